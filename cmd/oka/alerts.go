@@ -0,0 +1,101 @@
+package oka
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/oka/pkg/opsgenie"
+)
+
+// Output formats of the list-alerts command.
+const (
+	outputJSON  = "json"
+	outputTable = "table"
+)
+
+var (
+	listAlertsOutput = outputTable
+)
+
+// listAlertsCmd prints the alerts matching the configured query.
+var listAlertsCmd = &cobra.Command{
+	Use:   "list-alerts",
+	Short: "Print the alerts matching the configured OpsGenie query, without investigating them",
+	Args:  cobra.NoArgs,
+	RunE:  runListAlerts,
+}
+
+// listedAlert is an alert printed by the list-alerts command.
+type listedAlert struct {
+	ID           string `json:"id"`
+	Priority     string `json:"priority"`
+	Message      string `json:"message"`
+	Acknowledged bool   `json:"acknowledged"`
+}
+
+// init registers the list-alerts command and its flags.
+func init() {
+	listAlertsCmd.Flags().StringVar(&configFile, "config", configFile, "Path to configuration file")
+	listAlertsCmd.Flags().BoolVar(&strictConfig, "strict-config", strictConfig, "Fail on unknown configuration keys, they are logged and ignored otherwise")
+	listAlertsCmd.Flags().StringVarP(&listAlertsOutput, "output", "o", listAlertsOutput, "Output format, table or json")
+
+	Cmd.AddCommand(listAlertsCmd)
+}
+
+// runListAlerts runs the configured OpsGenie query once and prints the
+// matching alerts. Neither the LLM nor the MCP servers are initialized.
+func runListAlerts(c *cobra.Command, args []string) error {
+	if listAlertsOutput != outputTable && listAlertsOutput != outputJSON {
+		return fmt.Errorf("unknown output format %q, must be one of %s, %s", listAlertsOutput, outputTable, outputJSON)
+	}
+
+	conf, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	opsgenieService, err := opsgenie.NewService(conf)
+	if err != nil {
+		return fmt.Errorf("failed to create OpsGenie service: %w", err)
+	}
+
+	alerts, err := opsgenieService.ListAlerts(c.Context())
+	if err != nil {
+		return fmt.Errorf("failed to list alerts: %w", err)
+	}
+
+	return printAlerts(c.OutOrStdout(), alerts, listAlertsOutput)
+}
+
+// printAlerts prints the alerts to the writer in the output format, table or
+// json.
+func printAlerts(out io.Writer, alerts []alert.Alert, output string) error {
+	listed := make([]listedAlert, 0, len(alerts))
+	for _, a := range alerts {
+		listed = append(listed, listedAlert{
+			ID:           a.Id,
+			Priority:     string(a.Priority),
+			Message:      a.Message,
+			Acknowledged: a.Acknowledged,
+		})
+	}
+
+	if output == outputJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(listed)
+	}
+
+	w := tabwriter.NewWriter(out, 1, 1, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tPRIORITY\tACKNOWLEDGED\tMESSAGE")
+	for _, a := range listed {
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", a.ID, a.Priority, a.Acknowledged, a.Message)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,74 @@
+package oka
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+)
+
+func TestPrintAlerts(t *testing.T) {
+	alerts := []alert.Alert{
+		{Id: "alert-1", Priority: alert.P1, Message: "Disk full"},
+		{Id: "alert-2", Priority: alert.P3, Message: "High latency", Acknowledged: true},
+	}
+
+	t.Run("table", func(t *testing.T) {
+		var out bytes.Buffer
+		err := printAlerts(&out, alerts, outputTable)
+		if err != nil {
+			t.Fatalf("failed to print alerts: %v", err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		expected := [][]string{
+			{"ID", "PRIORITY", "ACKNOWLEDGED", "MESSAGE"},
+			{"alert-1", "P1", "false", "Disk", "full"},
+			{"alert-2", "P3", "true", "High", "latency"},
+		}
+		if len(lines) != len(expected) {
+			t.Fatalf("expected %d lines, got %q", len(expected), out.String())
+		}
+		for i, fields := range expected {
+			if got := strings.Fields(lines[i]); strings.Join(got, " ") != strings.Join(fields, " ") {
+				t.Errorf("expected line %d to be %q, got %q", i+1, fields, got)
+			}
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var out bytes.Buffer
+		err := printAlerts(&out, alerts, outputJSON)
+		if err != nil {
+			t.Fatalf("failed to print alerts: %v", err)
+		}
+
+		var listed []listedAlert
+		err = json.Unmarshal(out.Bytes(), &listed)
+		if err != nil {
+			t.Fatalf("failed to parse output: %v", err)
+		}
+
+		expected := []listedAlert{
+			{ID: "alert-1", Priority: "P1", Message: "Disk full"},
+			{ID: "alert-2", Priority: "P3", Message: "High latency", Acknowledged: true},
+		}
+		if len(listed) != len(expected) || listed[0] != expected[0] || listed[1] != expected[1] {
+			t.Errorf("expected alerts %+v, got %+v", expected, listed)
+		}
+	})
+
+	t.Run("no alerts", func(t *testing.T) {
+		var out bytes.Buffer
+		err := printAlerts(&out, nil, outputJSON)
+		if err != nil {
+			t.Fatalf("failed to print alerts: %v", err)
+		}
+
+		if strings.TrimSpace(out.String()) != "[]" {
+			t.Errorf("expected an empty list, got %q", out.String())
+		}
+	})
+}
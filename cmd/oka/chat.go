@@ -0,0 +1,139 @@
+package oka
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/oka/pkg/logger"
+	"github.com/giantswarm/oka/pkg/opsgenie"
+	"github.com/giantswarm/oka/pkg/session"
+)
+
+var (
+	chatAlertID string
+)
+
+// chatCmd investigates an alert interactively.
+var chatCmd = &cobra.Command{
+	Use:   "chat",
+	Short: "Investigate an alert interactively, approving each tool call and adding instructions between the LLM calls",
+	Args:  cobra.NoArgs,
+	RunE:  runChat,
+}
+
+// init registers the chat command and its flags.
+func init() {
+	chatCmd.Flags().StringVar(&chatAlertID, "alert-id", "", "ID of the OpsGenie alert to investigate")
+	chatCmd.Flags().StringVar(&configFile, "config", configFile, "Path to configuration file")
+	chatCmd.Flags().BoolVar(&strictConfig, "strict-config", strictConfig, "Fail on unknown configuration keys, they are logged and ignored otherwise")
+	_ = chatCmd.MarkFlagRequired("alert-id")
+
+	Cmd.AddCommand(chatCmd)
+}
+
+// runChat investigates the alert in a session driven by the operator through
+// stdin and stdout.
+func runChat(c *cobra.Command, args []string) error {
+	conf, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	logCloser, err := logger.Setup(conf.LogLevel, conf.LogFile, conf.LogFormat, logger.Rotation{
+		MaxAgeDays: conf.LogMaxAgeDays,
+		MaxBackups: conf.LogMaxBackups,
+		MaxSizeMB:  conf.LogMaxSizeMB,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set up logging: %w", err)
+	}
+	defer logCloser()
+
+	err = os.MkdirAll(conf.SessionsLogDir, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create sessions log directory: %w", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(c.Context(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+
+	alertClient, err := opsgenie.NewAlertClient(conf.OpsGenie.APIUrl, conf.OpsGenie.EnvVar, conf.OpsGenie.MaxRetries, conf.OpsGenie.RetryBackoff)
+	if err != nil {
+		return err
+	}
+
+	alert, err := alertClient.GetAlert(ctx, chatAlertID)
+	if err != nil {
+		return err
+	}
+
+	mcpClients, llmModels, err := setup(ctx, conf)
+	if err != nil {
+		return err
+	}
+	defer mcpClients.Close()
+
+	fmt.Fprintf(c.OutOrStdout(), "Investigating alert %s: %s\n", alert.Id, alert.Message)
+	repl := &chatREPL{in: bufio.NewReader(c.InOrStdin()), out: c.OutOrStdout()}
+
+	result, err := session.ProcessInteractive(ctx, alert, llmModels, mcpClients, alertClient, repl, conf)
+	fmt.Fprintf(c.OutOrStdout(), "\nSession %s: %s\n", result.Outcome, result.Reason)
+
+	return err
+}
+
+// chatREPL is the session interaction reading the operator's decisions from
+// its input.
+type chatREPL struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// Approve asks the operator whether the tool call is executed, only an
+// explicit yes approves it.
+func (r *chatREPL) Approve(ctx context.Context, call session.ToolCall) (bool, error) {
+	fmt.Fprintf(r.out, "\n> Tool call: %s %s\n", call.Tool, call.Arguments)
+	answer, err := r.readLine("Approve? [y/N] ")
+	if err != nil {
+		return false, err
+	}
+
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes", nil
+}
+
+// Instructions asks the operator for instructions to add before the next LLM
+// call.
+func (r *chatREPL) Instructions(ctx context.Context) (string, error) {
+	return r.readLine("\nInstructions for the LLM, empty to continue: ")
+}
+
+// Response shows a response of the LLM.
+func (r *chatREPL) Response(content string) {
+	fmt.Fprintf(r.out, "\n> LLM response:\n%s\n", content)
+}
+
+// ToolResponse shows the response of a tool call.
+func (r *chatREPL) ToolResponse(tool, content string) {
+	fmt.Fprintf(r.out, "\n> Tool response (%s):\n%s\n", tool, content)
+}
+
+// readLine prints the prompt and returns the next line of input, trimmed.
+func (r *chatREPL) readLine(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+
+	line, err := r.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,102 @@
+package oka
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/oka/pkg/session"
+)
+
+func TestChatREPLApprove(t *testing.T) {
+	testCases := []struct {
+		name        string
+		input       string
+		expected    bool
+		expectedErr error
+	}{
+		{
+			name:     "yes",
+			input:    "yes\n",
+			expected: true,
+		},
+		{
+			name:     "y uppercase with spaces",
+			input:    "  Y \n",
+			expected: true,
+		},
+		{
+			name:     "no",
+			input:    "n\n",
+			expected: false,
+		},
+		{
+			name:     "empty answer",
+			input:    "\n",
+			expected: false,
+		},
+		{
+			name:     "other answer",
+			input:    "sure\n",
+			expected: false,
+		},
+		{
+			name:     "last line without newline",
+			input:    "y",
+			expected: true,
+		},
+		{
+			name:        "end of input",
+			input:       "",
+			expectedErr: io.EOF,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			repl := &chatREPL{in: bufio.NewReader(strings.NewReader(tc.input)), out: &out}
+
+			approved, err := repl.Approve(context.Background(), session.ToolCall{Tool: "mcp_kubernetes_delete", Arguments: `{"name": "pod"}`})
+			if err != tc.expectedErr {
+				t.Fatalf("expected error %v, got %v", tc.expectedErr, err)
+			}
+			if approved != tc.expected {
+				t.Errorf("expected approved to be %t, got %t", tc.expected, approved)
+			}
+
+			// The operator is shown the tool call before being asked.
+			if !strings.Contains(out.String(), `Tool call: mcp_kubernetes_delete {"name": "pod"}`) {
+				t.Errorf("expected the tool call to be shown, got %q", out.String())
+			}
+		})
+	}
+}
+
+func TestChatREPLScript(t *testing.T) {
+	// The operator adds instructions, denies a tool call, approves the next
+	// one, then lets the LLM continue.
+	input := "Check the pods first\nn\ny\n\n"
+	repl := &chatREPL{in: bufio.NewReader(strings.NewReader(input)), out: io.Discard}
+	ctx := context.Background()
+
+	instructions, err := repl.Instructions(ctx)
+	if err != nil || instructions != "Check the pods first" {
+		t.Errorf("expected the instructions, got %q, %v", instructions, err)
+	}
+
+	for _, expected := range []bool{false, true} {
+		approved, err := repl.Approve(ctx, session.ToolCall{Tool: "tool"})
+		if err != nil || approved != expected {
+			t.Errorf("expected approved to be %t, got %t, %v", expected, approved, err)
+		}
+	}
+
+	instructions, err = repl.Instructions(ctx)
+	if err != nil || instructions != "" {
+		t.Errorf("expected no instructions, got %q, %v", instructions, err)
+	}
+}
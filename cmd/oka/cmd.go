@@ -2,21 +2,30 @@ package oka
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
-	"os/exec"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
 
 	"github.com/joho/godotenv"
 	"github.com/prometheus/common/version"
 	"github.com/spf13/cobra"
 
+	"github.com/giantswarm/oka/pkg/approval"
+	"github.com/giantswarm/oka/pkg/command"
 	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/events"
+	"github.com/giantswarm/oka/pkg/health"
 	"github.com/giantswarm/oka/pkg/llm"
 	"github.com/giantswarm/oka/pkg/logger"
 	"github.com/giantswarm/oka/pkg/mcp/client"
+	"github.com/giantswarm/oka/pkg/mcp/clock"
+	"github.com/giantswarm/oka/pkg/mcp/runbook"
+	"github.com/giantswarm/oka/pkg/metrics"
 	"github.com/giantswarm/oka/pkg/opsgenie"
 	"github.com/giantswarm/oka/pkg/service"
 	"github.com/giantswarm/oka/pkg/session"
@@ -44,8 +53,7 @@ func runner(c *cobra.Command, args []string) (err error) {
 		return nil
 	}
 
-	// Load the configuration file.
-	conf, err := config.LoadConfig(configFile)
+	conf, err := loadConfig()
 	if err != nil {
 		return err
 	}
@@ -55,18 +63,16 @@ func runner(c *cobra.Command, args []string) (err error) {
 	}
 
 	// Set up logging.
-	logCloser, err := logger.Setup(conf.LogLevel, conf.LogFile)
+	logCloser, err := logger.Setup(conf.LogLevel, conf.LogFile, conf.LogFormat, logger.Rotation{
+		MaxAgeDays: conf.LogMaxAgeDays,
+		MaxBackups: conf.LogMaxBackups,
+		MaxSizeMB:  conf.LogMaxSizeMB,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to set up logging: %w", err)
 	}
 	defer logCloser()
 
-	// Load environment variables from .env file if it exists.
-	err = godotenv.Load(".env")
-	if err == nil {
-		slog.Info("Loaded environment variables", "file", ".env")
-	}
-
 	// Create the sessions log directory if it does not exist.
 	err = os.MkdirAll(conf.SessionsLogDir, 0755)
 	if err != nil {
@@ -86,50 +92,308 @@ func runner(c *cobra.Command, args []string) (err error) {
 		cancel()
 	}()
 
-	// Initialize MCP servers.
-	mcpClients := client.New()
-	err = mcpClients.RegisterServersConfig(ctx, conf.GetMCPServers(true))
+	if len(alertIDs) > 0 {
+		return processSingleAlerts(ctx, conf, alertIDs)
+	}
+
+	return runContinuousMode(ctx, conf)
+}
+
+// loadConfig loads the configuration file, after the environment variables of
+// the .env file if it exists, which the configuration may reference.
+func loadConfig() (*config.Config, error) {
+	err := godotenv.Load(".env")
+	if err == nil {
+		slog.Info("Loaded environment variables", "file", ".env")
+	}
+
+	return config.LoadConfig(configFile, strictConfig)
+}
+
+// runContinuousMode fetches alerts from OpsGenie, by polling or webhook, and
+// starts a session for each of them until the context is canceled or one of
+// the services fails, whose error is returned.
+func runContinuousMode(ctx context.Context, conf *config.Config) error {
+	// A failing service stops the others.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	run := func(name string, f func() error) {
+		service.RunE(func() error {
+			err := f()
+			if err != nil {
+				slog.Error("Service failed, stopping", "service", name, "error", err)
+				cancel()
+				return fmt.Errorf("%s failed: %w", name, err)
+			}
+			return nil
+		})
+	}
+
+	// Start the health server first, so that OKA is reported as not ready
+	// while initializing.
+	if conf.Health.Address != "" {
+		run("health server", func() error {
+			return health.Serve(ctx, conf.Health.Address)
+		})
+	}
+
+	mcpClients, llmModels, err := setup(ctx, conf)
 	if err != nil {
 		return err
 	}
 	defer mcpClients.Close()
 
-	//runbookServer := runbook.NewServer(name, version.Version, conf)
-	//service.Run(func() { runbookServer.Start(ctx) })
-	//err = mcpClients.RegisterServer(ctx, runbookServer.MCPServer, "runbook")
-	//if err != nil {
-	//	return fmt.Errorf("failed to register runbook server: %w", err)
-	//}
+	// Initialize the OpsGenie service, polling alerts or receiving them by
+	// webhook.
+	var (
+		alertClient     *opsgenie.AlertClient
+		opsgenieService *opsgenie.Service
+		startAlerts     func(context.Context, chan<- any) error
+	)
+	switch conf.OpsGenie.Mode {
+	case opsgenie.ModePoll:
+		opsgenieService, err = opsgenie.NewService(conf)
+		if err != nil {
+			return fmt.Errorf("failed to create OpsGenie service: %w", err)
+		}
+		alertClient = opsgenieService.AlertClient()
+		startAlerts = opsgenieService.Start
+	case opsgenie.ModeWebhook:
+		webhookServer, err := opsgenie.NewWebhookServer(conf)
+		if err != nil {
+			return fmt.Errorf("failed to create OpsGenie webhook server: %w", err)
+		}
+		alertClient = webhookServer.AlertClient()
+		startAlerts = webhookServer.Start
+	default:
+		return fmt.Errorf("unknown OpsGenie mode %q", conf.OpsGenie.Mode)
+	}
 
-	// Initialize the LLM model.
-	llmModel, err := llm.New(conf)
+	// Start the events server streaming the sessions' events, if enabled.
+	var broker *events.Broker
+	if conf.Events.ListenAddress != "" {
+		broker = events.NewBroker()
+		run("events server", func() error {
+			return events.Serve(ctx, conf.Events.ListenAddress, broker)
+		})
+	}
+
+	// Start the metrics server, if enabled.
+	if conf.Metrics.Address != "" {
+		run("metrics server", func() error {
+			return metrics.Serve(ctx, conf.Metrics.Address)
+		})
+	}
+
+	// Reconnect the shared MCP servers when they fail, if enabled.
+	if conf.MCP.HealthCheckInterval > 0 {
+		run("MCP monitor", func() error {
+			return mcpClients.Monitor(ctx, conf.MCP.HealthCheckInterval)
+		})
+	}
+
+	// Prune the old session logs.
+	service.Run(func() { session.PruneLogs(ctx, conf) })
+
+	// Reload the configuration on SIGHUP.
+	reloads := make(chan *config.Config, 1)
+	service.Run(func() { watchReloads(ctx, conf, opsgenieService, reloads) })
+
+	// Start the approval server, if tool calls require approval.
+	var approver session.Approver
+	if len(conf.Approval.RequireApproval) > 0 {
+		gate := approval.NewGate(conf)
+		approver = gate
+		run("approval server", func() error {
+			return gate.Serve(ctx, conf.Approval.ListenAddress)
+		})
+	}
+
+	// Start the OpsGenie service and session services.
+	alertsChan := make(chan any, 1)
+	run("OpsGenie service", func() error { return startAlerts(ctx, alertsChan) })
+	run("session service", func() error {
+		return session.Listen(ctx, alertsChan, reloads, llmModels, mcpClients, alertClient, broker, approver, conf)
+	})
+
+	// Once stopping, wait for the sessions to complete up to the shutdown
+	// timeout.
+	err = service.WaitTimeout(ctx, conf.ShutdownTimeout)
+	if errors.Is(err, service.ErrTimeout) {
+		slog.Warn("Shutdown timeout reached, abandoning running sessions", "timeout", conf.ShutdownTimeout, "sessions", session.Running())
+	}
+
+	return err
+}
+
+// watchReloads reloads the configuration file on SIGHUP until the context is
+// canceled. The log level, max calls and the OpsGenie polling query and
+// interval are applied, other changes require a restart. The OpsGenie service
+// is nil in webhook mode.
+func watchReloads(ctx context.Context, conf *config.Config, opsgenieService *opsgenie.Service, reloads chan<- *config.Config) {
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	defer signal.Stop(hupChan)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hupChan:
+		}
+
+		slog.Info("Received SIGHUP signal, reloading config", "file", configFile)
+		newConf, err := config.LoadConfig(configFile, strictConfig)
+		if err != nil {
+			slog.Error("Failed to reload config, keeping the current one", "error", err)
+			continue
+		}
+
+		err = logger.SetLevel(newConf.LogLevel)
+		if err != nil {
+			slog.Error("Failed to reload log level", "error", err)
+		}
+
+		if opsgenieService != nil {
+			err = opsgenieService.Reload(newConf)
+			if err != nil {
+				slog.Error("Failed to reload OpsGenie service", "error", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case reloads <- newConf:
+		}
+
+		ignored := config.IgnoredChanges(conf, newConf)
+		if len(ignored) > 0 {
+			slog.Warn("Config changes require a restart to be applied", "settings", ignored)
+		}
+	}
+}
+
+// processSingleAlerts investigates the alerts with the given IDs one after the
+// other and returns once all of them have been processed.
+func processSingleAlerts(ctx context.Context, conf *config.Config, ids []string) error {
+	mcpClients, llmModels, err := setup(ctx, conf)
 	if err != nil {
 		return err
 	}
-	slog.Info("LLM model initialized", "provider", conf.LLM.Provider)
+	defer mcpClients.Close()
 
-	// Run initialization commands.
-	for _, initCommand := range conf.InitCommands {
-		c := exec.Command(initCommand.Command, initCommand.Args...)
-		slog.Info("Running init command", "command", c.String())
-		_, err = c.Output()
+	alertClient, err := opsgenie.NewAlertClient(conf.OpsGenie.APIUrl, conf.OpsGenie.EnvVar, conf.OpsGenie.MaxRetries, conf.OpsGenie.RetryBackoff)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, id := range ids {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			// Continue if context is not done
+		}
+
+		err = processSingleAlert(ctx, conf, id, llmModels, mcpClients, alertClient)
 		if err != nil {
-			return fmt.Errorf("failed to run init command %s: %w", c.String(), err)
+			slog.Error("Failed to process alert", "alert.id", id, "error", err)
+			failed++
 		}
 	}
 
-	// Initialize the OpsGenie service.
-	opsgenieService, err := opsgenie.NewService(conf)
+	if failed > 0 {
+		return fmt.Errorf("failed to process %d out of %d alerts", failed, len(ids))
+	}
+
+	return nil
+}
+
+// processSingleAlert fetches the alert with the given ID and investigates it.
+func processSingleAlert(ctx context.Context, conf *config.Config, id string, llmModels []llm.Model, mcpClients *client.Clients, alertClient *opsgenie.AlertClient) error {
+	alert, err := alertClient.GetAlert(ctx, id)
 	if err != nil {
-		return fmt.Errorf("failed to create OpsGenie service: %w", err)
+		return err
 	}
 
-	// Start the OpsGenie service and session services.
-	alertsChan := make(chan any, 1)
-	service.Run(func() { opsgenieService.Start(ctx, alertsChan) })
-	service.Run(func() { session.Listen(ctx, alertsChan, llmModel, mcpClients, conf) })
+	// Guard against investigating an alert belonging to another team.
+	var teams []string
+	for _, team := range conf.OpsGenie.GetTeams() {
+		if team.Name != "" {
+			teams = append(teams, team.Name)
+		}
+	}
+	matchesTeam := func(team string) bool { return opsgenie.AlertMatchesTeam(alert, team) }
+	if len(teams) > 0 && !slices.ContainsFunc(teams, matchesTeam) {
+		if conf.OpsGenie.EnforceTeam {
+			return fmt.Errorf("alert %s does not belong to team %s", id, strings.Join(teams, ", "))
+		}
+		slog.Warn("Alert does not belong to the configured team", "alert.id", id, "team", teams)
+	}
 
-	service.Wait()
+	result, err := session.ProcessSingleAlert(ctx, alert, llmModels, mcpClients, alertClient, conf)
+	slog.Info("Processed alert", "alert.id", id, "outcome", result.Outcome, "reason", result.Reason)
 
-	return nil
+	return err
+}
+
+// setup checks the system prompt, initializes the shared MCP clients and the
+// LLM model, and runs the initialization commands. The caller is responsible
+// for closing the returned MCP clients.
+func setup(ctx context.Context, conf *config.Config) (*client.Clients, []llm.Model, error) {
+	// Fail before starting any session if the system prompt is invalid.
+	err := session.CheckSystemPrompt(conf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Initialize MCP servers.
+	mcpClients := client.New()
+	err = mcpClients.RegisterServersConfig(ctx, conf.GetMCPServers(true), conf.MCP.FailFast)
+	if err != nil {
+		mcpClients.Close()
+		return nil, nil, err
+	}
+
+	// Register the runbook server in-process, it is closed along with the
+	// other MCP clients once the context is canceled.
+	runbookServer := runbook.NewServer(name, version.Version, conf)
+	err = mcpClients.RegisterServer(ctx, runbookServer.MCPServer, "runbook")
+	if err != nil {
+		mcpClients.Close()
+		return nil, nil, fmt.Errorf("failed to register runbook server: %w", err)
+	}
+
+	// Register the clock server in-process, giving the current time to the
+	// LLM.
+	clockServer := clock.NewServer(name, version.Version)
+	err = mcpClients.RegisterServer(ctx, clockServer.MCPServer, "clock")
+	if err != nil {
+		mcpClients.Close()
+		return nil, nil, fmt.Errorf("failed to register clock server: %w", err)
+	}
+	health.SetReady(health.CheckMCP, true)
+
+	// Initialize the LLM model.
+	llmModels, err := llm.New(conf)
+	if err != nil {
+		mcpClients.Close()
+		return nil, nil, err
+	}
+	slog.Info("LLM model initialized", "provider", conf.LLM.Provider)
+	health.SetReady(health.CheckLLM, true)
+
+	// Run initialization commands.
+	for _, initCommand := range conf.InitCommands {
+		slog.Info("Running init command", "command", initCommand.Command, "args", initCommand.Args)
+		err = command.Run(ctx, initCommand, conf.InitCommandTimeout)
+		if err != nil {
+			mcpClients.Close()
+			return nil, nil, fmt.Errorf("failed to run init command: %w", err)
+		}
+	}
+
+	return mcpClients, llmModels, nil
 }
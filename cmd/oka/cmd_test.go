@@ -0,0 +1,44 @@
+package oka
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/mcp/runbook"
+)
+
+func TestSetupRegistersRunbookServer(t *testing.T) {
+	conf := &config.Config{
+		LLM:        config.LLM{Provider: "openai", Model: "gpt", Token: "token"},
+		MCPServers: config.MCPServers{},
+		OpsGenie:   &config.OpsGenie{},
+		RunbookDir: t.TempDir(),
+	}
+
+	mcpClients, llmModels, err := setup(context.Background(), conf)
+	if err != nil {
+		t.Fatalf("failed to set up: %v", err)
+	}
+	t.Cleanup(func() { _ = mcpClients.Close() })
+
+	if len(llmModels) != 1 {
+		t.Errorf("expected 1 LLM model, got %d", len(llmModels))
+	}
+
+	var tools []string
+	for _, tool := range mcpClients.GetTools() {
+		tools = append(tools, tool.Function.Name)
+	}
+
+	for _, tool := range []string{runbook.GetRunbookToolName, runbook.ListRunbooksToolName, runbook.FindRunbookForAlertToolName} {
+		name := "mcp_runbook_" + tool
+		if !slices.Contains(tools, name) {
+			t.Errorf("expected tool %s to be registered, got %q", name, tools)
+		}
+		if original := mcpClients.GetOriginalToolName(name); original != tool {
+			t.Errorf("expected tool %s to call %s, got %s", name, tool, original)
+		}
+	}
+}
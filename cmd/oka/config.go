@@ -0,0 +1,98 @@
+package oka
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+var (
+	outputFile string
+)
+
+// configCmd groups the commands managing the configuration file.
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the OKA configuration file",
+}
+
+// configInitCmd writes an example configuration file with the default values.
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write a commented configuration file with the default values",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigInit,
+}
+
+// configValidateCmd checks a configuration file without starting OKA.
+var configValidateCmd = &cobra.Command{
+	Use:          "validate",
+	Short:        "Check a configuration file, exiting with a non-zero status if it is invalid",
+	Args:         cobra.NoArgs,
+	RunE:         runConfigValidate,
+	SilenceUsage: true,
+}
+
+// init registers the config commands and their flags.
+func init() {
+	configInitCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Path of the file to write, stdout if empty, an existing file is not overwritten")
+
+	configValidateCmd.Flags().StringVar(&configFile, "config", configFile, "Path to the configuration file to check")
+	configValidateCmd.Flags().BoolVar(&strictConfig, "strict-config", strictConfig, "Fail on unknown configuration keys, they are logged and ignored otherwise")
+
+	configCmd.AddCommand(configInitCmd, configValidateCmd)
+	Cmd.AddCommand(configCmd)
+}
+
+// runConfigInit writes the example configuration to the output file or
+// stdout.
+func runConfigInit(c *cobra.Command, args []string) error {
+	example, err := config.Example()
+	if err != nil {
+		return fmt.Errorf("failed to generate config: %w", err)
+	}
+
+	if outputFile == "" {
+		_, err = c.OutOrStdout().Write(example)
+		return err
+	}
+
+	f, err := os.OpenFile(outputFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create config file: %w", err)
+	}
+	defer f.Close()
+
+	_, err = f.Write(example)
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return f.Close()
+}
+
+// runConfigValidate loads and validates the configuration file, printing "ok"
+// or its problems. No service is started and OpsGenie is not contacted.
+func runConfigValidate(c *cobra.Command, args []string) error {
+	_, err := loadConfig()
+	if err == nil {
+		fmt.Fprintln(c.OutOrStdout(), "ok")
+		return nil
+	}
+
+	// The validation errors are joined, list them one per line.
+	problems := []error{err}
+	var joined interface{ Unwrap() []error }
+	if errors.As(err, &joined) {
+		problems = joined.Unwrap()
+	}
+	for _, problem := range problems {
+		fmt.Fprintf(c.OutOrStdout(), "- %s\n", problem)
+	}
+
+	return fmt.Errorf("invalid config file %s", configFile)
+}
@@ -0,0 +1,68 @@
+package oka
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestRunConfigValidate(t *testing.T) {
+	testCases := []struct {
+		name           string
+		config         string
+		expectedOutput []string
+		expectedErr    bool
+	}{
+		{
+			name:           "valid config",
+			config:         "max_calls: 10\n",
+			expectedOutput: []string{"ok"},
+		},
+		{
+			name:   "invalid config",
+			config: "log_format: xml\nsession:\n  llm_call_timeout: 0s\n",
+			expectedOutput: []string{
+				`- invalid log_format "xml"`,
+				"- invalid session.llm_call_timeout",
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "oka.yaml")
+			err := os.WriteFile(path, []byte(tc.config), 0600)
+			if err != nil {
+				t.Fatalf("failed to write config file: %v", err)
+			}
+
+			previous := configFile
+			configFile = path
+			t.Cleanup(func() { configFile = previous })
+
+			var out bytes.Buffer
+			c := &cobra.Command{}
+			c.SetOut(&out)
+
+			err = runConfigValidate(c, nil)
+			if tc.expectedErr != (err != nil) {
+				t.Errorf("expected error to be %t, got %v", tc.expectedErr, err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			if len(lines) != len(tc.expectedOutput) {
+				t.Fatalf("expected %d lines, got %q", len(tc.expectedOutput), out.String())
+			}
+			for i, expected := range tc.expectedOutput {
+				if !strings.HasPrefix(lines[i], expected) {
+					t.Errorf("expected line %d to start with %q, got %q", i+1, expected, lines[i])
+				}
+			}
+		})
+	}
+}
@@ -3,14 +3,18 @@ package oka
 import "github.com/giantswarm/oka/pkg/config"
 
 var (
-	configFile  = "oka.yaml"
-	versionFlag = false
+	alertIDs     []string
+	configFile   = "oka.yaml"
+	strictConfig = true
+	versionFlag  = false
 )
 
 // init initializes command line flags for the application.
 func init() {
 	Cmd.Flags().StringVar(&configFile, "config", configFile, "Path to configuration file, flag values take precedence over config file values")
+	Cmd.Flags().BoolVar(&strictConfig, "strict-config", strictConfig, "Fail on unknown configuration keys, they are logged and ignored otherwise")
 	Cmd.Flags().BoolVar(&versionFlag, "version", false, "Print version information and exit")
+	Cmd.Flags().StringSliceVar(&alertIDs, "alert-id", nil, "ID of an OpsGenie alert to investigate before exiting, can be repeated or comma-separated")
 
 	config.BindFlags(Cmd)
 }
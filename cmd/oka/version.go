@@ -0,0 +1,65 @@
+package oka
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/prometheus/common/version"
+	"github.com/spf13/cobra"
+)
+
+// outputText is the output format printing the version as text, like the
+// --version flag.
+const outputText = "text"
+
+var (
+	versionOutput = outputText
+)
+
+// versionCmd prints the version of OKA.
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version of OKA",
+	Args:  cobra.NoArgs,
+	RunE:  runVersion,
+}
+
+// versionInfo is the version printed by the version command in JSON.
+type versionInfo struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision"`
+	Branch    string `json:"branch"`
+	BuildUser string `json:"build_user"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// init registers the version command and its flags.
+func init() {
+	versionCmd.Flags().StringVarP(&versionOutput, "output", "o", versionOutput, "Output format, text or json")
+
+	Cmd.AddCommand(versionCmd)
+}
+
+// runVersion prints the version of OKA, as printed by the --version flag or
+// in JSON.
+func runVersion(c *cobra.Command, args []string) error {
+	switch versionOutput {
+	case outputText:
+		fmt.Fprintln(c.OutOrStdout(), version.Print(name))
+		return nil
+	case outputJSON:
+		encoder := json.NewEncoder(c.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(versionInfo{
+			Version:   version.Version,
+			Revision:  version.GetRevision(),
+			Branch:    version.Branch,
+			BuildUser: version.BuildUser,
+			BuildDate: version.BuildDate,
+			GoVersion: version.GoVersion,
+		})
+	}
+
+	return fmt.Errorf("unknown output format %q, must be one of %s, %s", versionOutput, outputText, outputJSON)
+}
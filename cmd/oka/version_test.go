@@ -0,0 +1,82 @@
+package oka
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/version"
+	"github.com/spf13/cobra"
+)
+
+func TestRunVersionJSON(t *testing.T) {
+	previous := version.Version
+	version.Version = "1.2.3"
+	t.Cleanup(func() { version.Version = previous })
+	setVersionOutput(t, outputJSON)
+
+	var out bytes.Buffer
+	c := &cobra.Command{}
+	c.SetOut(&out)
+
+	err := runVersion(c, nil)
+	if err != nil {
+		t.Fatalf("failed to print version: %v", err)
+	}
+
+	var info map[string]string
+	err = json.Unmarshal(out.Bytes(), &info)
+	if err != nil {
+		t.Fatalf("failed to decode version %q: %v", out.String(), err)
+	}
+
+	expectedKeys := []string{"branch", "build_date", "build_user", "go_version", "revision", "version"}
+	if keys := slices.Sorted(maps.Keys(info)); !slices.Equal(keys, expectedKeys) {
+		t.Errorf("expected keys %q, got %q", expectedKeys, keys)
+	}
+	if info["version"] != "1.2.3" {
+		t.Errorf("expected version 1.2.3, got %q", info["version"])
+	}
+	if info["go_version"] != version.GoVersion {
+		t.Errorf("expected go version %q, got %q", version.GoVersion, info["go_version"])
+	}
+}
+
+func TestRunVersionText(t *testing.T) {
+	setVersionOutput(t, outputText)
+
+	var out bytes.Buffer
+	c := &cobra.Command{}
+	c.SetOut(&out)
+
+	err := runVersion(c, nil)
+	if err != nil {
+		t.Fatalf("failed to print version: %v", err)
+	}
+
+	if expected := version.Print(name) + "\n"; out.String() != expected {
+		t.Errorf("expected the output of the --version flag %q, got %q", expected, out.String())
+	}
+}
+
+func TestRunVersionUnknownOutput(t *testing.T) {
+	setVersionOutput(t, "yaml")
+
+	err := runVersion(&cobra.Command{}, nil)
+	if err == nil || !strings.Contains(err.Error(), `unknown output format "yaml"`) {
+		t.Errorf("expected an unknown output format error, got %v", err)
+	}
+}
+
+// setVersionOutput sets the output format of the version command for the
+// test.
+func setVersionOutput(t *testing.T, output string) {
+	t.Helper()
+
+	previous := versionOutput
+	versionOutput = output
+	t.Cleanup(func() { versionOutput = previous })
+}
@@ -0,0 +1,131 @@
+// Package approval provides the gate requiring an out-of-band approval of the
+// tool calls of the sessions: approval requests are posted to Slack and
+// approved or denied through the gate's HTTP server.
+package approval
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/logger"
+	"github.com/giantswarm/oka/pkg/session"
+	"github.com/giantswarm/oka/pkg/slack"
+)
+
+// notifier posts the approval requests.
+type notifier interface {
+	PostApprovalRequest(ctx context.Context, request slack.ApprovalRequest) error
+}
+
+// Gate requires the approval of the calls of the configured tools, the other
+// tool calls are approved immediately. It implements session.Approver.
+type Gate struct {
+	baseURL  string
+	notifier notifier
+	timeout  time.Duration
+	tools    []string
+
+	mu sync.Mutex
+	// pending are the tool calls waiting for a decision, keyed by request ID.
+	pending map[string]*request
+}
+
+// request is a tool call waiting for a decision.
+type request struct {
+	call     session.ToolCall
+	decision chan bool
+}
+
+// NewGate creates a new gate posting the approval requests to the Slack
+// webhook.
+func NewGate(conf *config.Config) *Gate {
+	return &Gate{
+		baseURL:  strings.TrimSuffix(conf.Approval.URL, "/"),
+		notifier: slack.NewNotifier(conf.Slack.WebhookURL),
+		pending:  make(map[string]*request),
+		timeout:  conf.Approval.Timeout,
+		tools:    conf.Approval.RequireApproval,
+	}
+}
+
+// Approve returns whether the tool call is executed. Calls of the tools
+// requiring approval block until they are approved or denied, and are denied
+// once the timeout elapses.
+func (g *Gate) Approve(ctx context.Context, call session.ToolCall) (bool, error) {
+	if !slices.Contains(g.tools, call.Tool) {
+		return true, nil
+	}
+
+	id := uuid.New().String()
+	r := &request{call: call, decision: make(chan bool, 1)}
+	g.mu.Lock()
+	g.pending[id] = r
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		delete(g.pending, id)
+		g.mu.Unlock()
+	}()
+
+	err := g.notifier.PostApprovalRequest(ctx, slack.ApprovalRequest{
+		Arguments: call.Arguments,
+		Link:      fmt.Sprintf("%s/approvals/%s", g.baseURL, id),
+		SessionID: call.SessionID,
+		Timeout:   g.timeout,
+		Tool:      call.Tool,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to request approval: %w", err)
+	}
+	logger.FromContext(ctx).Info("Waiting for tool call approval", "tool", call.Tool, "request", id)
+
+	timer := time.NewTimer(g.timeout)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	case <-timer.C:
+		logger.FromContext(ctx).Warn("Tool call approval timed out", "tool", call.Tool, "request", id)
+		return false, nil
+	case approved := <-r.decision:
+		logger.FromContext(ctx).Info("Tool call approval decided", "tool", call.Tool, "request", id, "approved", approved)
+		return approved, nil
+	}
+}
+
+// decide records the decision on a pending request. It returns false if the
+// request is unknown, e.g. already decided or timed out.
+func (g *Gate) decide(id string, approved bool) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	r, ok := g.pending[id]
+	if !ok {
+		return false
+	}
+	delete(g.pending, id)
+	r.decision <- approved
+
+	return true
+}
+
+// lookup returns the tool call of a pending request.
+func (g *Gate) lookup(id string) (session.ToolCall, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	r, ok := g.pending[id]
+	if !ok {
+		return session.ToolCall{}, false
+	}
+
+	return r.call, true
+}
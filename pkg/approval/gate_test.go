@@ -0,0 +1,177 @@
+package approval
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/oka/pkg/session"
+	"github.com/giantswarm/oka/pkg/slack"
+)
+
+// fakeNotifier sends the approval requests to a channel instead of posting
+// them.
+type fakeNotifier struct {
+	requests chan slack.ApprovalRequest
+}
+
+// PostApprovalRequest sends the request to the channel.
+func (n *fakeNotifier) PostApprovalRequest(ctx context.Context, request slack.ApprovalRequest) error {
+	n.requests <- request
+	return nil
+}
+
+// newTestGate returns a gate requiring the approval of the delete tool, along
+// with its notifier and the URL of its server.
+func newTestGate(t *testing.T, timeout time.Duration) (*Gate, *fakeNotifier, string) {
+	t.Helper()
+
+	notifier := &fakeNotifier{requests: make(chan slack.ApprovalRequest, 1)}
+	g := &Gate{
+		notifier: notifier,
+		pending:  make(map[string]*request),
+		timeout:  timeout,
+		tools:    []string{"delete"},
+	}
+
+	server := httptest.NewServer(g.Handler())
+	t.Cleanup(server.Close)
+	g.baseURL = server.URL
+
+	return g, notifier, server.URL
+}
+
+// approveAsync asks the gate to approve the tool call in the background, the
+// result is sent to the returned channel.
+func approveAsync(g *Gate, tool string) <-chan bool {
+	result := make(chan bool, 1)
+	go func() {
+		approved, err := g.Approve(context.Background(), session.ToolCall{SessionID: "session", Tool: tool, Arguments: "{}"})
+		if err != nil {
+			approved = false
+		}
+		result <- approved
+	}()
+
+	return result
+}
+
+func TestGateDecision(t *testing.T) {
+	testCases := []struct {
+		name     string
+		action   string
+		expected bool
+	}{
+		{
+			name:     "approve",
+			action:   "approve",
+			expected: true,
+		},
+		{
+			name:     "deny",
+			action:   "deny",
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g, notifier, _ := newTestGate(t, time.Minute)
+			result := approveAsync(g, "delete")
+
+			request := <-notifier.requests
+			if request.Tool != "delete" || request.SessionID != "session" {
+				t.Errorf("expected the approval request of the delete tool, got %+v", request)
+			}
+
+			// The page of the request shows the tool call.
+			resp, err := http.Get(request.Link)
+			if err != nil {
+				t.Fatalf("failed to get approval page: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("expected status %d for the approval page, got %d", http.StatusOK, resp.StatusCode)
+			}
+
+			resp, err = http.Post(request.Link+"/"+tc.action, "", nil)
+			if err != nil {
+				t.Fatalf("failed to post decision: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+			}
+
+			select {
+			case approved := <-result:
+				if approved != tc.expected {
+					t.Errorf("expected approved to be %t, got %t", tc.expected, approved)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("expected the decision to unblock the tool call")
+			}
+
+			// The request cannot be decided again.
+			resp, err = http.Post(request.Link+"/approve", "", nil)
+			if err != nil {
+				t.Fatalf("failed to post decision: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusNotFound {
+				t.Errorf("expected status %d for a decided request, got %d", http.StatusNotFound, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestGateTimeout(t *testing.T) {
+	g, notifier, _ := newTestGate(t, 50*time.Millisecond)
+	result := approveAsync(g, "delete")
+
+	request := <-notifier.requests
+
+	select {
+	case approved := <-result:
+		if approved {
+			t.Error("expected the tool call to be denied once the timeout elapsed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the timeout to unblock the tool call")
+	}
+
+	resp, err := http.Post(request.Link+"/approve", "", nil)
+	if err != nil {
+		t.Fatalf("failed to post decision: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status %d for an expired request, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestGateToolWithoutApproval(t *testing.T) {
+	g, notifier, url := newTestGate(t, time.Minute)
+
+	approved, err := g.Approve(context.Background(), session.ToolCall{Tool: "list"})
+	if err != nil || !approved {
+		t.Errorf("expected the tool call to be approved immediately, got %t, %v", approved, err)
+	}
+
+	select {
+	case request := <-notifier.requests:
+		t.Errorf("expected no approval request, got %+v", request)
+	default:
+	}
+
+	resp, err := http.Get(url + "/approvals/unknown")
+	if err != nil {
+		t.Fatalf("failed to get approval page: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status %d for an unknown request, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
@@ -0,0 +1,108 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// shutdownTimeout is the time given to the server to shut down gracefully.
+const shutdownTimeout = 5 * time.Second
+
+// pageTemplate is the page approving or denying a tool call. The decision is
+// posted so that link previews do not decide it.
+var pageTemplate = template.Must(template.New("approval").Parse(`<!DOCTYPE html>
+<html>
+<head><title>OKA tool call approval</title></head>
+<body>
+<h1>Call {{ .Tool }}?</h1>
+<p>Session {{ .SessionID }}</p>
+<pre>{{ .Arguments }}</pre>
+<form method="post" action="{{ .ID }}/approve"><button type="submit">Approve</button></form>
+<form method="post" action="{{ .ID }}/deny"><button type="submit">Deny</button></form>
+</body>
+</html>
+`))
+
+// Handler returns the HTTP handler serving:
+//   - GET /approvals/{id}: the page approving or denying the tool call.
+//   - POST /approvals/{id}/approve: approves the tool call.
+//   - POST /approvals/{id}/deny: denies the tool call.
+func (g *Gate) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /approvals/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		call, ok := g.lookup(id)
+		if !ok {
+			http.Error(w, "unknown or expired approval request", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err := pageTemplate.Execute(w, map[string]string{
+			"Arguments": call.Arguments,
+			"ID":        id,
+			"SessionID": call.SessionID,
+			"Tool":      call.Tool,
+		})
+		if err != nil {
+			slog.Warn("Failed to write approval page", "error", err)
+		}
+	})
+
+	decide := func(approved bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if !g.decide(r.PathValue("id"), approved) {
+				http.Error(w, "unknown or expired approval request", http.StatusNotFound)
+				return
+			}
+
+			if approved {
+				fmt.Fprintln(w, "The tool call is approved.")
+			} else {
+				fmt.Fprintln(w, "The tool call is denied.")
+			}
+		}
+	}
+	mux.HandleFunc("POST /approvals/{id}/approve", decide(true))
+	mux.HandleFunc("POST /approvals/{id}/deny", decide(false))
+
+	return mux
+}
+
+// Serve serves the approval endpoints on the given address until the context
+// is canceled.
+func (g *Gate) Serve(ctx context.Context, address string) error {
+	server := &http.Server{
+		Addr:              address,
+		Handler:           g.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		err := server.Shutdown(shutdownCtx)
+		if err != nil {
+			slog.Warn("Failed to shut down approval server", "error", err)
+		}
+	}()
+
+	slog.Info("Approval server started", "address", address)
+	defer slog.Info("Approval server stopped")
+
+	err := server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve approvals: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+// Package command runs the commands configured to prepare the environment of
+// OKA, e.g. logging in to the Kubernetes clusters.
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/logger"
+)
+
+// waitDelay is the time given to the command to release its output once it
+// is killed, e.g. when subprocesses it started keep them open.
+const waitDelay = 5 * time.Second
+
+// Run runs the command with its environment variables added to the
+// environment of OKA, giving up after the timeout unless it is zero. The
+// output of the command is logged, its stderr is included in the returned
+// error if it fails.
+func Run(ctx context.Context, command config.Command, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, command.Command, command.Args...)
+	if len(command.Env) > 0 {
+		cmd.Env = append(os.Environ(), command.Env...)
+	}
+	cmd.WaitDelay = waitDelay
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	logger.FromContext(ctx).Debug("Command output", "command", cmd.String(), "duration", time.Since(start), "stdout", strings.TrimSpace(stdout.String()), "stderr", strings.TrimSpace(stderr.String()))
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+
+	if output := strings.TrimSpace(stderr.String()); output != "" {
+		return fmt.Errorf("command %s failed: %w, stderr: %s", cmd.String(), err, output)
+	}
+
+	return fmt.Errorf("command %s failed: %w", cmd.String(), err)
+}
@@ -0,0 +1,102 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/logger"
+)
+
+func TestRunEnv(t *testing.T) {
+	t.Setenv("OKA_TEST_INHERITED", "inherited")
+
+	testCases := []struct {
+		name        string
+		command     config.Command
+		expectedErr string
+	}{
+		{
+			name: "configured variable",
+			command: config.Command{
+				Command: "sh",
+				Args:    []string{"-c", `test "$OKA_TEST_VAR" = configured`},
+				Env:     []string{"OKA_TEST_VAR=configured"},
+			},
+		},
+		{
+			name: "inherited variable",
+			command: config.Command{
+				Command: "sh",
+				Args:    []string{"-c", `test "$OKA_TEST_INHERITED" = inherited && test "$OKA_TEST_VAR" = configured`},
+				Env:     []string{"OKA_TEST_VAR=configured"},
+			},
+		},
+		{
+			name: "missing variable",
+			command: config.Command{
+				Command: "sh",
+				Args:    []string{"-c", `test "$OKA_TEST_VAR" = configured || { echo missing >&2; exit 1; }`},
+			},
+			expectedErr: "stderr: missing",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Run(context.Background(), tc.command, time.Minute)
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Errorf("expected the command to see its environment, got %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("expected error containing %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	start := time.Now()
+	err := Run(context.Background(), config.Command{Command: "sleep", Args: []string{"10"}}, 100*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Errorf("expected a timeout error, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the command to be killed on the timeout, took %s", elapsed)
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	command := config.Command{
+		Command: "sh",
+		Args:    []string{"-c", "echo logging in; echo 'login failed: expired token' >&2; exit 3"},
+	}
+
+	var logs bytes.Buffer
+	ctx := logger.NewContext(context.Background(), slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	err := Run(ctx, command, time.Minute)
+	if err == nil {
+		t.Fatal("expected the command to fail")
+	}
+	for _, expected := range []string{"exit status 3", "stderr: login failed: expired token"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error containing %q, got %v", expected, err)
+		}
+	}
+
+	for _, expected := range []string{`stdout="logging in"`, `stderr="login failed: expired token"`} {
+		if !strings.Contains(logs.String(), expected) {
+			t.Errorf("expected the output of the command to be logged with %s, got %q", expected, logs.String())
+		}
+	}
+}
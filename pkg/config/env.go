@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// expandEnv expands the ${VAR} and $VAR references to environment variables
+// in the configuration values holding secrets or endpoints, which are often
+// injected through the environment. Referencing an unset variable is an error.
+func (c *Config) expandEnv() error {
+	var err error
+	expand := func(field string, value *string) {
+		if err != nil {
+			return
+		}
+		*value, err = expandEnvValue(*value)
+		if err != nil {
+			err = fmt.Errorf("failed to expand %s: %w", field, err)
+		}
+	}
+
+	expand("llm.base_url", &c.LLM.BaseURL)
+	expand("llm.token", &c.LLM.Token)
+	for i := range c.LLM.Fallbacks {
+		expand(fmt.Sprintf("llm.fallbacks[%d].base_url", i), &c.LLM.Fallbacks[i].BaseURL)
+		expand(fmt.Sprintf("llm.fallbacks[%d].token", i), &c.LLM.Fallbacks[i].Token)
+	}
+	expand("opsgenie.api_url", &c.OpsGenie.APIUrl)
+	expand("slack.webhook_url", &c.Slack.WebhookURL)
+
+	for name, server := range c.MCPServers {
+		expand(fmt.Sprintf("mcp_servers.%s.url", name), &server.URL)
+		for i := range server.Env {
+			expand(fmt.Sprintf("mcp_servers.%s.env[%d]", name, i), &server.Env[i])
+		}
+		c.MCPServers[name] = server
+	}
+
+	return err
+}
+
+// expandEnvValue expands the environment variables referenced in the value,
+// returning an error listing the unset ones.
+func expandEnvValue(value string) (string, error) {
+	var unset []string
+	expanded := os.Expand(value, func(name string) string {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			unset = append(unset, name)
+		}
+		return v
+	})
+
+	if len(unset) > 0 {
+		return "", fmt.Errorf("environment variables not set: %s", strings.Join(unset, ", "))
+	}
+
+	return expanded, nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigExpandEnv(t *testing.T) {
+	t.Setenv("OKA_TEST_TOKEN", "secret")
+	t.Setenv("OKA_TEST_HOST", "mcp.example.com")
+	t.Setenv("OKA_TEST_EMPTY", "")
+
+	conf, err := LoadConfig(writeConfig(t, `
+llm:
+  token: ${OKA_TEST_TOKEN}
+slack:
+  webhook_url: https://hooks.example.com/$OKA_TEST_TOKEN
+mcp_servers:
+  remote:
+    url: https://${OKA_TEST_HOST}/mcp
+  local:
+    command: mcp-server
+    env:
+    - TOKEN=${OKA_TEST_TOKEN}
+    - EMPTY=${OKA_TEST_EMPTY}
+`), true)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	if conf.LLM.Token != "secret" {
+		t.Errorf("expected llm.token to be expanded, got %q", conf.LLM.Token)
+	}
+	if conf.Slack.WebhookURL != "https://hooks.example.com/secret" {
+		t.Errorf("expected slack.webhook_url to be expanded, got %q", conf.Slack.WebhookURL)
+	}
+	if url := conf.MCPServers["remote"].URL; url != "https://mcp.example.com/mcp" {
+		t.Errorf("expected the MCP server URL to be expanded, got %q", url)
+	}
+	if env := conf.MCPServers["local"].Env; !slices.Equal(env, []string{"TOKEN=secret", "EMPTY="}) {
+		t.Errorf("expected the MCP server env to be expanded, got %q", env)
+	}
+}
+
+func TestLoadConfigExpandUnsetEnv(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      string
+		expectedErr string
+	}{
+		{
+			name:        "llm token",
+			config:      "llm:\n  token: ${OKA_TEST_UNSET}\n",
+			expectedErr: "failed to expand llm.token: environment variables not set: OKA_TEST_UNSET",
+		},
+		{
+			name:        "mcp server env",
+			config:      "mcp_servers:\n  local:\n    command: mcp-server\n    env:\n    - TOKEN=$OKA_TEST_UNSET\n",
+			expectedErr: "failed to expand mcp_servers.local.env[0]",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadConfig(writeConfig(t, tc.config), true)
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("expected error containing %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	_ "embed"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// typeSource is the source of the configuration types, whose field comments
+// document the settings of the example configuration.
+//
+//go:embed type.go
+var typeSource []byte
+
+// Example returns a configuration file holding the default value of every
+// setting, each commented with the documentation of its field. The settings
+// are generated from the Config struct so that the example stays in sync
+// with it, collections without defaults are given a commented-out entry.
+func Example() ([]byte, error) {
+	docs, err := fieldDocs()
+	if err != nil {
+		return nil, err
+	}
+
+	e := exampleEncoder{docs: docs, examples: true}
+	document := &yaml.Node{
+		Kind:        yaml.DocumentNode,
+		HeadComment: "OKA configuration file, see https://github.com/giantswarm/oka/tree/main/pkg/config",
+		Content:     []*yaml.Node{e.encode(reflect.ValueOf(defaultConfig()))},
+	}
+
+	return yaml.Marshal(document)
+}
+
+// fieldDocs returns the comments of the configuration struct fields, keyed by
+// type and field name.
+func fieldDocs() (map[string]map[string]string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "type.go", typeSource, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config types: %w", err)
+	}
+
+	docs := make(map[string]map[string]string)
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		structType, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			return false
+		}
+
+		docs[spec.Name.Name] = make(map[string]string)
+		for _, field := range structType.Fields.List {
+			for _, name := range field.Names {
+				docs[spec.Name.Name][name.Name] = strings.TrimSpace(field.Comment.Text())
+			}
+		}
+
+		return false
+	})
+
+	return docs, nil
+}
+
+// exampleEncoder encodes configuration values into YAML nodes.
+type exampleEncoder struct {
+	// docs are the comments of the struct fields, nodes are not commented if
+	// nil.
+	docs map[string]map[string]string
+	// examples controls whether empty collections of structs are given a
+	// commented-out entry.
+	examples bool
+}
+
+// encode returns the YAML node of the value, keyed by the mapstructure tags
+// of the struct fields.
+func (e exampleEncoder) encode(v reflect.Value) *yaml.Node {
+	if v.Type() == reflect.TypeFor[time.Duration]() {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: time.Duration(v.Int()).String()}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+		}
+		return e.encode(v.Elem())
+	case reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			key := &yaml.Node{
+				Kind:        yaml.ScalarNode,
+				Value:       strings.Split(field.Tag.Get("mapstructure"), ",")[0],
+				HeadComment: e.docs[v.Type().Name()][field.Name],
+			}
+			value := e.encode(v.Field(i))
+			if value.Kind != yaml.ScalarNode && len(value.Content) == 0 {
+				key.HeadComment = strings.TrimSpace(key.HeadComment + "\n" + e.example(field.Type))
+			}
+			node.Content = append(node.Content, key, value)
+		}
+		return node
+	case reflect.Slice:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for i := range v.Len() {
+			node.Content = append(node.Content, e.encode(v.Index(i)))
+		}
+		if len(node.Content) == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		return node
+	case reflect.Map:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(a.String(), b.String())
+		})
+		for _, k := range keys {
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: k.String()},
+				e.encode(v.MapIndex(k)),
+			)
+		}
+		if len(node.Content) == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		return node
+	}
+
+	node := &yaml.Node{}
+	// Encoding a scalar cannot fail.
+	_ = node.Encode(v.Interface())
+
+	return node
+}
+
+// example returns a commented-out entry of the collection type, or an empty
+// string if its elements are not structs.
+func (e exampleEncoder) example(t reflect.Type) string {
+	if !e.examples {
+		return ""
+	}
+
+	elem := t.Elem()
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return ""
+	}
+
+	// Examples are not commented and do not nest other examples.
+	entry := exampleEncoder{}.encode(reflect.Zero(elem))
+	node := &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{entry}}
+	if t.Kind() == reflect.Map {
+		node = &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{{Kind: yaml.ScalarNode, Value: "name"}, entry}}
+	}
+
+	out, err := yaml.Marshal(node)
+	if err != nil {
+		return ""
+	}
+
+	return "Example:\n" + strings.TrimSpace(string(out))
+}
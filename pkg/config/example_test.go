@@ -0,0 +1,33 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExampleLoads(t *testing.T) {
+	example, err := Example()
+	if err != nil {
+		t.Fatalf("failed to generate example config: %v", err)
+	}
+
+	conf, err := LoadConfig(writeConfig(t, string(example)), true)
+	if err != nil {
+		t.Fatalf("failed to load example config: %v", err)
+	}
+
+	// The example holds the default value of the settings.
+	defaults := defaultConfig()
+	if conf.MaxCalls != defaults.MaxCalls {
+		t.Errorf("expected max_calls %d, got %d", defaults.MaxCalls, conf.MaxCalls)
+	}
+	if conf.OpsGenie.Interval != defaults.OpsGenie.Interval {
+		t.Errorf("expected opsgenie.interval %s, got %s", defaults.OpsGenie.Interval, conf.OpsGenie.Interval)
+	}
+	if conf.OpsGenie.QueryString != defaults.OpsGenie.QueryString {
+		t.Errorf("expected opsgenie.query_string %q, got %q", defaults.OpsGenie.QueryString, conf.OpsGenie.QueryString)
+	}
+	if len(conf.InitCommands) != 1 || conf.InitCommands[0].Command != defaults.InitCommands[0].Command || !slices.Equal(conf.InitCommands[0].Args, defaults.InitCommands[0].Args) {
+		t.Errorf("expected init_commands %+v, got %+v", defaults.InitCommands, conf.InitCommands)
+	}
+}
@@ -14,6 +14,7 @@ func BindFlags(cmd *cobra.Command) {
 	configFlags := pflag.NewFlagSet("config", pflag.ContinueOnError)
 
 	configFlags.String("log-file", defaultConfig().LogFile, "Path to log file (logs is disabled if not specified)")
+	configFlags.String("log-format", defaultConfig().LogFormat, "Log format to use, text or json")
 	configFlags.String("log-level", defaultConfig().LogLevel, "Log level to use. Available levels: "+strings.Join(logger.GetLevels(), ", "))
 	configFlags.String("sessions-log-dir", defaultConfig().SessionsLogDir, "Directory to store session logs")
 
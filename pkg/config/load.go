@@ -3,59 +3,140 @@ package config
 import (
 	_ "embed"
 	"fmt"
+	"log/slog"
 	"os"
 	"strings"
 	"text/tabwriter"
 	"time"
 
-	"github.com/opsgenie/opsgenie-go-sdk-v2/client"
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
+
+	"github.com/giantswarm/oka/pkg/logger"
 )
 
+// defaultAckNoteTemplate is the default template of the note added when
+// acknowledging an alert.
+const defaultAckNoteTemplate = `OKA started an automated investigation of this alert (session {{ .SessionID }}).` +
+	`{{ if .SlackHandle }} Results will be posted to Slack ({{ .SlackHandle }}).{{ end }}`
+
 var (
 	defaultConfig = func() Config {
 		return Config{
+			LogFormat:      logger.FormatText,
 			LogLevel:       "info",
 			MaxCalls:       20,
 			SessionsLogDir: "sessions",
 
+			Approval: Approval{
+				Timeout: 10 * time.Minute,
+			},
 			InitCommands: []Command{
 				{
 					Command: "tsh",
 					Args:    []string{"kube", "login", "--all"},
 				},
 			},
+			LLM: LLM{
+				MaxRetries:   3,
+				RetryBackoff: 5 * time.Second,
+			},
+			MCP: MCP{
+				HealthCheckInterval: time.Minute,
+			},
 			MCPServers: make(map[string]MCPServer),
+			Session: Session{
+				CallLimitMessage: "You must now complete your investigation and provide a final response.",
+				EndPhrase:        "investigation complete",
+				LLMCallTimeout:   3 * time.Minute,
+				PathTemplate:     "session-{{ .SessionID }}.log",
+				ToolCallTimeout:  3 * time.Minute,
+			},
+			InitCommandTimeout:     5 * time.Minute,
+			SessionInitCommandsTTL: time.Hour,
+			ShutdownTimeout:        5 * time.Minute,
 			OpsGenie: &OpsGenie{
-				APIUrl:      string(client.API_URL),
-				EnvVar:      "OPSGENIE_TOKEN",
-				Interval:    30 * time.Second,
-				QueryString: `responders: "{{ .Team }}" AND status: open`,
+				AckNoteTemplate:        defaultAckNoteTemplate,
+				ActionSource:           "oka",
+				ActionUser:             "OKA",
+				DetailFetchConcurrency: 4,
+				EnvVar:                 "OPSGENIE_TOKEN",
+				Interval:               30 * time.Second,
+				MaxRetries:             3,
+				MaxSnoozeDuration:      24 * time.Hour,
+				Mode:                   "poll",
+				QueryString:            `responders: "{{ .Team }}" AND status: open`,
+				Region:                 "us",
+				RetryBackoff:           time.Second,
+				WebhookAddress:         ":8081",
+				WebhookSecretEnvVar:    "OPSGENIE_WEBHOOK_SECRET",
 			},
 		}
 	}
 )
 
-func LoadConfig(cfgFile string) (*Config, error) {
+// LoadConfig loads the configuration from the provided file and validates it.
+// In strict mode, unknown configuration keys are errors, otherwise they are
+// logged and ignored.
+func LoadConfig(cfgFile string, strict bool) (*Config, error) {
 	config := defaultConfig()
 
-	if cfgFile == "" {
-		return &config, nil
+	if cfgFile != "" {
+		err := readConfig(cfgFile, strict, &config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := config.expandEnv()
+	if err != nil {
+		return nil, err
 	}
 
+	err = config.resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return &config, nil
+}
+
+// readConfig reads the configuration file into the provided configuration.
+func readConfig(cfgFile string, strict bool, config *Config) error {
 	viper.SetConfigFile(cfgFile)
 
 	err := viper.ReadInConfig()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	err = viper.UnmarshalExact(&config)
+	if strict {
+		err = viper.UnmarshalExact(config)
+		if err != nil {
+			return fmt.Errorf("failed to parse config file: %w", err)
+		}
+
+		return nil
+	}
+
+	var metadata mapstructure.Metadata
+	err = viper.Unmarshal(config, func(dc *mapstructure.DecoderConfig) {
+		dc.Metadata = &metadata
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+		return fmt.Errorf("failed to parse config file: %w", err)
 	}
 
-	return &config, nil
+	for _, key := range metadata.Unused {
+		slog.Warn("Ignoring unknown config key", "key", key)
+	}
+
+	return nil
 }
 
 func (conf *Config) Print() {
@@ -63,21 +144,120 @@ func (conf *Config) Print() {
 
 	fmt.Fprintf(w, "log_level:\t%s\n", conf.LogLevel)
 	fmt.Fprintf(w, "log_file:\t%s\n", conf.LogFile)
+	fmt.Fprintf(w, "log_format:\t%s\n", conf.LogFormat)
+	fmt.Fprintf(w, "log_max_age_days:\t%d\n", conf.LogMaxAgeDays)
+	fmt.Fprintf(w, "log_max_backups:\t%d\n", conf.LogMaxBackups)
+	fmt.Fprintf(w, "log_max_size_mb:\t%d\n", conf.LogMaxSizeMB)
 	fmt.Fprintf(w, "max_calls:\t%d\n", conf.MaxCalls)
+	fmt.Fprintf(w, "max_concurrent_sessions:\t%d\n", conf.MaxConcurrentSessions)
 	fmt.Fprintf(w, "runbook_dir:\t%s\n", conf.RunbookDir)
 	fmt.Fprintf(w, "slack_handle:\t%s\n", conf.SlackHandle)
+	fmt.Fprintf(w, "sessions_json:\t%t\n", conf.SessionsJSON)
 	fmt.Fprintf(w, "sessions_log_directory:\t%s\n", conf.SessionsLogDir)
+	fmt.Fprintf(w, "sessions_log_max_age:\t%s\n", conf.SessionsLogMaxAge)
+	fmt.Fprintf(w, "sessions_log_max_files:\t%d\n", conf.SessionsLogMaxFiles)
 	fmt.Fprintf(w, "init_commands:\t%d\n", len(conf.InitCommands))
 	for _, initCmd := range conf.InitCommands {
 		fmt.Fprintf(w, "\t- %s %s\n", initCmd.Command, strings.Join(initCmd.Args, " "))
 	}
+	fmt.Fprintf(w, "init_commands_ttl:\t%s\n", conf.InitCommandsTTL)
+	fmt.Fprintf(w, "init_command_timeout:\t%s\n", conf.InitCommandTimeout)
+	fmt.Fprintf(w, "session_init_commands:\t%d\n", len(conf.SessionInitCommands))
+	for _, initCmd := range conf.SessionInitCommands {
+		fmt.Fprintf(w, "\t- %s %s\n", initCmd.Command, strings.Join(initCmd.Args, " "))
+	}
+	fmt.Fprintf(w, "session_init_commands_ttl:\t%s\n", conf.SessionInitCommandsTTL)
+	fmt.Fprintf(w, "shutdown_timeout:\t%s\n", conf.ShutdownTimeout)
+	fmt.Fprintf(w, "approval.listen_address:\t%s\n", conf.Approval.ListenAddress)
+	fmt.Fprintf(w, "approval.require_approval:\t%s\n", strings.Join(conf.Approval.RequireApproval, ", "))
+	fmt.Fprintf(w, "approval.timeout:\t%s\n", conf.Approval.Timeout)
+	fmt.Fprintf(w, "approval.url:\t%s\n", conf.Approval.URL)
+	fmt.Fprintf(w, "events.listen_address:\t%s\n", conf.Events.ListenAddress)
+	fmt.Fprintf(w, "health.address:\t%s\n", conf.Health.Address)
+	fmt.Fprintf(w, "mcp.fail_fast:\t%t\n", conf.MCP.FailFast)
+	fmt.Fprintf(w, "mcp.health_check_interval:\t%s\n", conf.MCP.HealthCheckInterval)
+	fmt.Fprintf(w, "metrics.address:\t%s\n", conf.Metrics.Address)
+	fmt.Fprintf(w, "opsgenie.ack_note_template:\t%s\n", conf.OpsGenie.AckNoteTemplate)
+	fmt.Fprintf(w, "opsgenie.ack_on_start:\t%t\n", conf.OpsGenie.AckOnStart)
+	fmt.Fprintf(w, "opsgenie.action_source:\t%s\n", conf.OpsGenie.ActionSource)
+	fmt.Fprintf(w, "opsgenie.action_user:\t%s\n", conf.OpsGenie.ActionUser)
+	fmt.Fprintf(w, "opsgenie.alert_tools:\t%t\n", conf.OpsGenie.AlertTools)
+	fmt.Fprintf(w, "opsgenie.max_snooze_duration:\t%s\n", conf.OpsGenie.MaxSnoozeDuration)
+	fmt.Fprintf(w, "opsgenie.post_notes:\t%t\n", conf.OpsGenie.PostNotes)
+	fmt.Fprintf(w, "opsgenie.state_file:\t%s\n", conf.OpsGenie.StateFile)
 	fmt.Fprintf(w, "opsgenie.api_url:\t%s\n", conf.OpsGenie.APIUrl)
+	fmt.Fprintf(w, "opsgenie.region:\t%s\n", conf.OpsGenie.Region)
 	fmt.Fprintf(w, "opsgenie.query_string:\t%s\n", conf.OpsGenie.QueryString)
+	fmt.Fprintf(w, "opsgenie.query_tags:\t%v\n", conf.OpsGenie.QueryTags)
+	fmt.Fprintf(w, "opsgenie.enforce_team:\t%t\n", conf.OpsGenie.EnforceTeam)
 	fmt.Fprintf(w, "opsgenie.environment_variable:\t%s\n", conf.OpsGenie.EnvVar)
+	fmt.Fprintf(w, "opsgenie.domain:\t%s\n", conf.OpsGenie.Domain)
+	fmt.Fprintf(w, "opsgenie.group_by_incident:\t%t\n", conf.OpsGenie.GroupByIncident)
+	fmt.Fprintf(w, "opsgenie.fetch_incidents:\t%t\n", conf.OpsGenie.FetchIncidents)
+	fmt.Fprintf(w, "opsgenie.include_notes:\t%t\n", conf.OpsGenie.IncludeNotes)
+	fmt.Fprintf(w, "opsgenie.detail_fetch_concurrency:\t%d\n", conf.OpsGenie.DetailFetchConcurrency)
+	fmt.Fprintf(w, "opsgenie.detail_fetch_rate:\t%g\n", conf.OpsGenie.DetailFetchRate)
 	fmt.Fprintf(w, "opsgenie.interval:\t%s\n", conf.OpsGenie.Interval)
+	fmt.Fprintf(w, "opsgenie.max_retries:\t%d\n", conf.OpsGenie.MaxRetries)
+	fmt.Fprintf(w, "opsgenie.min_priority:\t%s\n", conf.OpsGenie.MinPriority)
+	fmt.Fprintf(w, "opsgenie.mode:\t%s\n", conf.OpsGenie.Mode)
+	fmt.Fprintf(w, "opsgenie.webhook_address:\t%s\n", conf.OpsGenie.WebhookAddress)
+	fmt.Fprintf(w, "opsgenie.webhook_secret_env_var:\t%s\n", conf.OpsGenie.WebhookSecretEnvVar)
+	fmt.Fprintf(w, "opsgenie.retry_backoff:\t%s\n", conf.OpsGenie.RetryBackoff)
 	fmt.Fprintf(w, "opsgenie.team:\t%s\n", conf.OpsGenie.Team)
+	fmt.Fprintf(w, "opsgenie.teams:\t%d\n", len(conf.OpsGenie.Teams))
+	for _, team := range conf.OpsGenie.Teams {
+		fmt.Fprintf(w, "\t- %s %s\n", team.Name, team.QueryString)
+	}
+	fmt.Fprintf(w, "opsgenie.unack_on_failure:\t%t\n", conf.OpsGenie.UnackOnFailure)
+	fmt.Fprintf(w, "session.alert_instructions:\t%d\n", len(conf.Session.AlertInstructions))
+	fmt.Fprintf(w, "session.call_limit_message:\t%s\n", conf.Session.CallLimitMessage)
+	fmt.Fprintf(w, "session.context_window_tokens:\t%d\n", conf.Session.ContextWindowTokens)
+	fmt.Fprintf(w, "session.end_phrase:\t%s\n", conf.Session.EndPhrase)
+	fmt.Fprintf(w, "session.hourly_budget:\t%d\n", conf.Session.HourlyBudget)
+	fmt.Fprintf(w, "session.image_field:\t%s\n", conf.Session.ImageField)
+	fmt.Fprintf(w, "session.llm_call_timeout:\t%s\n", conf.Session.LLMCallTimeout)
+	fmt.Fprintf(w, "session.multimodal:\t%t\n", conf.Session.Multimodal)
+	fmt.Fprintf(w, "session.path_template:\t%s\n", conf.Session.PathTemplate)
+	fmt.Fprintf(w, "session.system_prompt_file:\t%s\n", conf.Session.SystemPromptFile)
+	fmt.Fprintf(w, "session.timeout:\t%s\n", conf.Session.Timeout)
+	fmt.Fprintf(w, "session.tool_call_timeout:\t%s\n", conf.Session.ToolCallTimeout)
+	fmt.Fprintf(w, "slack.alert_url:\t%s\n", conf.Slack.AlertURL)
+	fmt.Fprintf(w, "slack.post_summaries:\t%t\n", conf.Slack.PostSummaries)
+	if conf.LLM.BaseURL != "" {
+		fmt.Fprintf(w, "llm.base_url:\t%s\n", conf.LLM.BaseURL)
+	}
+	fmt.Fprintf(w, "llm.fallbacks:\t%d\n", len(conf.LLM.Fallbacks))
+	for _, fallback := range conf.LLM.Fallbacks {
+		fmt.Fprintf(w, "\t- %s: %s\n", fallback.Provider, fallback.Model)
+	}
+	fmt.Fprintf(w, "llm.max_retries:\t%d\n", conf.LLM.MaxRetries)
+	if conf.LLM.MaxTokens != nil {
+		fmt.Fprintf(w, "llm.max_tokens:\t%d\n", *conf.LLM.MaxTokens)
+	}
 	fmt.Fprintf(w, "llm.model:\t%s\n", conf.LLM.Model)
+	fmt.Fprintf(w, "llm.params:\t%d\n", len(conf.LLM.Params))
+	for name, value := range conf.LLM.Params {
+		fmt.Fprintf(w, "\t- %s: %v\n", name, value)
+	}
+	fmt.Fprintf(w, "llm.prices:\t%d\n", len(conf.LLM.Prices))
+	for model, price := range conf.LLM.Prices {
+		fmt.Fprintf(w, "\t- %s: prompt %g, completion %g\n", model, price.Prompt, price.Completion)
+	}
 	fmt.Fprintf(w, "llm.provider:\t%s\n", conf.LLM.Provider)
+	if conf.LLM.Region != "" {
+		fmt.Fprintf(w, "llm.region:\t%s\n", conf.LLM.Region)
+	}
+	fmt.Fprintf(w, "llm.retry_backoff:\t%s\n", conf.LLM.RetryBackoff)
+	if conf.LLM.Seed != nil {
+		fmt.Fprintf(w, "llm.seed:\t%d\n", *conf.LLM.Seed)
+	}
+	if conf.LLM.Temperature != nil {
+		fmt.Fprintf(w, "llm.temperature:\t%g\n", *conf.LLM.Temperature)
+	}
+	if conf.LLM.TopP != nil {
+		fmt.Fprintf(w, "llm.top_p:\t%g\n", *conf.LLM.TopP)
+	}
 	fmt.Fprintf(w, "mcp_servers:\t%d\n", len(conf.MCPServers))
 	for name, server := range conf.MCPServers {
 		if server.Command != "" {
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/client"
+)
+
+// writeConfig writes the configuration to a temporary file and returns its
+// path.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "oka.yaml")
+	err := os.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	return path
+}
+
+func TestLoadConfigRegion(t *testing.T) {
+	testCases := []struct {
+		name           string
+		config         string
+		expectedAPIURL client.ApiUrl
+		expectedErr    string
+	}{
+		{
+			name:           "default region",
+			config:         "opsgenie: {}\n",
+			expectedAPIURL: client.API_URL,
+		},
+		{
+			name:           "eu region",
+			config:         "opsgenie:\n  region: eu\n",
+			expectedAPIURL: client.API_URL_EU,
+		},
+		{
+			name:           "api url takes precedence",
+			config:         "opsgenie:\n  region: eu\n  api_url: " + string(client.API_URL_SANDBOX) + "\n",
+			expectedAPIURL: client.API_URL_SANDBOX,
+		},
+		{
+			name:        "invalid region",
+			config:      "opsgenie:\n  region: apac\n",
+			expectedErr: `invalid opsgenie.region "apac"`,
+		},
+		{
+			name:        "invalid api url",
+			config:      "opsgenie:\n  api_url: api.example.com\n",
+			expectedErr: `invalid opsgenie.api_url "api.example.com"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf, err := LoadConfig(writeConfig(t, tc.config), true)
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Errorf("expected error containing %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to load config: %v", err)
+			}
+
+			if conf.OpsGenie.APIUrl != string(tc.expectedAPIURL) {
+				t.Errorf("expected API URL %s, got %s", tc.expectedAPIURL, conf.OpsGenie.APIUrl)
+			}
+		})
+	}
+}
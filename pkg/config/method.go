@@ -16,6 +16,47 @@ func (c Config) GetMCPServers(shared bool) MCPServers {
 	return sharedServers
 }
 
+// GetTeams returns the teams whose alerts are fetched, with their query
+// string: the configured teams, or the single team otherwise, which may be
+// empty if the query string does not reference it. The teams without query
+// string use the default one.
+func (o OpsGenie) GetTeams() []OpsGenieTeam {
+	teams := o.Teams
+	if len(teams) == 0 {
+		teams = []OpsGenieTeam{{Name: o.Team}}
+	}
+
+	resolved := make([]OpsGenieTeam, 0, len(teams))
+	for _, team := range teams {
+		if team.QueryString == "" {
+			team.QueryString = o.QueryString
+		}
+		resolved = append(resolved, team)
+	}
+
+	return resolved
+}
+
+// MCP server transports.
+const (
+	TransportHTTP  = "http"
+	TransportSSE   = "sse"
+	TransportStdio = "stdio"
+)
+
+// TransportType returns the transport of the MCP server. Unless configured,
+// it is http if a URL is given and stdio otherwise.
+func (s MCPServer) TransportType() string {
+	switch {
+	case s.Transport != "":
+		return s.Transport
+	case s.URL != "":
+		return TransportHTTP
+	}
+
+	return TransportStdio
+}
+
 // IsShared returns true if the MCP server is shared across sessions.
 // A server is considered shared if the `Shared` field is nil or if it is
 // explicitly set to true.
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// reloadableSettings are the settings applied when the configuration is
+// reloaded, the others require a restart.
+var reloadableSettings = []string{
+	"log_level",
+	"max_calls",
+	"opsgenie.interval",
+	"opsgenie.query_string",
+	"opsgenie.query_tags",
+	"opsgenie.team",
+	"opsgenie.teams",
+}
+
+// IgnoredChanges returns the settings changed between the two configurations
+// which are not applied on reload, e.g. to log them.
+func IgnoredChanges(old, new *Config) []string {
+	var changes []string
+	for _, setting := range changedSettings("", reflect.ValueOf(*old), reflect.ValueOf(*new)) {
+		if !slices.Contains(reloadableSettings, setting) {
+			changes = append(changes, setting)
+		}
+	}
+
+	return changes
+}
+
+// changedSettings returns the names of the settings differing between two
+// configuration structs, descending into the nested configuration structs.
+func changedSettings(prefix string, old, new reflect.Value) []string {
+	var changes []string
+	for i := range old.NumField() {
+		field := old.Type().Field(i)
+		name := prefix + strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+
+		oldValue, newValue := old.Field(i), new.Field(i)
+		if oldValue.Kind() == reflect.Pointer && newValue.Kind() == reflect.Pointer && !oldValue.IsNil() && !newValue.IsNil() {
+			oldValue, newValue = oldValue.Elem(), newValue.Elem()
+		}
+
+		if oldValue.Kind() == reflect.Struct {
+			changes = append(changes, changedSettings(name+".", oldValue, newValue)...)
+			continue
+		}
+
+		if !reflect.DeepEqual(oldValue.Interface(), newValue.Interface()) {
+			changes = append(changes, name)
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIgnoredChanges(t *testing.T) {
+	old, err := LoadConfig(writeConfig(t, "max_calls: 10\nopsgenie:\n  interval: 1m\nllm:\n  model: a\n"), true)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		config   string
+		expected []string
+	}{
+		{
+			name:   "unchanged",
+			config: "max_calls: 10\nopsgenie:\n  interval: 1m\nllm:\n  model: a\n",
+		},
+		{
+			name:   "reloadable settings",
+			config: "max_calls: 20\nlog_level: debug\nopsgenie:\n  interval: 5m\n  query_string: \"status: open\"\nllm:\n  model: a\n",
+		},
+		{
+			name:     "settings requiring a restart",
+			config:   "max_calls: 20\nopsgenie:\n  interval: 5m\n  detail_fetch_concurrency: 8\nllm:\n  model: b\n",
+			expected: []string{"llm.model", "opsgenie.detail_fetch_concurrency"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			new, err := LoadConfig(writeConfig(t, tc.config), true)
+			if err != nil {
+				t.Fatalf("failed to load config: %v", err)
+			}
+
+			if ignored := IgnoredChanges(old, new); !slices.Equal(ignored, tc.expected) {
+				t.Errorf("expected ignored changes %q, got %q", tc.expected, ignored)
+			}
+		})
+	}
+}
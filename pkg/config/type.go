@@ -7,28 +7,134 @@ import (
 // Config represents the application's configuration. It holds settings for
 // logging, LLM, OpsGenie, MCP servers, and other operational parameters.
 type Config struct {
-	LogLevel         string           `mapstructure:"log_level"`         // Log level for the application (e.g., "debug", "info", "error")
-	LogFile          string           `mapstructure:"log_file"`          // Path to the log file, if empty logging is disabled
-	MaxCalls         int              `mapstructure:"max_calls"`         // Maximum number of calls to the LLM per session
-	RunbookDir       string           `mapstructure:"runbook_dir"`       // Directory containing runbooks for the application
-	RunbookContainer RunbookContainer `mapstructure:"runbook_container"` // Configuration for the runbook container, including image and port
-	SessionsLogDir   string           `mapstructure:"sessions_log_dir"`  // Directory to store session logs
-	SlackHandle      string           `mapstructure:"slack_handle"`      // Slack handle to use for notifications
+	LogLevel              string           `mapstructure:"log_level"`               // Log level for the application (e.g., "debug", "info", "error")
+	LogFile               string           `mapstructure:"log_file"`                // Path to the log file, if empty logging is disabled
+	LogFormat             string           `mapstructure:"log_format"`              // Format of the logs, "text" or "json"
+	LogMaxAgeDays         int              `mapstructure:"log_max_age_days"`        // Number of days after which rotated log files are removed, kept if 0
+	LogMaxBackups         int              `mapstructure:"log_max_backups"`         // Number of rotated log files kept, all of them if 0
+	LogMaxSizeMB          int              `mapstructure:"log_max_size_mb"`         // Size in megabytes above which the log file is rotated, 100 if 0
+	MaxCalls              int              `mapstructure:"max_calls"`               // Maximum number of calls to the LLM per session
+	MaxConcurrentSessions int              `mapstructure:"max_concurrent_sessions"` // Maximum number of sessions running concurrently, unlimited if 0
+	RunbookDir            string           `mapstructure:"runbook_dir"`             // Directory containing runbooks for the application
+	RunbookContainer      RunbookContainer `mapstructure:"runbook_container"`       // Configuration for the runbook container, including image and port
+	SessionsJSON          bool             `mapstructure:"sessions_json"`           // Whether to write a structured JSON transcript alongside the session logs
+	SessionsLogDir        string           `mapstructure:"sessions_log_dir"`        // Directory to store session logs
+	SessionsLogMaxAge     time.Duration    `mapstructure:"sessions_log_max_age"`    // Age above which the session logs are removed, kept if 0
+	SessionsLogMaxFiles   int              `mapstructure:"sessions_log_max_files"`  // Number of session logs kept, the oldest ones are removed, all of them if 0
+	SlackHandle           string           `mapstructure:"slack_handle"`            // Slack handle to use for notifications
 
-	InitCommands []Command  `mapstructure:"init_commands"` // Commands to run during initialization
-	LLM          LLM        `mapstructure:"llm"`           // LLM configuration for the application
-	MCPServers   MCPServers `mapstructure:"mcp_servers"`   // MCP servers to configure
-	OpsGenie     *OpsGenie  `mapstructure:"opsgenie"`      // OpsGenie configuration for fetching alerts
+	Approval               Approval      `mapstructure:"approval"`                  // Approval configuration for the tool calls requiring a human approval
+	Events                 Events        `mapstructure:"events"`                    // Events configuration for streaming the sessions' progress
+	Health                 Health        `mapstructure:"health"`                    // Health configuration for the liveness and readiness probes
+	InitCommandTimeout     time.Duration `mapstructure:"init_command_timeout"`      // Timeout for running each init and session init command, unlimited if 0
+	InitCommands           []Command     `mapstructure:"init_commands"`             // Commands to run during initialization
+	InitCommandsTTL        time.Duration `mapstructure:"init_commands_ttl"`         // Duration after which the init commands run again before a session, only at startup if 0
+	LLM                    LLM           `mapstructure:"llm"`                       // LLM configuration for the application
+	MCP                    MCP           `mapstructure:"mcp"`                       // MCP configuration for managing the clients of the MCP servers
+	MCPServers             MCPServers    `mapstructure:"mcp_servers"`               // MCP servers to configure
+	Metrics                Metrics       `mapstructure:"metrics"`                   // Metrics configuration for exposing the Prometheus metrics
+	OpsGenie               *OpsGenie     `mapstructure:"opsgenie"`                  // OpsGenie configuration for fetching alerts
+	Session                Session       `mapstructure:"session"`                   // Session configuration for investigating alerts
+	SessionInitCommands    []Command     `mapstructure:"session_init_commands"`     // Commands templated with the alert data to run before each session
+	SessionInitCommandsTTL time.Duration `mapstructure:"session_init_commands_ttl"` // Duration during which a successful session init command is not run again
+	ShutdownTimeout        time.Duration `mapstructure:"shutdown_timeout"`          // Duration to wait for the running sessions when stopping, unlimited if 0
+	Slack                  Slack         `mapstructure:"slack"`                     // Slack configuration for posting the investigation summaries
 }
 
 // OpsGenie holds the configuration for the OpsGenie integration, including API
 // settings, alert filtering, and polling interval.
 type OpsGenie struct {
-	APIUrl      string        `mapstructure:"api_url"`      // API URL is the OpsGenie API endpoint URL, defaults to the official API URL
-	EnvVar      string        `mapstructure:"env_var"`      // Environment variable for the OpsGenie API token
-	Interval    time.Duration `mapstructure:"interval"`     // Interval for fetching alerts
-	QueryString string        `mapstructure:"query_string"` // Query string to filter alerts, e.g., "status:open AND tags:team"
-	Team        string        `mapstructure:"team"`         // Team name to filter alerts
+	AckNoteTemplate        string         `mapstructure:"ack_note_template"`        // Template of the note added when acknowledging an alert
+	AckOnStart             bool           `mapstructure:"ack_on_start"`             // Whether to acknowledge alerts when a session starts investigating them
+	ActionSource           string         `mapstructure:"action_source"`            // Source displayed for actions performed by OKA in OpsGenie
+	ActionUser             string         `mapstructure:"action_user"`              // User displayed for actions performed by OKA in OpsGenie
+	AlertTools             bool           `mapstructure:"alert_tools"`              // Whether to give the LLM tools acting on the investigated alert, e.g. closing or escalating it
+	APIUrl                 string         `mapstructure:"api_url"`                  // API URL is the OpsGenie API endpoint host, derived from the region if empty
+	EnforceTeam            bool           `mapstructure:"enforce_team"`             // Whether to refuse investigating alerts given by ID which do not belong to the team
+	DetailFetchConcurrency int            `mapstructure:"detail_fetch_concurrency"` // Maximum number of alert details fetched concurrently
+	DetailFetchRate        float64        `mapstructure:"detail_fetch_rate"`        // Maximum number of alert details fetched per second, unlimited if 0
+	Domain                 string         `mapstructure:"domain"`                   // Domain of the OpsGenie account (e.g., "example" for example.app.opsgenie.com) used to link the alerts
+	EnvVar                 string         `mapstructure:"env_var"`                  // Environment variable for the OpsGenie API token
+	FetchIncidents         bool           `mapstructure:"fetch_incidents"`          // Whether to also investigate the open incidents, each in its own session
+	GroupByIncident        bool           `mapstructure:"group_by_incident"`        // Whether to investigate the alerts of an incident in a single session
+	IncludeNotes           bool           `mapstructure:"include_notes"`            // Whether to include the notes already left on the alert in the session context
+	Interval               time.Duration  `mapstructure:"interval"`                 // Interval for fetching alerts
+	MaxRetries             int            `mapstructure:"max_retries"`              // Number of times transient failures of fetching alerts are retried
+	MaxSnoozeDuration      time.Duration  `mapstructure:"max_snooze_duration"`      // Maximum duration the snooze_alert tool snoozes the investigated alert for
+	MinPriority            string         `mapstructure:"min_priority"`             // Minimum priority of the alerts to investigate (e.g., "P2"), all priorities if empty
+	Mode                   string         `mapstructure:"mode"`                     // Mode of receiving the alerts, "poll" or "webhook"
+	PostNotes              bool           `mapstructure:"post_notes"`               // Whether to add the final response of the sessions to their alert as a note
+	QueryString            string         `mapstructure:"query_string"`             // Query string to filter alerts, e.g., "status:open AND tags:team"
+	QueryTags              []string       `mapstructure:"query_tags"`               // Tags available to the query string as the {{ .Tags }} placeholder
+	Region                 string         `mapstructure:"region"`                   // OpsGenie region ("us" or "eu") used to derive the API URL when it is not set
+	RetryBackoff           time.Duration  `mapstructure:"retry_backoff"`            // Initial delay between two attempts of fetching alerts, growing exponentially
+	StateFile              string         `mapstructure:"state_file"`               // File recording the alerts already dispatched to sessions across restarts, disabled if empty
+	Team                   string         `mapstructure:"team"`                     // Team name to filter alerts
+	Teams                  []OpsGenieTeam `mapstructure:"teams"`                    // Teams whose alerts are fetched, each with its own query, replacing team if set
+	UnackOnFailure         bool           `mapstructure:"unack_on_failure"`         // Whether to unacknowledge alerts when their session fails
+	WebhookAddress         string         `mapstructure:"webhook_address"`          // Address to receive the webhook requests on in webhook mode
+	WebhookSecretEnvVar    string         `mapstructure:"webhook_secret_env_var"`   // Environment variable for the shared secret of the webhook requests
+}
+
+// OpsGenieTeam is a team whose alerts are fetched from OpsGenie.
+type OpsGenieTeam struct {
+	Name        string `mapstructure:"name"`         // Team name used for the {{ .Team }} placeholder
+	QueryString string `mapstructure:"query_string"` // Query string fetching the alerts of the team, opsgenie.query_string if empty
+}
+
+// Approval holds the configuration of the tool calls requiring an approval
+// before being executed.
+type Approval struct {
+	ListenAddress   string        `mapstructure:"listen_address"`   // Address to serve the approval pages on (e.g., ":8083")
+	RequireApproval []string      `mapstructure:"require_approval"` // Tools whose calls require an approval, by their name exposed to the LLM
+	Timeout         time.Duration `mapstructure:"timeout"`          // Duration after which a tool call waiting for approval is denied
+	URL             string        `mapstructure:"url"`              // External URL of the approval server, linked in the approval requests
+}
+
+// Events holds the configuration of the server streaming the sessions' events.
+type Events struct {
+	ListenAddress string `mapstructure:"listen_address"` // Address to serve the events on (e.g., ":8080"), disabled if empty
+}
+
+// Health holds the configuration of the server of the liveness and readiness
+// probes.
+type Health struct {
+	Address string `mapstructure:"address"` // Address to serve the probes on (e.g., ":8082"), disabled if empty
+}
+
+// MCP holds the configuration of the clients of the MCP servers.
+type MCP struct {
+	FailFast            bool          `mapstructure:"fail_fast"`             // Whether an MCP server failing to register is an error, it is skipped otherwise
+	HealthCheckInterval time.Duration `mapstructure:"health_check_interval"` // Interval for pinging the shared MCP servers and reconnecting them, disabled if 0
+}
+
+// Metrics holds the configuration of the server exposing the Prometheus
+// metrics.
+type Metrics struct {
+	Address string `mapstructure:"address"` // Address to serve the metrics on (e.g., ":9090"), disabled if empty
+}
+
+// Slack holds the configuration of the Slack notifications.
+type Slack struct {
+	AlertURL      string `mapstructure:"alert_url"`      // URL to which the alert ID is appended to link the alerts in the summaries (e.g., "https://example.app.opsgenie.com/alert/detail/")
+	PostSummaries bool   `mapstructure:"post_summaries"` // Whether to post the final response of the sessions to Slack
+	WebhookURL    string `mapstructure:"webhook_url"`    // URL of the Slack incoming webhook to post to
+}
+
+// Session holds the configuration of the sessions investigating alerts.
+type Session struct {
+	AlertInstructions   []AlertInstruction `mapstructure:"alert_instructions"`    // Instructions given to the LLM for the matching alerts
+	CallLimitMessage    string             `mapstructure:"call_limit_message"`    // Message sent to the LLM on its last call before hitting max_calls
+	ContextWindowTokens int                `mapstructure:"context_window_tokens"` // Estimated number of tokens above which old tool responses are trimmed from the context, disabled if 0
+	EndPhrase           string             `mapstructure:"end_phrase"`            // Phrase ending the session when the LLM response contains it, matched case-insensitively
+	HourlyBudget        int                `mapstructure:"hourly_budget"`         // Maximum number of sessions started per rolling hour, unlimited if 0
+	ImageField          string             `mapstructure:"image_field"`           // Alert detail field holding the URLs of images to attach to the session
+	LLMCallTimeout      time.Duration      `mapstructure:"llm_call_timeout"`      // Timeout for calling the LLM
+	Multimodal          bool               `mapstructure:"multimodal"`            // Whether to attach the alert and tool images to the session, the model must support images
+	PathTemplate        string             `mapstructure:"path_template"`         // Template of the session log file path, relative to sessions_log_dir
+	SystemPromptFile    string             `mapstructure:"system_prompt_file"`    // Template file of the system prompt replacing the built-in one, the built-in one is used if empty
+	Timeout             time.Duration      `mapstructure:"timeout"`               // Maximum duration of a session, including its LLM and tool calls, unlimited if 0
+	ToolCallTimeout     time.Duration      `mapstructure:"tool_call_timeout"`     // Timeout for calling the tools without a specific timeout
 }
 
 // MCPServers is a map of MCP server configurations, where the key is the server
@@ -38,21 +144,51 @@ type MCPServers map[string]MCPServer
 // MCPServer represents the configuration for an MCP server, including the
 // command to run, arguments, environment variables, and other settings.
 type MCPServer struct {
-	Args                     []string `mapstructure:"args"`                                 // Arguments for the MCP server command
-	Command                  string   `mapstructure:"command"`                              // Command to run the MCP server
-	Disabled                 bool     `mapstructure:"disabled,omitempty"`                   // Whether this server is disabled
-	Env                      []string `mapstructure:"env"`                                  // Environment variables for the MCP server command
-	InitializeTimeoutSeconds *int     `mapstructure:"initialize_timeout_seconds,omitempty"` // Timeout for server initialization in seconds
-	Shared                   *bool    `mapstructure:"shared,omitempty"`                     // Whether this server is shared across sessions
-	URL                      string   `mapstructure:"url"`                                  // URL of the MCP server
+	AllowedTools             []string                 `mapstructure:"allowed_tools,omitempty"`              // Tools exposed to the LLM, all of them if empty
+	Args                     []string                 `mapstructure:"args"`                                 // Arguments for the MCP server command
+	Command                  string                   `mapstructure:"command"`                              // Command to run the MCP server
+	DeniedTools              []string                 `mapstructure:"denied_tools,omitempty"`               // Tools never exposed to the LLM, taking precedence over allowed_tools
+	Disabled                 bool                     `mapstructure:"disabled,omitempty"`                   // Whether this server is disabled
+	Env                      []string                 `mapstructure:"env"`                                  // Environment variables for the MCP server command
+	InitializeTimeoutSeconds *int                     `mapstructure:"initialize_timeout_seconds,omitempty"` // Timeout for server initialization in seconds
+	Resources                bool                     `mapstructure:"resources,omitempty"`                  // Whether to expose the resources of the MCP server through a read_resource tool
+	Shared                   *bool                    `mapstructure:"shared,omitempty"`                     // Whether this server is shared across sessions
+	ToolTimeouts             map[string]time.Duration `mapstructure:"tool_timeouts,omitempty"`              // Timeouts for calling specific tools, keyed by tool name
+	Transport                string                   `mapstructure:"transport,omitempty"`                  // Transport of the MCP server, "stdio", "http" or "sse", from command and url if empty
+	URL                      string                   `mapstructure:"url"`                                  // URL of the MCP server
 }
 
 // LLM holds the configuration for the Large Language Model, including the
 // provider, model name, and API token.
 type LLM struct {
-	Model    string `mapstructure:"model"`    // Model name (e.g., "gpt-3.5-turbo", "claude-2")
-	Provider string `mapstructure:"provider"` // LLM provider (e.g., "openai", "anthropic")
-	Token    string `mapstructure:"token"`    // API token for the LLM provider
+	BaseURL      string                `mapstructure:"base_url,omitempty"`    // Base URL of the LLM provider API, e.g. a local OpenAI-compatible server
+	Fallbacks    []LLM                 `mapstructure:"fallbacks,omitempty"`   // LLMs used in order when the previous one keeps failing, with the same history
+	MaxRetries   int                   `mapstructure:"max_retries"`           // Number of times transient failures of LLM calls are retried
+	MaxTokens    *int                  `mapstructure:"max_tokens,omitempty"`  // Maximum number of tokens generated per LLM call, provider default if unset
+	Model        string                `mapstructure:"model"`                 // Model name (e.g., "gpt-3.5-turbo", "claude-2")
+	Prices       map[string]TokenPrice `mapstructure:"prices,omitempty"`      // Price per 1K tokens keyed by model, used to estimate the cost of the sessions
+	Params       map[string]any        `mapstructure:"params,omitempty"`      // Generation parameters passed through to the provider (e.g., "top_p")
+	Provider     string                `mapstructure:"provider"`              // LLM provider (e.g., "openai", "anthropic")
+	Region       string                `mapstructure:"region,omitempty"`      // AWS region of the Bedrock provider, from the AWS environment if empty
+	RetryBackoff time.Duration         `mapstructure:"retry_backoff"`         // Initial delay between two attempts of an LLM call, growing exponentially
+	Seed         *int                  `mapstructure:"seed,omitempty"`        // Seed for deterministic sampling, if supported by the provider
+	Temperature  *float64              `mapstructure:"temperature,omitempty"` // Sampling temperature, e.g. 0 for deterministic answers, provider default if unset
+	Token        string                `mapstructure:"token"`                 // API token for the LLM provider
+	TopP         *float64              `mapstructure:"top_p,omitempty"`       // Nucleus sampling probability mass, provider default if unset
+}
+
+// TokenPrice is the price of 1K tokens of an LLM model, in any currency.
+type TokenPrice struct {
+	Completion float64 `mapstructure:"completion"` // Price of 1K completion (output) tokens
+	Prompt     float64 `mapstructure:"prompt"`     // Price of 1K prompt (input) tokens
+}
+
+// AlertInstruction is an instruction given to the LLM when investigating the
+// alerts matching any of its tags or its message.
+type AlertInstruction struct {
+	Instruction string   `mapstructure:"instruction"` // Instruction added to the session context, e.g. "Check the PVC usage first"
+	Message     string   `mapstructure:"message"`     // Substring of the alert message matching the alerts, case-insensitive
+	Tags        []string `mapstructure:"tags"`        // Tags matching the alerts having any of them
 }
 
 // Command represents a command to be executed, including its arguments and
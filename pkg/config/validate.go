@@ -0,0 +1,152 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/client"
+
+	"github.com/giantswarm/oka/pkg/logger"
+	"github.com/giantswarm/oka/pkg/templates"
+)
+
+// opsGenieRegions maps the OpsGenie regions to their API endpoint.
+var opsGenieRegions = map[string]client.ApiUrl{
+	"us": client.API_URL,
+	"eu": client.API_URL_EU,
+}
+
+// opsGenieAPIURLs are the API endpoints supported by the OpsGenie client.
+var opsGenieAPIURLs = []client.ApiUrl{
+	client.API_URL,
+	client.API_URL_EU,
+	client.API_URL_SANDBOX,
+}
+
+// opsGeniePriorities are the valid OpsGenie alert priorities.
+var opsGeniePriorities = []string{"P1", "P2", "P3", "P4", "P5"}
+
+// resolve derives the settings which depend on others, e.g. the OpsGenie API
+// URL from its region when no URL is configured.
+func (c *Config) resolve() error {
+	if c.OpsGenie.APIUrl == "" {
+		apiURL, ok := opsGenieRegions[c.OpsGenie.Region]
+		if !ok {
+			return fmt.Errorf("invalid opsgenie.region %q, must be one of us, eu", c.OpsGenie.Region)
+		}
+		c.OpsGenie.APIUrl = string(apiURL)
+	}
+
+	return nil
+}
+
+// Validate returns an error if the configuration is invalid, joining the
+// errors of every invalid setting.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.LogFormat != logger.FormatText && c.LogFormat != logger.FormatJSON {
+		errs = append(errs, fmt.Errorf("invalid log_format %q, must be one of %s, %s", c.LogFormat, logger.FormatText, logger.FormatJSON))
+	}
+
+	if !slices.Contains(opsGenieAPIURLs, client.ApiUrl(c.OpsGenie.APIUrl)) {
+		errs = append(errs, fmt.Errorf("invalid opsgenie.api_url %q, must be one of %s, %s, %s", c.OpsGenie.APIUrl, client.API_URL, client.API_URL_EU, client.API_URL_SANDBOX))
+	}
+
+	if c.Session.LLMCallTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("invalid session.llm_call_timeout %s, must be positive", c.Session.LLMCallTimeout))
+	}
+
+	if c.Session.ToolCallTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("invalid session.tool_call_timeout %s, must be positive", c.Session.ToolCallTimeout))
+	}
+
+	if c.OpsGenie.MaxSnoozeDuration <= 0 {
+		errs = append(errs, fmt.Errorf("invalid opsgenie.max_snooze_duration %s, must be positive", c.OpsGenie.MaxSnoozeDuration))
+	}
+
+	if c.OpsGenie.MinPriority != "" && !slices.Contains(opsGeniePriorities, c.OpsGenie.MinPriority) {
+		errs = append(errs, fmt.Errorf("invalid opsgenie.min_priority %q, must be one of %s", c.OpsGenie.MinPriority, strings.Join(opsGeniePriorities, ", ")))
+	}
+
+	if c.Slack.PostSummaries && c.Slack.WebhookURL == "" {
+		errs = append(errs, errors.New("slack.webhook_url must be set to post summaries to Slack"))
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(c.MCPServers)) {
+		server := c.MCPServers[name]
+		if server.Disabled {
+			continue
+		}
+
+		switch server.TransportType() {
+		case TransportHTTP, TransportSSE:
+			if server.URL == "" {
+				errs = append(errs, fmt.Errorf("mcp_servers.%s.url must be set for the %s transport", name, server.TransportType()))
+			}
+		case TransportStdio:
+			if server.Command == "" {
+				errs = append(errs, fmt.Errorf("mcp_servers.%s.command must be set for the stdio transport", name))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("invalid mcp_servers.%s.transport %q, must be one of %s, %s, %s", name, server.Transport, TransportStdio, TransportHTTP, TransportSSE))
+		}
+	}
+
+	for i, team := range c.OpsGenie.Teams {
+		if team.Name == "" {
+			errs = append(errs, fmt.Errorf("opsgenie.teams[%d].name must be set", i))
+		}
+	}
+
+	for _, team := range c.OpsGenie.GetTeams() {
+		if team.QueryString == "" {
+			continue
+		}
+
+		_, err := templates.ParseQuery(team.QueryString)
+		if err != nil {
+			if team.Name != "" {
+				errs = append(errs, fmt.Errorf("invalid query string of opsgenie team %s: %w", team.Name, err))
+			} else {
+				errs = append(errs, fmt.Errorf("invalid opsgenie.query_string: %w", err))
+			}
+		}
+	}
+
+	if c.Session.SystemPromptFile != "" {
+		text, err := os.ReadFile(c.Session.SystemPromptFile)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid session.system_prompt_file: %w", err))
+		} else if _, err := templates.ParseSystemPrompt(string(text)); err != nil {
+			errs = append(errs, fmt.Errorf("invalid session.system_prompt_file: %w", err))
+		}
+	}
+
+	for i, instruction := range c.Session.AlertInstructions {
+		if instruction.Instruction == "" {
+			errs = append(errs, fmt.Errorf("session.alert_instructions[%d].instruction must be set", i))
+		}
+		if instruction.Message == "" && len(instruction.Tags) == 0 {
+			errs = append(errs, fmt.Errorf("session.alert_instructions[%d] must match alerts by message or tags", i))
+		}
+	}
+
+	if len(c.Approval.RequireApproval) > 0 {
+		if c.Slack.WebhookURL == "" {
+			errs = append(errs, errors.New("slack.webhook_url must be set to request approvals"))
+		}
+		if c.Approval.ListenAddress == "" || c.Approval.URL == "" {
+			errs = append(errs, errors.New("approval.listen_address and approval.url must be set to request approvals"))
+		}
+		if c.Approval.Timeout <= 0 {
+			errs = append(errs, fmt.Errorf("invalid approval.timeout %s, must be positive", c.Approval.Timeout))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateMCPServerTransport(t *testing.T) {
+	testCases := []struct {
+		name              string
+		server            MCPServer
+		expectedTransport string
+		expectedErr       string
+	}{
+		{
+			name:              "url",
+			server:            MCPServer{URL: "http://localhost/mcp"},
+			expectedTransport: TransportHTTP,
+		},
+		{
+			name:              "command",
+			server:            MCPServer{Command: "mcp-server"},
+			expectedTransport: TransportStdio,
+		},
+		{
+			name:              "sse",
+			server:            MCPServer{Transport: TransportSSE, URL: "http://localhost/sse"},
+			expectedTransport: TransportSSE,
+		},
+		{
+			name:              "sse without url",
+			server:            MCPServer{Transport: TransportSSE, Command: "mcp-server"},
+			expectedTransport: TransportSSE,
+			expectedErr:       "mcp_servers.test.url must be set for the sse transport",
+		},
+		{
+			name:              "http without url",
+			server:            MCPServer{Transport: TransportHTTP},
+			expectedTransport: TransportHTTP,
+			expectedErr:       "mcp_servers.test.url must be set for the http transport",
+		},
+		{
+			name:              "stdio without command",
+			server:            MCPServer{Transport: TransportStdio, URL: "http://localhost/mcp"},
+			expectedTransport: TransportStdio,
+			expectedErr:       "mcp_servers.test.command must be set for the stdio transport",
+		},
+		{
+			name:              "neither url nor command",
+			server:            MCPServer{},
+			expectedTransport: TransportStdio,
+			expectedErr:       "mcp_servers.test.command must be set for the stdio transport",
+		},
+		{
+			name:              "invalid transport",
+			server:            MCPServer{Transport: "websocket", URL: "http://localhost/mcp"},
+			expectedTransport: "websocket",
+			expectedErr:       `invalid mcp_servers.test.transport "websocket"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if transport := tc.server.TransportType(); transport != tc.expectedTransport {
+				t.Errorf("expected transport %s, got %s", tc.expectedTransport, transport)
+			}
+
+			conf := defaultConfig()
+			conf.MCPServers = MCPServers{"test": tc.server}
+			if err := conf.resolve(); err != nil {
+				t.Fatalf("failed to resolve config: %v", err)
+			}
+
+			err := conf.Validate()
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Errorf("expected the config to be valid, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("expected error containing %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigTemplates(t *testing.T) {
+	testCases := []struct {
+		name         string
+		config       string
+		systemPrompt *string
+		expectedErr  string
+	}{
+		{
+			name:   "valid query string",
+			config: "opsgenie:\n  query_string: 'status: open AND createdAt > {{ .Today }}'\n",
+		},
+		{
+			name:        "broken query string",
+			config:      "opsgenie:\n  query_string: 'status: open AND createdAt > {{ .Today '\n",
+			expectedErr: "invalid opsgenie.query_string: template: opsgenieQuery:1: unclosed action",
+		},
+		{
+			name:        "unknown query function",
+			config:      "opsgenie:\n  query_string: 'createdAt > {{ yesterday }}'\n",
+			expectedErr: `invalid opsgenie.query_string: template: opsgenieQuery:1: function "yesterday" not defined`,
+		},
+		{
+			name:        "broken team query string",
+			config:      "opsgenie:\n  teams:\n  - name: team-a\n  - name: team-b\n    query_string: 'responder: {{ .Team'\n",
+			expectedErr: "invalid query string of opsgenie team team-b",
+		},
+		{
+			name:         "valid system prompt",
+			systemPrompt: ptr(`Investigate {{ .AlertID | default "the alert" }}`),
+		},
+		{
+			name:         "broken system prompt",
+			systemPrompt: ptr("Investigate {{ .AlertID "),
+			expectedErr:  "invalid session.system_prompt_file: template: system-prompt:1: unclosed action",
+		},
+		{
+			name:        "missing system prompt",
+			config:      "session:\n  system_prompt_file: /nonexistent/system-prompt.tmpl\n",
+			expectedErr: "invalid session.system_prompt_file",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := tc.config
+			if tc.systemPrompt != nil {
+				path := filepath.Join(t.TempDir(), "system-prompt.tmpl")
+				err := os.WriteFile(path, []byte(*tc.systemPrompt), 0600)
+				if err != nil {
+					t.Fatalf("failed to write system prompt template: %v", err)
+				}
+				config += fmt.Sprintf("session:\n  system_prompt_file: %s\n", path)
+			}
+
+			_, err := LoadConfig(writeConfig(t, config), true)
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Errorf("expected the config to be valid, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("expected error containing %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+// ptr returns a pointer to the value.
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,104 @@
+package events
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// subscriberBufferSize is the number of events buffered for a subscriber,
+// events are dropped for subscribers which do not keep up.
+const subscriberBufferSize = 64
+
+// Broker dispatches the published session events to their subscribers. It
+// also keeps a registry of the running sessions.
+type Broker struct {
+	mu          sync.Mutex
+	sessions    map[string]SessionInfo
+	subscribers map[chan Event]string
+}
+
+// SessionInfo holds the information about a running session.
+type SessionInfo struct {
+	ID        string    `json:"id"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// NewBroker creates a new Broker instance.
+func NewBroker() *Broker {
+	b := &Broker{
+		sessions:    make(map[string]SessionInfo),
+		subscribers: make(map[chan Event]string),
+	}
+
+	return b
+}
+
+// Publish sends the event to the subscribers of its session. Publishing to a
+// nil broker is a no-op so that sessions do not need to check whether events
+// are enabled.
+func (b *Broker) Publish(e Event) {
+	if b == nil {
+		return
+	}
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch e.Type {
+	case TypeSessionStarted:
+		b.sessions[e.SessionID] = SessionInfo{ID: e.SessionID, StartedAt: e.Time}
+	case TypeSessionCompleted:
+		delete(b.sessions, e.SessionID)
+	}
+
+	for ch, sessionID := range b.subscribers {
+		if sessionID != "" && sessionID != e.SessionID {
+			continue
+		}
+
+		select {
+		case ch <- e:
+		default:
+			slog.Debug("Dropping session event for slow subscriber", "session.id", e.SessionID, "type", e.Type)
+		}
+	}
+}
+
+// Subscribe returns a channel receiving the events of the given session, or
+// of all sessions if the session ID is empty. The returned function must be
+// called to unsubscribe.
+func (b *Broker) Subscribe(sessionID string) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBufferSize)
+
+	b.mu.Lock()
+	b.subscribers[ch] = sessionID
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		delete(b.subscribers, ch)
+		b.mu.Unlock()
+	}
+
+	return ch, unsubscribe
+}
+
+// Sessions returns the running sessions, sorted by start time.
+func (b *Broker) Sessions() []SessionInfo {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sessions := slices.Collect(maps.Values(b.sessions))
+	slices.SortFunc(sessions, func(a, b SessionInfo) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+
+	return sessions
+}
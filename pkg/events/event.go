@@ -0,0 +1,28 @@
+// Package events provides the publication of session events, e.g. to stream
+// the progress of investigations to a live dashboard.
+package events
+
+import (
+	"time"
+)
+
+// Type is the type of a session event.
+type Type string
+
+// Session event types.
+const (
+	TypeSessionStarted   Type = "session_started"
+	TypeTurnStarted      Type = "turn_started"
+	TypeLLMContent       Type = "llm_content"
+	TypeToolCall         Type = "tool_call"
+	TypeToolResult       Type = "tool_result"
+	TypeSessionCompleted Type = "session_completed"
+)
+
+// Event is an event occurring during a session.
+type Event struct {
+	SessionID string         `json:"session_id"`
+	Type      Type           `json:"type"`
+	Time      time.Time      `json:"time"`
+	Data      map[string]any `json:"data,omitempty"`
+}
@@ -0,0 +1,109 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// shutdownTimeout is the time given to the server to shut down gracefully.
+const shutdownTimeout = 5 * time.Second
+
+// Handler returns the HTTP handler serving the broker's events. It serves:
+//   - GET /sessions: the running sessions as JSON.
+//   - GET /events: a Server-Sent Events stream of the sessions' events,
+//     restricted to a single session with the "session" query parameter.
+func Handler(b *Broker) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /sessions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(w).Encode(b.Sessions())
+		if err != nil {
+			slog.Warn("Failed to write sessions", "error", err)
+		}
+	})
+
+	mux.HandleFunc("GET /events", func(w http.ResponseWriter, r *http.Request) {
+		streamEvents(w, r, b)
+	})
+
+	return mux
+}
+
+// streamEvents streams the events of the requested sessions until the client
+// disconnects.
+func streamEvents(w http.ResponseWriter, r *http.Request, b *Broker) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	events, unsubscribe := b.Subscribe(r.URL.Query().Get("session"))
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e := <-events:
+			data, err := json.Marshal(e)
+			if err != nil {
+				slog.Warn("Failed to marshal session event", "error", err, "session.id", e.SessionID)
+				continue
+			}
+
+			_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// Serve serves the broker's events on the given address until the context is
+// canceled.
+func Serve(ctx context.Context, address string, b *Broker) error {
+	server := &http.Server{
+		Addr:              address,
+		Handler:           Handler(b),
+		ReadHeaderTimeout: 10 * time.Second,
+		// Streams are bound to the context so that they end on shutdown.
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		err := server.Shutdown(shutdownCtx)
+		if err != nil {
+			slog.Warn("Failed to shut down events server", "error", err)
+		}
+	}()
+
+	slog.Info("Events server started", "address", address)
+	defer slog.Info("Events server stopped")
+
+	err := server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve events: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+// Package health provides the liveness and readiness endpoints of OKA, the
+// readiness being reported by the services as they initialize.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// shutdownTimeout is the time given to the server to shut down gracefully.
+const shutdownTimeout = 5 * time.Second
+
+// Check is a condition for OKA to be ready.
+type Check string
+
+// Readiness checks, all of them must be ready for OKA to be ready.
+const (
+	// CheckLLM is ready once the LLM models are built.
+	CheckLLM Check = "llm"
+	// CheckMCP is ready once the shared MCP clients are initialized, and while
+	// the monitored MCP servers answer or are reconnected.
+	CheckMCP Check = "mcp"
+	// CheckOpsGenie is ready while OpsGenie is reachable, i.e. the last poll
+	// succeeded, or once the webhook server listens in webhook mode.
+	CheckOpsGenie Check = "opsgenie"
+)
+
+// checks are the readiness checks, in the order they are reported.
+var checks = []Check{CheckLLM, CheckMCP, CheckOpsGenie}
+
+// ready holds the state of the readiness checks.
+var ready = struct {
+	sync.Mutex
+	checks map[Check]bool
+}{checks: make(map[Check]bool)}
+
+// SetReady sets the state of a readiness check.
+func SetReady(check Check, isReady bool) {
+	ready.Lock()
+	defer ready.Unlock()
+
+	ready.checks[check] = isReady
+}
+
+// notReady returns the readiness checks which are not ready.
+func notReady() []string {
+	ready.Lock()
+	defer ready.Unlock()
+
+	var failing []string
+	for _, check := range checks {
+		if !ready.checks[check] {
+			failing = append(failing, string(check))
+		}
+	}
+
+	return failing
+}
+
+// Handler returns the HTTP handler serving:
+//   - GET /healthz: 200 while the process is alive.
+//   - GET /readyz: 200 once all the readiness checks are ready, 503 with the
+//     failing checks otherwise.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		failing := notReady()
+		if len(failing) > 0 {
+			http.Error(w, "not ready: "+strings.Join(failing, ", "), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+
+	return mux
+}
+
+// Serve serves the health endpoints on the given address until the context is
+// canceled.
+func Serve(ctx context.Context, address string) error {
+	server := &http.Server{
+		Addr:              address,
+		Handler:           Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		err := server.Shutdown(shutdownCtx)
+		if err != nil {
+			slog.Warn("Failed to shut down health server", "error", err)
+		}
+	}()
+
+	slog.Info("Health server started", "address", address)
+	defer slog.Info("Health server stopped")
+
+	err := server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve health endpoints: %w", err)
+	}
+
+	return nil
+}
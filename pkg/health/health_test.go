@@ -0,0 +1,96 @@
+package health
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// get returns the status code and body of the response to a GET request.
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("failed to get %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+
+	return resp.StatusCode, strings.TrimSpace(string(body))
+}
+
+func TestReadiness(t *testing.T) {
+	t.Cleanup(func() {
+		for _, check := range checks {
+			SetReady(check, false)
+		}
+	})
+
+	server := httptest.NewServer(Handler())
+	t.Cleanup(server.Close)
+
+	steps := []struct {
+		name           string
+		check          Check
+		ready          bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "llm built",
+			check:          CheckLLM,
+			ready:          true,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "not ready: mcp, opsgenie",
+		},
+		{
+			name:           "mcp initialized",
+			check:          CheckMCP,
+			ready:          true,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "not ready: opsgenie",
+		},
+		{
+			name:           "opsgenie reachable",
+			check:          CheckOpsGenie,
+			ready:          true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "ok",
+		},
+		{
+			name:           "opsgenie unreachable",
+			check:          CheckOpsGenie,
+			ready:          false,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "not ready: opsgenie",
+		},
+	}
+
+	// Nothing is ready before the services initialize.
+	status, body := get(t, server.URL+"/readyz")
+	if status != http.StatusServiceUnavailable || body != "not ready: llm, mcp, opsgenie" {
+		t.Errorf("expected status %d with every check failing, got %d %q", http.StatusServiceUnavailable, status, body)
+	}
+
+	for _, step := range steps {
+		SetReady(step.check, step.ready)
+
+		status, body := get(t, server.URL+"/readyz")
+		if status != step.expectedStatus || body != step.expectedBody {
+			t.Errorf("%s: expected status %d %q, got %d %q", step.name, step.expectedStatus, step.expectedBody, status, body)
+		}
+
+		// The process is alive whatever its readiness.
+		status, _ = get(t, server.URL+"/healthz")
+		if status != http.StatusOK {
+			t.Errorf("%s: expected status %d for healthz, got %d", step.name, http.StatusOK, status)
+		}
+	}
+}
@@ -1,26 +1,37 @@
 package kubernetes
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
-)
+	"slices"
+	"strings"
 
-// kubeConfigPath is the path to the default kubeconfig file.
-var (
-	kubeConfigPath = os.ExpandEnv("$HOME/.kube/config")
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
-// CreateTmpKubeConfigFile creates a temporary kubeconfig file by copying the
-// default kubeconfig. This is useful for isolating the kubeconfig used by the
+// inClusterName is the name of the cluster, user and context of the
+// kubeconfig generated from the in-cluster service account.
+const inClusterName = "in-cluster"
+
+// CreateTmpKubeConfigFile creates a temporary kubeconfig file holding the
+// resolved kubeconfig. This is useful for isolating the kubeconfig used by the
 // application from the user's default kubeconfig.
 //
-// TODO: Use the Kubernetes client-go library to get the kubeconfig instead of
-// reading from a file.
-func CreateTmpKubeConfigFile() (string, error) {
-	// TODO: use kubernetes client-go to get the kubeconfig instead of reading from a file.
-	kubeConfig, err := os.ReadFile(kubeConfigPath)
+// The kubeconfig is loaded from the files listed in KUBECONFIG, or
+// $HOME/.kube/config, falling back to the in-cluster service account when
+// none is found. If an installation is given, only its contexts are kept.
+func CreateTmpKubeConfigFile(installation string) (string, error) {
+	kubeConfig, err := loadKubeConfig()
 	if err != nil {
-		return "", fmt.Errorf("failed to read kubeconfig file: %w", err)
+		return "", err
+	}
+
+	if installation != "" {
+		kubeConfig = filterContexts(kubeConfig, installation)
 	}
 
 	// Create a temporary file to store the kubeconfig
@@ -28,15 +39,106 @@ func CreateTmpKubeConfigFile() (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to create temporary kubeconfig file: %w", err)
 	}
+	tmpFile.Close()
 
 	// Write the kubeconfig content to the temporary file
-	if _, err := tmpFile.WriteString(string(kubeConfig)); err != nil {
-		tmpFile.Close()
+	err = clientcmd.WriteToFile(*kubeConfig, tmpFile.Name())
+	if err != nil {
 		os.Remove(tmpFile.Name())
 		return "", fmt.Errorf("failed to write kubeconfig to temporary file: %w", err)
 	}
 
-	// Close the file and return its name
-	tmpFile.Close()
 	return tmpFile.Name(), nil
 }
+
+// loadKubeConfig returns the kubeconfig merged from the KUBECONFIG files, or
+// the default file, or generated from the in-cluster service account.
+func loadKubeConfig() (*clientcmdapi.Config, error) {
+	// The relative paths of the files are resolved, so that the kubeconfig
+	// can be written elsewhere.
+	kubeConfig, err := clientcmd.NewDefaultClientConfigLoadingRules().Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	if len(kubeConfig.Contexts) > 0 {
+		return kubeConfig, nil
+	}
+
+	restConfig, err := rest.InClusterConfig()
+	if errors.Is(err, rest.ErrNotInCluster) {
+		return nil, errors.New("no kubeconfig found and not running in a cluster")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load in-cluster config: %w", err)
+	}
+
+	// The token file is referenced rather than its content, as the service
+	// account tokens are rotated.
+	kubeConfig = clientcmdapi.NewConfig()
+	kubeConfig.Clusters[inClusterName] = &clientcmdapi.Cluster{
+		Server:               restConfig.Host,
+		CertificateAuthority: restConfig.TLSClientConfig.CAFile,
+	}
+	kubeConfig.AuthInfos[inClusterName] = &clientcmdapi.AuthInfo{
+		TokenFile: restConfig.BearerTokenFile,
+	}
+	kubeConfig.Contexts[inClusterName] = &clientcmdapi.Context{
+		Cluster:  inClusterName,
+		AuthInfo: inClusterName,
+	}
+	kubeConfig.CurrentContext = inClusterName
+
+	return kubeConfig, nil
+}
+
+// filterContexts returns the kubeconfig restricted to the contexts of the
+// installation, along with their clusters and users. A context belongs to the
+// installation if its name, split on "-", ".", "_", ":", "/" and "@", contains
+// the installation, e.g. "gs-gazelle" or "teleport.giantswarm.io-gazelle-wc1"
+// for the gazelle installation. The kubeconfig is returned unchanged if no
+// context belongs to the installation.
+func filterContexts(kubeConfig *clientcmdapi.Config, installation string) *clientcmdapi.Config {
+	filtered := clientcmdapi.NewConfig()
+	filtered.Preferences = kubeConfig.Preferences
+	for name, context := range kubeConfig.Contexts {
+		if !isInstallationContext(name, installation) {
+			continue
+		}
+
+		filtered.Contexts[name] = context
+		if cluster, ok := kubeConfig.Clusters[context.Cluster]; ok {
+			filtered.Clusters[context.Cluster] = cluster
+		}
+		if authInfo, ok := kubeConfig.AuthInfos[context.AuthInfo]; ok {
+			filtered.AuthInfos[context.AuthInfo] = authInfo
+		}
+	}
+
+	if len(filtered.Contexts) == 0 {
+		slog.Warn("No kube context found for the installation, keeping all of them", "installation", installation)
+		return kubeConfig
+	}
+
+	// Keep the current context if it belongs to the installation, otherwise
+	// use the first one.
+	filtered.CurrentContext = kubeConfig.CurrentContext
+	if _, ok := filtered.Contexts[filtered.CurrentContext]; !ok {
+		names := make([]string, 0, len(filtered.Contexts))
+		for name := range filtered.Contexts {
+			names = append(names, name)
+		}
+		filtered.CurrentContext = slices.Min(names)
+	}
+
+	return filtered
+}
+
+// isInstallationContext returns whether the context name refers to the
+// installation.
+func isInstallationContext(name, installation string) bool {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return strings.ContainsRune("-._:/@", r)
+	})
+
+	return name == installation || slices.Contains(parts, installation)
+}
@@ -0,0 +1,216 @@
+package kubernetes
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// testKubeConfig is a kubeconfig with a single context, whose certificate
+// authority is relative to the kubeconfig file.
+const testKubeConfig = `apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+- name: test
+  cluster:
+    server: https://test.example.com
+    certificate-authority: ca.crt
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+users:
+- name: test
+  user:
+    token: token
+`
+
+// writeKubeConfig writes the kubeconfig to a temporary directory and returns
+// its path.
+func writeKubeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	err := os.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+
+	return path
+}
+
+func TestCreateTmpKubeConfigFile(t *testing.T) {
+	path := writeKubeConfig(t, testKubeConfig)
+	t.Setenv("KUBECONFIG", path)
+
+	tmpFile, err := CreateTmpKubeConfigFile("")
+	if err != nil {
+		t.Fatalf("failed to create kubeconfig: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Remove(tmpFile) })
+
+	kubeConfig, err := clientcmd.LoadFromFile(tmpFile)
+	if err != nil {
+		t.Fatalf("failed to load created kubeconfig: %v", err)
+	}
+
+	if kubeConfig.CurrentContext != "test" {
+		t.Errorf("expected current context test, got %s", kubeConfig.CurrentContext)
+	}
+
+	cluster, ok := kubeConfig.Clusters["test"]
+	if !ok {
+		t.Fatalf("expected cluster test, got %v", kubeConfig.Clusters)
+	}
+	if cluster.Server != "https://test.example.com" {
+		t.Errorf("expected server https://test.example.com, got %s", cluster.Server)
+	}
+
+	// The relative paths are resolved against the loaded kubeconfig.
+	expectedCA := filepath.Join(filepath.Dir(path), "ca.crt")
+	if cluster.CertificateAuthority != expectedCA {
+		t.Errorf("expected certificate authority %s, got %s", expectedCA, cluster.CertificateAuthority)
+	}
+}
+
+func TestCreateTmpKubeConfigFileMerged(t *testing.T) {
+	other := strings.ReplaceAll(testKubeConfig, "test", "other")
+	t.Setenv("KUBECONFIG", writeKubeConfig(t, testKubeConfig)+string(os.PathListSeparator)+writeKubeConfig(t, other))
+
+	tmpFile, err := CreateTmpKubeConfigFile("")
+	if err != nil {
+		t.Fatalf("failed to create kubeconfig: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Remove(tmpFile) })
+
+	kubeConfig, err := clientcmd.LoadFromFile(tmpFile)
+	if err != nil {
+		t.Fatalf("failed to load created kubeconfig: %v", err)
+	}
+
+	// The current context is taken from the first file.
+	if kubeConfig.CurrentContext != "test" {
+		t.Errorf("expected current context test, got %s", kubeConfig.CurrentContext)
+	}
+	for _, name := range []string{"test", "other"} {
+		if _, ok := kubeConfig.Contexts[name]; !ok {
+			t.Errorf("expected context %s to be merged, got %v", name, kubeConfig.Contexts)
+		}
+	}
+}
+
+func TestCreateTmpKubeConfigFileNotFound(t *testing.T) {
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "")
+
+	_, err := CreateTmpKubeConfigFile("")
+	if err == nil || !strings.Contains(err.Error(), "no kubeconfig found") {
+		t.Errorf("expected error containing %q, got %v", "no kubeconfig found", err)
+	}
+}
+
+// newContextsKubeConfig returns a kubeconfig with a context, cluster and user
+// for each of the names, the current context being the first one.
+func newContextsKubeConfig(names ...string) *clientcmdapi.Config {
+	kubeConfig := clientcmdapi.NewConfig()
+	for _, name := range names {
+		kubeConfig.Clusters[name] = &clientcmdapi.Cluster{Server: "https://" + name}
+		kubeConfig.AuthInfos[name] = &clientcmdapi.AuthInfo{Token: name}
+		kubeConfig.Contexts[name] = &clientcmdapi.Context{Cluster: name, AuthInfo: name}
+	}
+	kubeConfig.CurrentContext = names[0]
+
+	return kubeConfig
+}
+
+func TestFilterContexts(t *testing.T) {
+	contexts := []string{"gs-gazelle", "teleport.giantswarm.io-gazelle-wc1", "gs-gazellex", "gs-golem", "golem-gazelle_old"}
+
+	testCases := []struct {
+		name                   string
+		currentContext         string
+		installation           string
+		expectedContexts       []string
+		expectedCurrentContext string
+	}{
+		{
+			name:                   "installation contexts",
+			currentContext:         "gs-gazelle",
+			installation:           "gazelle",
+			expectedContexts:       []string{"golem-gazelle_old", "gs-gazelle", "teleport.giantswarm.io-gazelle-wc1"},
+			expectedCurrentContext: "gs-gazelle",
+		},
+		{
+			name:                   "current context of another installation",
+			currentContext:         "gs-golem",
+			installation:           "gazelle",
+			expectedContexts:       []string{"golem-gazelle_old", "gs-gazelle", "teleport.giantswarm.io-gazelle-wc1"},
+			expectedCurrentContext: "golem-gazelle_old",
+		},
+		{
+			name:                   "single context",
+			currentContext:         "gs-gazelle",
+			installation:           "gazellex",
+			expectedContexts:       []string{"gs-gazellex"},
+			expectedCurrentContext: "gs-gazellex",
+		},
+		{
+			name:                   "unknown installation",
+			currentContext:         "gs-golem",
+			installation:           "unknown",
+			expectedContexts:       []string{"golem-gazelle_old", "gs-gazelle", "gs-gazellex", "gs-golem", "teleport.giantswarm.io-gazelle-wc1"},
+			expectedCurrentContext: "gs-golem",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			kubeConfig := newContextsKubeConfig(contexts...)
+			kubeConfig.CurrentContext = tc.currentContext
+
+			filtered := filterContexts(kubeConfig, tc.installation)
+
+			names := slices.Sorted(maps.Keys(filtered.Contexts))
+			if !slices.Equal(names, tc.expectedContexts) {
+				t.Errorf("expected contexts %q, got %q", tc.expectedContexts, names)
+			}
+			if filtered.CurrentContext != tc.expectedCurrentContext {
+				t.Errorf("expected current context %s, got %s", tc.expectedCurrentContext, filtered.CurrentContext)
+			}
+
+			// The clusters and users of the kept contexts are kept, only them.
+			for _, resources := range []map[string]bool{
+				mapKeys(filtered.Clusters),
+				mapKeys(filtered.AuthInfos),
+			} {
+				if len(resources) != len(tc.expectedContexts) {
+					t.Errorf("expected %d clusters and users, got %d", len(tc.expectedContexts), len(resources))
+				}
+				for _, name := range tc.expectedContexts {
+					if !resources[name] {
+						t.Errorf("expected the cluster and user of context %s to be kept", name)
+					}
+				}
+			}
+		})
+	}
+}
+
+// mapKeys returns the set of keys of the map.
+func mapKeys[V any](m map[string]V) map[string]bool {
+	keys := make(map[string]bool, len(m))
+	for key := range m {
+		keys[key] = true
+	}
+
+	return keys
+}
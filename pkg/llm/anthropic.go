@@ -9,8 +9,9 @@ func newAnthropicFactory() LLMFactory {
 	return &genericFactory[anthropic.Option, *anthropic.LLM]{
 		newFunc: anthropic.New,
 		optsFunc: genericFactoryOptions[anthropic.Option]{
-			Token: anthropic.WithToken,
-			Model: anthropic.WithModel,
+			BaseURL: anthropic.WithBaseURL,
+			Token:   anthropic.WithToken,
+			Model:   anthropic.WithModel,
 		},
 	}
 }
@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/bedrock"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+// bedrockFactory is an LLMFactory for the Amazon Bedrock provider. Unlike the
+// other providers, Bedrock authenticates with the AWS credentials found in the
+// environment (e.g. an IAM role) rather than an API token, in the configured
+// region.
+type bedrockFactory struct{}
+
+// newBedrockFactory returns a new LLMFactory for the Amazon Bedrock provider.
+func newBedrockFactory() LLMFactory {
+	return &bedrockFactory{}
+}
+
+// Build creates a new Bedrock LLM model using the provided configuration.
+func (f *bedrockFactory) Build(llmConfig config.LLM) (llms.Model, error) {
+	opts, err := f.buildOpts(llmConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	model, err := bedrock.New(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LLM model: %w", err)
+	}
+
+	return model, nil
+}
+
+// buildOpts constructs the list of options for the Bedrock client, loading
+// the AWS configuration for the configured region.
+func (f *bedrockFactory) buildOpts(llmConfig config.LLM) ([]bedrock.Option, error) {
+	awsConfig, err := loadAWSConfig(llmConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []bedrock.Option{bedrock.WithClient(bedrockruntime.NewFromConfig(awsConfig))}
+	if llmConfig.Model != "" {
+		opts = append(opts, bedrock.WithModel(llmConfig.Model))
+	}
+
+	return opts, nil
+}
+
+// loadAWSConfig loads the AWS configuration from the environment, in the
+// configured region if any.
+func loadAWSConfig(llmConfig config.LLM) (aws.Config, error) {
+	var awsOpts []func(*awsconfig.LoadOptions) error
+	if llmConfig.Region != "" {
+		awsOpts = append(awsOpts, awsconfig.WithRegion(llmConfig.Region))
+	}
+
+	awsConfig, err := awsconfig.LoadDefaultConfig(context.Background(), awsOpts...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return awsConfig, nil
+}
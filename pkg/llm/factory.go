@@ -15,19 +15,23 @@ type LLMFactory interface {
 	Build(llmConfig config.LLM) (llms.Model, error)
 }
 
-// NewFactory returns a new LLMFactory based on the provider specified in the
-// configuration. It supports "anthropic", "google", and "openai" providers.
-func NewFactory(conf *config.Config) (LLMFactory, error) {
-	switch conf.LLM.Provider {
+// NewFactory returns a new LLMFactory for the given provider. It supports
+// "anthropic", "bedrock", "google", "ollama" and "openai" providers.
+func NewFactory(provider string) (LLMFactory, error) {
+	switch provider {
 	case "anthropic":
 		return newAnthropicFactory(), nil
+	case "bedrock":
+		return newBedrockFactory(), nil
 	case "google":
 		return newGoogleFactory(), nil
+	case "ollama":
+		return newOllamaFactory(), nil
 	case "openai":
 		return newOpenAIFactory(), nil
 	}
 
-	return nil, fmt.Errorf("unknown LLM provider: %s", conf.LLM.Provider)
+	return nil, fmt.Errorf("unknown LLM provider: %s", provider)
 }
 
 // genericFactory is a generic implementation of the LLMFactory interface.
@@ -39,10 +43,12 @@ type genericFactory[O any, M llms.Model] struct {
 }
 
 // genericFactoryOptions holds the functions for creating provider-specific
-// options, such as setting the API token or model name.
+// options, such as setting the API token or model name. Options a provider
+// does not support are left nil.
 type genericFactoryOptions[O any] struct {
-	Token func(string) O
-	Model func(string) O
+	BaseURL func(string) O
+	Token   func(string) O
+	Model   func(string) O
 }
 
 // Build creates a new LLM model using the provided configuration.
@@ -63,15 +69,17 @@ func (f *genericFactory[O, M]) Build(llmConfig config.LLM) (llms.Model, error) {
 func (f *genericFactory[O, M]) buildOpts(llmConfig config.LLM) []O {
 	var opts []O
 
+	f.buildOpt(&opts, llmConfig.BaseURL, f.optsFunc.BaseURL)
 	f.buildOpt(&opts, llmConfig.Token, f.optsFunc.Token)
 	f.buildOpt(&opts, llmConfig.Model, f.optsFunc.Model)
 
 	return opts
 }
 
-// buildOpt adds an option to the list if the value is not empty.
+// buildOpt adds an option to the list if the value is not empty and the
+// provider supports the option.
 func (f *genericFactory[O, M]) buildOpt(opts *[]O, value string, o func(string) O) {
-	if value != "" && f != nil {
+	if value != "" && o != nil && f != nil {
 		*opts = append(*opts, o(value))
 	}
 }
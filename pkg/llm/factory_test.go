@@ -0,0 +1,157 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/tmc/langchaingo/llms/anthropic"
+	"github.com/tmc/langchaingo/llms/bedrock"
+	"github.com/tmc/langchaingo/llms/openai"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+// setAWSEnv sets static AWS credentials and region in the environment, so
+// that the AWS configuration loads without reaching AWS.
+func setAWSEnv(t *testing.T, region string) {
+	t.Helper()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_REGION", region)
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+}
+
+func TestNewFactory(t *testing.T) {
+	testCases := []struct {
+		provider    string
+		expectedErr bool
+	}{
+		{provider: "anthropic"},
+		{provider: "bedrock"},
+		{provider: "google"},
+		{provider: "ollama"},
+		{provider: "openai"},
+		{provider: "unknown", expectedErr: true},
+		{provider: "", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.provider, func(t *testing.T) {
+			factory, err := NewFactory(tc.provider)
+			if tc.expectedErr {
+				if err == nil {
+					t.Errorf("expected an error for provider %q, got factory %T", tc.provider, factory)
+				}
+				return
+			}
+
+			if err != nil || factory == nil {
+				t.Errorf("expected a factory for provider %q, got %v", tc.provider, err)
+			}
+		})
+	}
+}
+
+func TestFactoryBuild(t *testing.T) {
+	setAWSEnv(t, "us-east-1")
+
+	testCases := []struct {
+		name      string
+		llmConfig config.LLM
+		check     func(t *testing.T, model any)
+	}{
+		{
+			name:      "anthropic",
+			llmConfig: config.LLM{Provider: "anthropic", Model: "claude", Token: "token"},
+			check: func(t *testing.T, model any) {
+				if _, ok := model.(*anthropic.LLM); !ok {
+					t.Errorf("expected an Anthropic model, got %T", model)
+				}
+			},
+		},
+		{
+			name:      "bedrock",
+			llmConfig: config.LLM{Provider: "bedrock", Model: "anthropic.claude", Region: "eu-west-1"},
+			check: func(t *testing.T, model any) {
+				if _, ok := model.(*bedrock.LLM); !ok {
+					t.Errorf("expected a Bedrock model, got %T", model)
+				}
+			},
+		},
+		{
+			name:      "openai",
+			llmConfig: config.LLM{Provider: "openai", Model: "gpt", Token: "token"},
+			check: func(t *testing.T, model any) {
+				if _, ok := model.(*openai.LLM); !ok {
+					t.Errorf("expected an OpenAI model, got %T", model)
+				}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			factory, err := NewFactory(tc.llmConfig.Provider)
+			if err != nil {
+				t.Fatalf("failed to create factory: %v", err)
+			}
+
+			model, err := factory.Build(tc.llmConfig)
+			if err != nil {
+				t.Fatalf("failed to build model: %v", err)
+			}
+			tc.check(t, model)
+		})
+	}
+}
+
+func TestBedrockOpts(t *testing.T) {
+	testCases := []struct {
+		name           string
+		llmConfig      config.LLM
+		expectedOpts   int
+		expectedRegion string
+	}{
+		{
+			name:           "configured region",
+			llmConfig:      config.LLM{Model: "anthropic.claude", Region: "eu-west-1"},
+			expectedOpts:   2,
+			expectedRegion: "eu-west-1",
+		},
+		{
+			name:           "environment region",
+			llmConfig:      config.LLM{Model: "anthropic.claude"},
+			expectedOpts:   2,
+			expectedRegion: "us-east-1",
+		},
+		{
+			name:           "default model",
+			llmConfig:      config.LLM{},
+			expectedOpts:   1,
+			expectedRegion: "us-east-1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setAWSEnv(t, "us-east-1")
+
+			awsConfig, err := loadAWSConfig(tc.llmConfig)
+			if err != nil {
+				t.Fatalf("failed to load AWS config: %v", err)
+			}
+			if awsConfig.Region != tc.expectedRegion {
+				t.Errorf("expected region %s, got %s", tc.expectedRegion, awsConfig.Region)
+			}
+
+			opts, err := (&bedrockFactory{}).buildOpts(tc.llmConfig)
+			if err != nil {
+				t.Fatalf("failed to build options: %v", err)
+			}
+			if len(opts) != tc.expectedOpts {
+				t.Errorf("expected %d options, got %d", tc.expectedOpts, len(opts))
+			}
+		})
+	}
+}
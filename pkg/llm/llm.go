@@ -4,24 +4,43 @@
 package llm
 
 import (
+	"fmt"
+
 	"github.com/tmc/langchaingo/llms"
 
 	"github.com/giantswarm/oka/pkg/config"
 )
 
-// New creates a new llms.Model based on the provided configuration. It uses a
-// factory to build the appropriate LLM client (e.g., OpenAI, Anthropic) and
-// returns a generic `llms.Model` interface.
-func New(conf *config.Config) (llms.Model, error) {
-	factory, err := NewFactory(conf)
-	if err != nil {
-		return nil, err
-	}
+// Model is an LLM model along with the configuration it was built from.
+type Model struct {
+	llms.Model
+
+	Config config.LLM
+}
+
+// New creates the LLM models based on the provided configuration: the primary
+// model followed by its fallbacks, in order. It uses a factory to build the
+// appropriate LLM client (e.g., OpenAI, Anthropic) for each of them.
+func New(conf *config.Config) ([]Model, error) {
+	llmConfigs := append([]config.LLM{conf.LLM}, conf.LLM.Fallbacks...)
+
+	models := make([]Model, 0, len(llmConfigs))
+	for i, llmConfig := range llmConfigs {
+		factory, err := NewFactory(llmConfig.Provider)
+		if err != nil {
+			return nil, err
+		}
+
+		model, err := factory.Build(llmConfig)
+		if err != nil {
+			if i > 0 {
+				return nil, fmt.Errorf("failed to build LLM fallback %d: %w", i, err)
+			}
+			return nil, err
+		}
 
-	model, err := factory.Build(conf.LLM)
-	if err != nil {
-		return nil, err
+		models = append(models, Model{Model: model, Config: llmConfig})
 	}
 
-	return model, nil
+	return models, nil
 }
@@ -0,0 +1,39 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+func TestNewFallbacks(t *testing.T) {
+	conf := &config.Config{
+		LLM: config.LLM{
+			Provider: "openai",
+			Model:    "gpt",
+			Token:    "token",
+			Fallbacks: []config.LLM{
+				{Provider: "ollama", Model: "llama"},
+			},
+		},
+	}
+
+	models, err := New(conf)
+	if err != nil {
+		t.Fatalf("failed to create models: %v", err)
+	}
+
+	// The primary model comes first, followed by its fallbacks in order.
+	if len(models) != 2 {
+		t.Fatalf("expected 2 models, got %d", len(models))
+	}
+	if models[0].Config.Model != "gpt" || models[1].Config.Model != "llama" {
+		t.Errorf("expected the gpt model followed by the llama one, got %s and %s", models[0].Config.Model, models[1].Config.Model)
+	}
+
+	conf.LLM.Fallbacks = append(conf.LLM.Fallbacks, config.LLM{Provider: "unknown"})
+	_, err = New(conf)
+	if err == nil {
+		t.Error("expected an error for the unknown fallback provider")
+	}
+}
@@ -0,0 +1,38 @@
+package llm
+
+import "github.com/tmc/langchaingo/llms/openai"
+
+const (
+	// defaultOllamaBaseURL is the OpenAI-compatible endpoint of a local Ollama
+	// server.
+	defaultOllamaBaseURL = "http://localhost:11434/v1"
+
+	// ollamaToken is the token sent to Ollama, which ignores it but the OpenAI
+	// client requires one.
+	ollamaToken = "ollama"
+)
+
+// newOllamaFactory returns a new LLMFactory for the Ollama provider, or any
+// local OpenAI-compatible server. It builds an openai.LLM client defaulting to
+// the local Ollama endpoint and requiring no token.
+func newOllamaFactory() LLMFactory {
+	return &genericFactory[openai.Option, *openai.LLM]{
+		newFunc: ollamaNew,
+		optsFunc: genericFactoryOptions[openai.Option]{
+			BaseURL: openai.WithBaseURL,
+			Token:   openai.WithToken,
+			Model:   openai.WithModel,
+		},
+	}
+}
+
+// ollamaNew is a wrapper around the openai.New function setting the Ollama
+// defaults, which the configured options override.
+func ollamaNew(opts ...openai.Option) (*openai.LLM, error) {
+	defaults := []openai.Option{
+		openai.WithBaseURL(defaultOllamaBaseURL),
+		openai.WithToken(ollamaToken),
+	}
+
+	return openai.New(append(defaults, opts...)...)
+}
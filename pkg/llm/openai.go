@@ -9,8 +9,9 @@ func newOpenAIFactory() LLMFactory {
 	return &genericFactory[openai.Option, *openai.LLM]{
 		newFunc: openai.New,
 		optsFunc: genericFactoryOptions[openai.Option]{
-			Token: openai.WithToken,
-			Model: openai.WithModel,
+			BaseURL: openai.WithBaseURL,
+			Token:   openai.WithToken,
+			Model:   openai.WithModel,
 		},
 	}
 }
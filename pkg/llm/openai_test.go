@@ -0,0 +1,121 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/openai"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+// newStubOpenAI starts a stub OpenAI-compatible server answering every chat
+// completion, and returns its URL along with the channel receiving the
+// authorization headers of the requests.
+func newStubOpenAI(t *testing.T) (string, <-chan string) {
+	t.Helper()
+
+	authorizations := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected request path %s", r.URL.Path)
+		}
+		authorizations <- r.Header.Get("Authorization")
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":      "test",
+			"object":  "chat.completion",
+			"created": 0,
+			"model":   "local",
+			"choices": []map[string]any{
+				{"index": 0, "message": map[string]any{"role": "assistant", "content": "ok"}, "finish_reason": "stop"},
+			},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL + "/v1", authorizations
+}
+
+func TestBaseURLOpts(t *testing.T) {
+	testCases := []struct {
+		name         string
+		llmConfig    config.LLM
+		expectedOpts int
+	}{
+		{
+			name:         "base url",
+			llmConfig:    config.LLM{BaseURL: "http://localhost:11434/v1", Model: "llama"},
+			expectedOpts: 2,
+		},
+		{
+			name:         "no base url",
+			llmConfig:    config.LLM{Model: "gpt", Token: "token"},
+			expectedOpts: 2,
+		},
+		{
+			name:         "all options",
+			llmConfig:    config.LLM{BaseURL: "http://localhost:11434/v1", Model: "gpt", Token: "token"},
+			expectedOpts: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newOpenAIFactory().(*genericFactory[openai.Option, *openai.LLM])
+			if opts := f.buildOpts(tc.llmConfig); len(opts) != tc.expectedOpts {
+				t.Errorf("expected %d options, got %d", tc.expectedOpts, len(opts))
+			}
+		})
+	}
+}
+
+func TestBaseURL(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		provider              string
+		token                 string
+		expectedAuthorization string
+	}{
+		{
+			name:                  "openai",
+			provider:              "openai",
+			token:                 "token",
+			expectedAuthorization: "Bearer token",
+		},
+		{
+			name:                  "ollama without token",
+			provider:              "ollama",
+			expectedAuthorization: "Bearer " + ollamaToken,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			baseURL, authorizations := newStubOpenAI(t)
+
+			factory, err := NewFactory(tc.provider)
+			if err != nil {
+				t.Fatalf("failed to create factory: %v", err)
+			}
+			model, err := factory.Build(config.LLM{BaseURL: baseURL, Model: "local", Token: tc.token})
+			if err != nil {
+				t.Fatalf("failed to build model: %v", err)
+			}
+
+			// The model calls the configured endpoint.
+			_, err = llms.GenerateFromSinglePrompt(context.Background(), model, "ping")
+			if err != nil {
+				t.Fatalf("failed to call model: %v", err)
+			}
+			if authorization := <-authorizations; authorization != tc.expectedAuthorization {
+				t.Errorf("expected authorization %q, got %q", tc.expectedAuthorization, authorization)
+			}
+		})
+	}
+}
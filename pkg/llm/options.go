@@ -0,0 +1,140 @@
+package llm
+
+import (
+	"fmt"
+
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+// floatParams maps the generation parameters taking a float value to their
+// call option.
+var floatParams = map[string]func(float64) llms.CallOption{
+	"frequency_penalty":  llms.WithFrequencyPenalty,
+	"presence_penalty":   llms.WithPresencePenalty,
+	"repetition_penalty": llms.WithRepetitionPenalty,
+	"temperature":        llms.WithTemperature,
+	"top_p":              llms.WithTopP,
+}
+
+// intParams maps the generation parameters taking an integer value to their
+// call option.
+var intParams = map[string]func(int) llms.CallOption{
+	"max_length": llms.WithMaxLength,
+	"max_tokens": llms.WithMaxTokens,
+	"min_length": llms.WithMinLength,
+	"seed":       llms.WithSeed,
+	"top_k":      llms.WithTopK,
+}
+
+// CallOptions returns the call options to use when generating content with
+// the configured LLM. Generation parameters without a dedicated call option
+// are passed through as metadata to the provider.
+func CallOptions(llmConfig config.LLM) ([]llms.CallOption, error) {
+	var options []llms.CallOption
+
+	if llmConfig.MaxTokens != nil {
+		options = append(options, llms.WithMaxTokens(*llmConfig.MaxTokens))
+	}
+	if llmConfig.Seed != nil {
+		options = append(options, llms.WithSeed(*llmConfig.Seed))
+	}
+	if llmConfig.Temperature != nil {
+		options = append(options, llms.WithTemperature(*llmConfig.Temperature))
+	}
+	if llmConfig.TopP != nil {
+		options = append(options, llms.WithTopP(*llmConfig.TopP))
+	}
+
+	metadata := make(map[string]any)
+	for name, value := range llmConfig.Params {
+		if o, ok := floatParams[name]; ok {
+			f, err := toFloat(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid LLM parameter %s: %w", name, err)
+			}
+			options = append(options, o(f))
+			continue
+		}
+
+		if o, ok := intParams[name]; ok {
+			i, err := toInt(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid LLM parameter %s: %w", name, err)
+			}
+			options = append(options, o(i))
+			continue
+		}
+
+		if name == "stop_words" {
+			words, err := toStrings(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid LLM parameter %s: %w", name, err)
+			}
+			options = append(options, llms.WithStopWords(words))
+			continue
+		}
+
+		metadata[name] = value
+	}
+
+	if len(metadata) > 0 {
+		options = append(options, llms.WithMetadata(metadata))
+	}
+
+	return options, nil
+}
+
+// toFloat converts a configuration value to a float.
+func toFloat(value any) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	}
+
+	return 0, fmt.Errorf("expected a number, got %T", value)
+}
+
+// toInt converts a configuration value to an integer.
+func toInt(value any) (int, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		if v != float64(int(v)) {
+			return 0, fmt.Errorf("expected an integer, got %v", v)
+		}
+		return int(v), nil
+	}
+
+	return 0, fmt.Errorf("expected an integer, got %T", value)
+}
+
+// toStrings converts a configuration value to a slice of strings.
+func toStrings(value any) ([]string, error) {
+	switch v := value.(type) {
+	case []string:
+		return v, nil
+	case []any:
+		words := make([]string, 0, len(v))
+		for _, w := range v {
+			s, ok := w.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a list of strings, got %T", w)
+			}
+			words = append(words, s)
+		}
+		return words, nil
+	}
+
+	return nil, fmt.Errorf("expected a list of strings, got %T", value)
+}
@@ -0,0 +1,106 @@
+package llm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+// applyOptions returns the call options resulting from the options.
+func applyOptions(options []llms.CallOption) llms.CallOptions {
+	var o llms.CallOptions
+	for _, option := range options {
+		option(&o)
+	}
+
+	return o
+}
+
+func TestCallOptions(t *testing.T) {
+	zero := 0.0
+	topP := 0.9
+	maxTokens := 1024
+	seed := 42
+
+	testCases := []struct {
+		name            string
+		llmConfig       config.LLM
+		expectedCount   int
+		expectedOptions llms.CallOptions
+		expectedErr     bool
+	}{
+		{
+			name:          "unset",
+			llmConfig:     config.LLM{},
+			expectedCount: 0,
+		},
+		{
+			name:            "zero temperature",
+			llmConfig:       config.LLM{Temperature: &zero},
+			expectedCount:   1,
+			expectedOptions: llms.CallOptions{Temperature: 0},
+		},
+		{
+			name:            "all parameters",
+			llmConfig:       config.LLM{MaxTokens: &maxTokens, Seed: &seed, Temperature: &zero, TopP: &topP},
+			expectedCount:   4,
+			expectedOptions: llms.CallOptions{MaxTokens: 1024, Seed: 42, TopP: 0.9},
+		},
+		{
+			name: "params",
+			llmConfig: config.LLM{Params: map[string]any{
+				"top_k":        40,
+				"temperature":  0.2,
+				"stop_words":   []any{"STOP"},
+				"custom_param": "value",
+			}},
+			expectedCount: 4,
+			expectedOptions: llms.CallOptions{
+				TopK:        40,
+				Temperature: 0.2,
+				StopWords:   []string{"STOP"},
+				Metadata:    map[string]any{"custom_param": "value"},
+			},
+		},
+		{
+			name:        "invalid float param",
+			llmConfig:   config.LLM{Params: map[string]any{"temperature": "hot"}},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid int param",
+			llmConfig:   config.LLM{Params: map[string]any{"max_tokens": 1.5}},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid stop words",
+			llmConfig:   config.LLM{Params: map[string]any{"stop_words": []any{1}}},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options, err := CallOptions(tc.llmConfig)
+			if tc.expectedErr {
+				if err == nil {
+					t.Error("expected an error for the invalid parameter")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to build call options: %v", err)
+			}
+
+			if len(options) != tc.expectedCount {
+				t.Errorf("expected %d call options, got %d", tc.expectedCount, len(options))
+			}
+			if o := applyOptions(options); !reflect.DeepEqual(o, tc.expectedOptions) {
+				t.Errorf("expected call options %+v, got %+v", tc.expectedOptions, o)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// contextKey is the key of the logger carried by a context.
+type contextKey struct{}
+
+// NewContext returns a copy of the context carrying the given logger, e.g. a
+// child logger whose attributes correlate the records of a session.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by the context, or the default
+// logger if it carries none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+
+	return slog.Default()
+}
@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	if logger := FromContext(context.Background()); logger != slog.Default() {
+		t.Error("expected the default logger for a context without logger")
+	}
+	if logger := FromContext(NewContext(context.Background(), nil)); logger != slog.Default() {
+		t.Error("expected the default logger for a context with a nil logger")
+	}
+
+	var out bytes.Buffer
+	child := slog.New(slog.NewTextHandler(&out, nil)).With("session.id", "session-1")
+	ctx := NewContext(context.Background(), child)
+
+	FromContext(ctx).Info("Tool call", "tool", "echo")
+	if !strings.Contains(out.String(), "session.id=session-1 tool=echo") {
+		t.Errorf("expected the record to carry the attributes of the child logger, got %q", out.String())
+	}
+}
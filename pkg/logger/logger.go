@@ -8,6 +8,8 @@ import (
 	"maps"
 	"os"
 	"slices"
+
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // levels is a map of log level names to their corresponding slog.Level values.
@@ -18,6 +20,34 @@ var levels = map[string]slog.Level{
 	"error": slog.LevelError,
 }
 
+// Log formats supported by Setup.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Rotation configures the rotation of the log file, which is disabled if all
+// the fields are zero.
+type Rotation struct {
+	// MaxAgeDays is the number of days after which rotated files are removed,
+	// they are kept if 0.
+	MaxAgeDays int
+	// MaxBackups is the number of rotated files kept, all of them if 0.
+	MaxBackups int
+	// MaxSizeMB is the size in megabytes above which the file is rotated,
+	// 100 if 0.
+	MaxSizeMB int
+}
+
+// enabled returns whether the log file is rotated.
+func (r Rotation) enabled() bool {
+	return r != Rotation{}
+}
+
+// level is the level of the global logger, which can be changed at runtime
+// with SetLevel.
+var level slog.LevelVar
+
 // GetLevel returns the slog.Level for a given log level name.
 func GetLevel(name string) (slog.Level, error) {
 	if level, ok := levels[name]; ok {
@@ -32,12 +62,33 @@ func GetLevels() []string {
 	return slices.Collect(maps.Keys(levels))
 }
 
-// Setup initializes the global logger with the specified log level and output
-// file. If no log file is provided, it defaults to stderr. It returns a closer
-// function to be called on application shutdown.
-func Setup(logLevel, logFile string) (closer func(), err error) {
+// Setup initializes the global logger with the specified log level, output
+// file and format, "text" or "json". If no log file is provided, it defaults
+// to stderr, otherwise the file is rotated according to the given rotation.
+// It returns a closer function to be called on application shutdown.
+func Setup(logLevel, logFile, format string, rotation Rotation) (closer func(), err error) {
+	err = SetLevel(logLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	newHandler, err := handler(format)
+	if err != nil {
+		return nil, err
+	}
+
 	var logWriter io.Writer
-	if logFile != "" {
+	if logFile != "" && rotation.enabled() {
+		// Rotated files are named after the log file with a timestamp.
+		file := &lumberjack.Logger{
+			Filename:   logFile,
+			MaxAge:     rotation.MaxAgeDays,
+			MaxBackups: rotation.MaxBackups,
+			MaxSize:    rotation.MaxSizeMB,
+		}
+		closer = func() { file.Close() } // nolint:errcheck
+		logWriter = file
+	} else if logFile != "" {
 		// If a log file is specified, create/open it and use it for logging.
 		file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644) // nolint:gosec
 		if err != nil {
@@ -51,14 +102,9 @@ func Setup(logLevel, logFile string) (closer func(), err error) {
 		closer = func() {}
 	}
 
-	level, err := GetLevel(logLevel)
-	if err != nil {
-		return nil, err
-	}
-
 	// Set up the logger with a custom format and level.
-	logger := slog.New(slog.NewTextHandler(logWriter, &slog.HandlerOptions{
-		Level: level,
+	logger := slog.New(newHandler(logWriter, &slog.HandlerOptions{
+		Level: &level,
 	}))
 
 	// Set the global logger.
@@ -66,3 +112,31 @@ func Setup(logLevel, logFile string) (closer func(), err error) {
 
 	return closer, nil
 }
+
+// SetLevel changes the level of the global logger.
+func SetLevel(logLevel string) error {
+	l, err := GetLevel(logLevel)
+	if err != nil {
+		return err
+	}
+
+	level.Set(l)
+
+	return nil
+}
+
+// handler returns the constructor of the slog handler writing the format.
+func handler(format string) (func(io.Writer, *slog.HandlerOptions) slog.Handler, error) {
+	switch format {
+	case FormatText:
+		return func(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+			return slog.NewTextHandler(w, opts)
+		}, nil
+	case FormatJSON:
+		return func(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+			return slog.NewJSONHandler(w, opts)
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown log format: %s", format)
+}
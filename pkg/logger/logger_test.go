@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// restoreDefault restores the global logger once the test completes.
+func restoreDefault(t *testing.T) {
+	t.Helper()
+
+	previous := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(previous) })
+}
+
+func TestSetupFormat(t *testing.T) {
+	testCases := []struct {
+		name            string
+		format          string
+		expectedHandler slog.Handler
+		expectedErr     string
+	}{
+		{
+			name:            "text",
+			format:          FormatText,
+			expectedHandler: &slog.TextHandler{},
+		},
+		{
+			name:            "json",
+			format:          FormatJSON,
+			expectedHandler: &slog.JSONHandler{},
+		},
+		{
+			name:        "invalid",
+			format:      "xml",
+			expectedErr: "unknown log format: xml",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			restoreDefault(t)
+
+			closer, err := Setup("info", filepath.Join(t.TempDir(), "oka.log"), tc.format, Rotation{})
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Errorf("expected error containing %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to set up logger: %v", err)
+			}
+			t.Cleanup(closer)
+
+			handler := slog.Default().Handler()
+			if reflect.TypeOf(handler) != reflect.TypeOf(tc.expectedHandler) {
+				t.Errorf("expected handler %T, got %T", tc.expectedHandler, handler)
+			}
+		})
+	}
+}
+
+func TestSetupRotation(t *testing.T) {
+	restoreDefault(t)
+
+	dir := t.TempDir()
+	closer, err := Setup("info", filepath.Join(dir, "oka.log"), FormatText, Rotation{MaxSizeMB: 1})
+	if err != nil {
+		t.Fatalf("failed to set up logger: %v", err)
+	}
+	t.Cleanup(closer)
+
+	// Write past the size threshold of 1 MB.
+	message := strings.Repeat("x", 100*1024)
+	for range 15 {
+		slog.Info(message)
+	}
+
+	rotated, err := filepath.Glob(filepath.Join(dir, "oka-*.log"))
+	if err != nil {
+		t.Fatalf("failed to list rotated files: %v", err)
+	}
+	if len(rotated) != 1 {
+		t.Errorf("expected 1 rotated file, got %q", rotated)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "oka.log"))
+	if err != nil {
+		t.Fatalf("failed to stat log file: %v", err)
+	}
+	if info.Size() > 1024*1024 {
+		t.Errorf("expected the log file to be below the size threshold, got %d bytes", info.Size())
+	}
+}
+
+func TestSetupWithoutRotation(t *testing.T) {
+	restoreDefault(t)
+
+	dir := t.TempDir()
+	closer, err := Setup("info", filepath.Join(dir, "oka.log"), FormatText, Rotation{})
+	if err != nil {
+		t.Fatalf("failed to set up logger: %v", err)
+	}
+	t.Cleanup(closer)
+
+	message := strings.Repeat("x", 100*1024)
+	for range 15 {
+		slog.Info(message)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to list log directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the log file, got %d files", len(entries))
+	}
+}
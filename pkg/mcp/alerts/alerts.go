@@ -0,0 +1,234 @@
+// Package alerts provides an MCP server acting on the OpsGenie alert
+// investigated by a session. The server is bound to a single alert and is
+// meant to be registered in-process with the session's MCP clients, see
+// client.Clients.RegisterServer.
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/opsgenie"
+)
+
+const (
+	// CloseAlertToolName is the name of the tool used to close the alert.
+	CloseAlertToolName = "close_alert"
+	// AssignAlertToolName is the name of the tool used to assign the alert.
+	AssignAlertToolName = "assign_alert"
+	// EscalateAlertToolName is the name of the tool used to escalate the
+	// alert.
+	EscalateAlertToolName = "escalate_alert"
+	// SnoozeAlertToolName is the name of the tool used to snooze the alert.
+	SnoozeAlertToolName = "snooze_alert"
+	// AddTagsToolName is the name of the tool used to add tags to the alert.
+	AddTagsToolName = "add_tags"
+	// RemoveTagsToolName is the name of the tool used to remove tags from the
+	// alert.
+	RemoveTagsToolName = "remove_tags"
+)
+
+// Server wraps the core MCP server and provides alert-specific functionality.
+type Server struct {
+	*server.MCPServer
+
+	alertClient  *opsgenie.AlertClient
+	alertID      string
+	actionSource string
+	actionUser   string
+	maxSnooze    time.Duration
+}
+
+// NewServer creates a new MCP server with the alert tools registered, acting
+// on the alert with the given ID.
+func NewServer(name, version string, alertClient *opsgenie.AlertClient, alertID string, conf *config.Config) *Server {
+	mcpServer := server.NewMCPServer(
+		name,
+		version,
+		server.WithToolCapabilities(true),
+	)
+
+	s := &Server{
+		MCPServer:    mcpServer,
+		alertClient:  alertClient,
+		alertID:      alertID,
+		actionSource: conf.OpsGenie.ActionSource,
+		actionUser:   conf.OpsGenie.ActionUser,
+		maxSnooze:    conf.OpsGenie.MaxSnoozeDuration,
+	}
+
+	registerHandlers(s)
+
+	return s
+}
+
+// registerHandlers registers the tool handlers for the alerts server.
+func registerHandlers(s *Server) {
+	closeAlert := mcp.NewTool(CloseAlertToolName,
+		mcp.WithDescription("Close the alert under investigation, only once you verified that the underlying issue is resolved"),
+		mcp.WithString("note",
+			mcp.Description("Note explaining why the alert is closed"),
+			mcp.Required(),
+		),
+	)
+	s.AddTool(closeAlert, s.CloseAlert)
+
+	assignAlert := mcp.NewTool(AssignAlertToolName,
+		mcp.WithDescription("Assign the alert under investigation to a user, when it cannot be resolved automatically and should be handled by this user"),
+		mcp.WithString("owner_id",
+			mcp.Description("ID of the OpsGenie user to assign the alert to"),
+			mcp.Required(),
+		),
+	)
+	s.AddTool(assignAlert, s.AssignAlert)
+
+	escalateAlert := mcp.NewTool(EscalateAlertToolName,
+		mcp.WithDescription("Escalate the alert under investigation to the next level of an escalation, when it cannot be resolved automatically and requires human attention"),
+		mcp.WithString("escalation_id",
+			mcp.Description("ID of the OpsGenie escalation to escalate the alert with"),
+			mcp.Required(),
+		),
+	)
+	s.AddTool(escalateAlert, s.EscalateAlert)
+
+	snoozeAlert := mcp.NewTool(SnoozeAlertToolName,
+		mcp.WithDescription(fmt.Sprintf("Snooze the alert under investigation, instead of investigating it, when it is a known flapping or noisy alert. The alert is snoozed for at most %s", s.maxSnooze)),
+		mcp.WithString("duration",
+			mcp.Description("Duration to snooze the alert for, e.g. \"30m\" or \"2h\""),
+			mcp.Required(),
+		),
+		mcp.WithString("note",
+			mcp.Description("Note explaining why the alert is snoozed"),
+			mcp.Required(),
+		),
+	)
+	s.AddTool(snoozeAlert, s.SnoozeAlert)
+
+	addTags := mcp.NewTool(AddTagsToolName,
+		mcp.WithDescription(fmt.Sprintf("Add tags to the alert under investigation to persist its categorization, e.g. \"root-cause:node-pressure\". At most %d tags per call", maxTagsPerCall)),
+		mcp.WithArray("tags",
+			mcp.Description("Tags to add to the alert"),
+			mcp.Required(),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	)
+	s.AddTool(addTags, s.AddTags)
+
+	removeTags := mcp.NewTool(RemoveTagsToolName,
+		mcp.WithDescription(fmt.Sprintf("Remove tags from the alert under investigation. At most %d tags per call", maxTagsPerCall)),
+		mcp.WithArray("tags",
+			mcp.Description("Tags to remove from the alert"),
+			mcp.Required(),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	)
+	s.AddTool(removeTags, s.RemoveTags)
+}
+
+// CloseAlert is the tool implementation for closing the alert.
+func (s *Server) CloseAlert(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	note := request.GetString("note", "")
+	if note == "" {
+		return mcp.NewToolResultError("note parameter is required"), nil
+	}
+
+	_, err := s.alertClient.CloseAlert(ctx, s.alertID, s.actionUser, note, s.actionSource)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText("The alert is closed."), nil
+}
+
+// AssignAlert is the tool implementation for assigning the alert.
+func (s *Server) AssignAlert(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ownerID := request.GetString("owner_id", "")
+	if ownerID == "" {
+		return mcp.NewToolResultError("owner_id parameter is required"), nil
+	}
+
+	_, err := s.alertClient.AssignAlert(ctx, s.alertID, ownerID, s.actionUser, s.actionSource)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText("The alert is assigned."), nil
+}
+
+// EscalateAlert is the tool implementation for escalating the alert.
+func (s *Server) EscalateAlert(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	escalationID := request.GetString("escalation_id", "")
+	if escalationID == "" {
+		return mcp.NewToolResultError("escalation_id parameter is required"), nil
+	}
+
+	_, err := s.alertClient.EscalateAlert(ctx, s.alertID, escalationID, s.actionUser, s.actionSource)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText("The alert is escalated."), nil
+}
+
+// SnoozeAlert is the tool implementation for snoozing the alert.
+func (s *Server) SnoozeAlert(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	note := request.GetString("note", "")
+	if note == "" {
+		return mcp.NewToolResultError("note parameter is required"), nil
+	}
+
+	duration, err := time.ParseDuration(request.GetString("duration", ""))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid duration parameter: %s", err)), nil
+	}
+	if duration <= 0 {
+		return mcp.NewToolResultError("duration parameter must be positive, the alert can only be snoozed until a future time"), nil
+	}
+	if duration > s.maxSnooze {
+		return mcp.NewToolResultError(fmt.Sprintf("duration parameter must be at most %s", s.maxSnooze)), nil
+	}
+
+	until := time.Now().Add(duration)
+	_, err = s.alertClient.SnoozeAlert(ctx, s.alertID, until, s.actionUser, note, s.actionSource)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("The alert is snoozed until %s.", until.UTC().Format(time.RFC3339))), nil
+}
+
+// AddTags is the tool implementation for adding tags to the alert.
+func (s *Server) AddTags(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	tags, err := sanitizeTags(request.GetStringSlice("tags", nil))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	_, err = s.alertClient.AddTags(ctx, s.alertID, tags, s.actionUser, s.actionSource)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Added the tags %s to the alert.", strings.Join(tags, ", "))), nil
+}
+
+// RemoveTags is the tool implementation for removing tags from the alert.
+func (s *Server) RemoveTags(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	tags, err := sanitizeTags(request.GetStringSlice("tags", nil))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	_, err = s.alertClient.RemoveTags(ctx, s.alertID, tags, s.actionUser, s.actionSource)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Removed the tags %s from the alert.", strings.Join(tags, ", "))), nil
+}
@@ -0,0 +1,456 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/opsgenie"
+)
+
+// testAPIKeyEnvVar is the environment variable holding the API key of the
+// alert client of the test servers.
+const testAPIKeyEnvVar = "OKA_TEST_OPSGENIE_API_KEY"
+
+// alertRequest is a request received by the fake OpsGenie API.
+type alertRequest struct {
+	method string
+	path   string
+	query  string
+	body   string
+}
+
+// fakeAlertAPI is a fake OpsGenie API accepting the actions on alerts, or
+// rejecting them if failing is set, and recording the requests it receives.
+type fakeAlertAPI struct {
+	failing bool
+
+	mu       sync.Mutex
+	requests []alertRequest
+}
+
+// ServeHTTP records the request and responds like the OpsGenie API, the
+// actions being processed successfully.
+func (f *fakeAlertAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if strings.HasPrefix(r.URL.Path, "/v2/alerts/requests/") {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data":      map[string]any{"isSuccess": true, "status": "Processed"},
+			"requestId": "status",
+		})
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	f.mu.Lock()
+	f.requests = append(f.requests, alertRequest{method: r.Method, path: r.URL.Path, query: r.URL.RawQuery, body: string(body)})
+	f.mu.Unlock()
+
+	if f.failing {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_ = json.NewEncoder(w).Encode(map[string]any{"message": "Request rejected", "requestId": "test"})
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(map[string]any{"result": "Request will be processed", "requestId": "test"})
+}
+
+// actions returns the requests of actions on alerts received by the fake.
+func (f *fakeAlertAPI) actions() []alertRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]alertRequest(nil), f.requests...)
+}
+
+// newTestServer returns an alerts server acting on alert-1 through a fake
+// OpsGenie API, along with the fake.
+func newTestServer(t *testing.T, failing bool) (*Server, *fakeAlertAPI) {
+	t.Helper()
+	t.Setenv(testAPIKeyEnvVar, "test")
+
+	fake := &fakeAlertAPI{failing: failing}
+	httpServer := httptest.NewServer(fake)
+	t.Cleanup(httpServer.Close)
+
+	// The OpsGenie clients use plain HTTP for hosts which are not OpsGenie
+	// API hosts.
+	alertClient, err := opsgenie.NewAlertClient(strings.TrimPrefix(httpServer.URL, "http://"), testAPIKeyEnvVar, 0, time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to create alert client: %v", err)
+	}
+
+	conf := &config.Config{OpsGenie: &config.OpsGenie{
+		ActionSource:      "oka",
+		ActionUser:        "OKA",
+		MaxSnoozeDuration: 4 * time.Hour,
+	}}
+
+	return NewServer("alerts", "test", alertClient, "alert-1", conf), fake
+}
+
+// callTool calls the tool handler with the arguments and returns the text of
+// its result, along with whether it is an error.
+func callTool(t *testing.T, handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]any) (string, bool) {
+	t.Helper()
+
+	var request mcp.CallToolRequest
+	request.Params.Arguments = args
+
+	result, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("failed to call tool: %v", err)
+	}
+
+	var text strings.Builder
+	for _, content := range result.Content {
+		if c, ok := content.(mcp.TextContent); ok {
+			text.WriteString(c.Text)
+		}
+	}
+
+	return text.String(), result.IsError
+}
+
+func TestAlertActionTools(t *testing.T) {
+	testCases := []struct {
+		name            string
+		tool            func(*Server, context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)
+		args            map[string]any
+		failing         bool
+		expectedText    string
+		expectedIsError bool
+		expectedPath    string
+		expectedBody    string
+	}{
+		{
+			name:         "close",
+			tool:         (*Server).CloseAlert,
+			args:         map[string]any{"note": "Resolved by restarting the pod"},
+			expectedText: "The alert is closed.",
+			expectedPath: "/v2/alerts/alert-1/close",
+			expectedBody: `"note":"Resolved by restarting the pod"`,
+		},
+		{
+			name:            "close rejected",
+			tool:            (*Server).CloseAlert,
+			args:            map[string]any{"note": "Resolved by restarting the pod"},
+			failing:         true,
+			expectedText:    "failed to close alert with ID alert-1",
+			expectedIsError: true,
+			expectedPath:    "/v2/alerts/alert-1/close",
+			expectedBody:    `"note":"Resolved by restarting the pod"`,
+		},
+		{
+			name:            "close without note",
+			tool:            (*Server).CloseAlert,
+			args:            map[string]any{},
+			expectedText:    "note parameter is required",
+			expectedIsError: true,
+		},
+		{
+			name:         "assign",
+			tool:         (*Server).AssignAlert,
+			args:         map[string]any{"owner_id": "user-1"},
+			expectedText: "The alert is assigned.",
+			expectedPath: "/v2/alerts/alert-1/assign",
+			expectedBody: `"owner":{"id":"user-1"}`,
+		},
+		{
+			name:            "assign rejected",
+			tool:            (*Server).AssignAlert,
+			args:            map[string]any{"owner_id": "user-1"},
+			failing:         true,
+			expectedText:    "failed to assign alert with ID alert-1",
+			expectedIsError: true,
+			expectedPath:    "/v2/alerts/alert-1/assign",
+			expectedBody:    `"owner":{"id":"user-1"}`,
+		},
+		{
+			name:            "assign without owner",
+			tool:            (*Server).AssignAlert,
+			args:            map[string]any{},
+			expectedText:    "owner_id parameter is required",
+			expectedIsError: true,
+		},
+		{
+			name:         "escalate",
+			tool:         (*Server).EscalateAlert,
+			args:         map[string]any{"escalation_id": "escalation-1"},
+			expectedText: "The alert is escalated.",
+			expectedPath: "/v2/alerts/alert-1/escalate",
+			expectedBody: `"escalation":{"id":"escalation-1"}`,
+		},
+		{
+			name:            "escalate rejected",
+			tool:            (*Server).EscalateAlert,
+			args:            map[string]any{"escalation_id": "escalation-1"},
+			failing:         true,
+			expectedText:    "failed to escalate alert with ID alert-1",
+			expectedIsError: true,
+			expectedPath:    "/v2/alerts/alert-1/escalate",
+			expectedBody:    `"escalation":{"id":"escalation-1"}`,
+		},
+		{
+			name:            "escalate without escalation",
+			tool:            (*Server).EscalateAlert,
+			args:            map[string]any{"escalation_id": ""},
+			expectedText:    "escalation_id parameter is required",
+			expectedIsError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, fake := newTestServer(t, tc.failing)
+
+			handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tc.tool(s, ctx, request)
+			}
+
+			text, isError := callTool(t, handler, tc.args)
+			if isError != tc.expectedIsError || !strings.Contains(text, tc.expectedText) {
+				t.Errorf("expected result %q with error %t, got %q with error %t", tc.expectedText, tc.expectedIsError, text, isError)
+			}
+
+			requests := fake.actions()
+			if tc.expectedPath == "" {
+				if len(requests) != 0 {
+					t.Errorf("expected no request to OpsGenie, got %+v", requests)
+				}
+				return
+			}
+			if len(requests) != 1 || requests[0].method != http.MethodPost || requests[0].path != tc.expectedPath {
+				t.Fatalf("expected a single request to %s, got %+v", tc.expectedPath, requests)
+			}
+			for _, expected := range []string{tc.expectedBody, `"user":"OKA"`, `"source":"oka"`} {
+				if !strings.Contains(requests[0].body, expected) {
+					t.Errorf("expected request body containing %s, got %s", expected, requests[0].body)
+				}
+			}
+		})
+	}
+}
+
+func TestSnoozeAlert(t *testing.T) {
+	testCases := []struct {
+		name             string
+		args             map[string]any
+		failing          bool
+		expectedDuration time.Duration
+		expectedErr      string
+	}{
+		{
+			name:             "valid window",
+			args:             map[string]any{"duration": "30m", "note": "Known flapping alert"},
+			expectedDuration: 30 * time.Minute,
+		},
+		{
+			name:             "maximum window",
+			args:             map[string]any{"duration": "4h", "note": "Known flapping alert"},
+			expectedDuration: 4 * time.Hour,
+		},
+		{
+			name:        "window above the maximum",
+			args:        map[string]any{"duration": "4h1m", "note": "Known flapping alert"},
+			expectedErr: "duration parameter must be at most 4h0m0s",
+		},
+		{
+			name:        "empty window",
+			args:        map[string]any{"duration": "0s", "note": "Known flapping alert"},
+			expectedErr: "duration parameter must be positive",
+		},
+		{
+			name:        "window in the past",
+			args:        map[string]any{"duration": "-1h", "note": "Known flapping alert"},
+			expectedErr: "duration parameter must be positive",
+		},
+		{
+			name:        "invalid duration",
+			args:        map[string]any{"duration": "until tomorrow", "note": "Known flapping alert"},
+			expectedErr: "invalid duration parameter",
+		},
+		{
+			name:        "missing note",
+			args:        map[string]any{"duration": "30m"},
+			expectedErr: "note parameter is required",
+		},
+		{
+			name:             "snooze rejected",
+			args:             map[string]any{"duration": "30m", "note": "Known flapping alert"},
+			failing:          true,
+			expectedDuration: 30 * time.Minute,
+			expectedErr:      "failed to snooze alert with ID alert-1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, fake := newTestServer(t, tc.failing)
+
+			start := time.Now()
+			text, isError := callTool(t, s.SnoozeAlert, tc.args)
+			end := time.Now()
+
+			if tc.expectedErr == "" {
+				if isError || !strings.HasPrefix(text, "The alert is snoozed until ") {
+					t.Errorf("expected the alert to be snoozed, got %q", text)
+				}
+			} else if !isError || !strings.Contains(text, tc.expectedErr) {
+				t.Errorf("expected error containing %q, got %q", tc.expectedErr, text)
+			}
+
+			requests := fake.actions()
+			if tc.expectedDuration == 0 {
+				if len(requests) != 0 {
+					t.Errorf("expected no request to OpsGenie for an invalid window, got %+v", requests)
+				}
+				return
+			}
+			if len(requests) != 1 || requests[0].path != "/v2/alerts/alert-1/snooze" {
+				t.Fatalf("expected a single snooze request, got %+v", requests)
+			}
+
+			var body struct {
+				EndTime time.Time `json:"endTime"`
+				Note    string    `json:"note"`
+			}
+			err := json.Unmarshal([]byte(requests[0].body), &body)
+			if err != nil {
+				t.Fatalf("failed to decode snooze request: %v", err)
+			}
+			if body.EndTime.Before(start.Add(tc.expectedDuration)) || body.EndTime.After(end.Add(tc.expectedDuration)) {
+				t.Errorf("expected the alert to be snoozed for %s from now, got until %s", tc.expectedDuration, body.EndTime)
+			}
+			if body.Note != tc.args["note"] {
+				t.Errorf("expected note %q, got %q", tc.args["note"], body.Note)
+			}
+		})
+	}
+}
+
+func TestAddAndRemoveTags(t *testing.T) {
+	var tooMany []any
+	for i := range maxTagsPerCall + 1 {
+		tooMany = append(tooMany, fmt.Sprintf("tag-%d", i))
+	}
+
+	testCases := []struct {
+		name            string
+		tool            func(*Server, context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)
+		tags            []any
+		failing         bool
+		expectedText    string
+		expectedIsError bool
+		expectedRequest *alertRequest
+	}{
+		{
+			name:         "add",
+			tool:         (*Server).AddTags,
+			tags:         []any{"root-cause:node-pressure", "node pressure"},
+			expectedText: "Added the tags root-cause:node-pressure, node-pressure to the alert.",
+			expectedRequest: &alertRequest{
+				method: http.MethodPost,
+				body:   `"tags":["root-cause:node-pressure","node-pressure"]`,
+			},
+		},
+		{
+			name:            "add rejected",
+			tool:            (*Server).AddTags,
+			tags:            []any{"flapping"},
+			failing:         true,
+			expectedText:    "failed to add tags to alert with ID alert-1",
+			expectedIsError: true,
+			expectedRequest: &alertRequest{
+				method: http.MethodPost,
+				body:   `"tags":["flapping"]`,
+			},
+		},
+		{
+			name:            "add above the cap",
+			tool:            (*Server).AddTags,
+			tags:            tooMany,
+			expectedText:    fmt.Sprintf("tags parameter must contain at most %d tags", maxTagsPerCall),
+			expectedIsError: true,
+		},
+		{
+			name:         "remove",
+			tool:         (*Server).RemoveTags,
+			tags:         []any{"flapping", "cluster:wc1"},
+			expectedText: "Removed the tags flapping, cluster:wc1 from the alert.",
+			expectedRequest: &alertRequest{
+				method: http.MethodDelete,
+				query:  "tags=flapping%2Ccluster%3Awc1",
+			},
+		},
+		{
+			name:            "remove rejected",
+			tool:            (*Server).RemoveTags,
+			tags:            []any{"flapping"},
+			failing:         true,
+			expectedText:    "failed to remove tags from alert with ID alert-1",
+			expectedIsError: true,
+			expectedRequest: &alertRequest{
+				method: http.MethodDelete,
+				query:  "tags=flapping",
+			},
+		},
+		{
+			name:            "remove above the cap",
+			tool:            (*Server).RemoveTags,
+			tags:            tooMany,
+			expectedText:    fmt.Sprintf("tags parameter must contain at most %d tags", maxTagsPerCall),
+			expectedIsError: true,
+		},
+		{
+			name:            "remove without tags",
+			tool:            (*Server).RemoveTags,
+			tags:            []any{" "},
+			expectedText:    "tags parameter must contain at least one non-empty tag",
+			expectedIsError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, fake := newTestServer(t, tc.failing)
+			handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tc.tool(s, ctx, request)
+			}
+
+			text, isError := callTool(t, handler, map[string]any{"tags": tc.tags})
+			if isError != tc.expectedIsError || !strings.Contains(text, tc.expectedText) {
+				t.Errorf("expected result %q with error %t, got %q with error %t", tc.expectedText, tc.expectedIsError, text, isError)
+			}
+
+			requests := fake.actions()
+			if tc.expectedRequest == nil {
+				if len(requests) != 0 {
+					t.Errorf("expected no request to OpsGenie, got %+v", requests)
+				}
+				return
+			}
+			if len(requests) != 1 || requests[0].method != tc.expectedRequest.method || requests[0].path != "/v2/alerts/alert-1/tags" {
+				t.Fatalf("expected a single %s request to the tags of the alert, got %+v", tc.expectedRequest.method, requests)
+			}
+			if !strings.Contains(requests[0].body, tc.expectedRequest.body) {
+				t.Errorf("expected request body containing %s, got %s", tc.expectedRequest.body, requests[0].body)
+			}
+			if !strings.Contains(requests[0].query, tc.expectedRequest.query) {
+				t.Errorf("expected request query containing %s, got %s", tc.expectedRequest.query, requests[0].query)
+			}
+		})
+	}
+}
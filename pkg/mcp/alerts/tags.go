@@ -0,0 +1,56 @@
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxTagsPerCall is the maximum number of tags added or removed by a
+	// single tool call.
+	maxTagsPerCall = 10
+
+	// maxTagLength is the maximum length of an OpsGenie tag.
+	maxTagLength = 50
+)
+
+// sanitizeTags returns the tags given to a tool call, sanitized: whitespace
+// and commas, which separate the tags of OpsGenie requests, are replaced by
+// dashes, other control characters are dropped, and tags are truncated to the
+// maximum tag length. Empty and duplicate tags are dropped. It returns an
+// error if no tag or more than maxTagsPerCall tags are left.
+func sanitizeTags(tags []string) ([]string, error) {
+	sanitized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.Map(func(r rune) rune {
+			switch {
+			case unicode.IsSpace(r) || r == ',':
+				return '-'
+			case unicode.IsControl(r):
+				return -1
+			}
+			return r
+		}, strings.TrimSpace(tag))
+
+		if runes := []rune(tag); len(runes) > maxTagLength {
+			tag = string(runes[:maxTagLength])
+		}
+
+		if tag == "" || slices.Contains(sanitized, tag) {
+			continue
+		}
+		sanitized = append(sanitized, tag)
+	}
+
+	if len(sanitized) == 0 {
+		return nil, errors.New("tags parameter must contain at least one non-empty tag")
+	}
+	if len(sanitized) > maxTagsPerCall {
+		return nil, fmt.Errorf("tags parameter must contain at most %d tags, got %d", maxTagsPerCall, len(sanitized))
+	}
+
+	return sanitized, nil
+}
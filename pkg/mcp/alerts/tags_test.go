@@ -0,0 +1,87 @@
+package alerts
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeTags(t *testing.T) {
+	var tooMany []string
+	for i := range maxTagsPerCall + 1 {
+		tooMany = append(tooMany, fmt.Sprintf("tag-%d", i))
+	}
+
+	testCases := []struct {
+		name         string
+		tags         []string
+		expectedTags []string
+		expectedErr  string
+	}{
+		{
+			name:         "valid tags",
+			tags:         []string{"root-cause:node-pressure", "cluster:wc1"},
+			expectedTags: []string{"root-cause:node-pressure", "cluster:wc1"},
+		},
+		{
+			name:         "whitespace and commas",
+			tags:         []string{"  node pressure ", "a,b", "tab\there"},
+			expectedTags: []string{"node-pressure", "a-b", "tab-here"},
+		},
+		{
+			name:         "control characters",
+			tags:         []string{"clean\x00ed\x1b"},
+			expectedTags: []string{"cleaned"},
+		},
+		{
+			name:         "long tag",
+			tags:         []string{strings.Repeat("é", maxTagLength+10)},
+			expectedTags: []string{strings.Repeat("é", maxTagLength)},
+		},
+		{
+			name:         "empty and duplicate tags",
+			tags:         []string{"", "  ", "flapping", "flapping", " flapping"},
+			expectedTags: []string{"flapping"},
+		},
+		{
+			name:         "at the cap",
+			tags:         tooMany[:maxTagsPerCall],
+			expectedTags: tooMany[:maxTagsPerCall],
+		},
+		{
+			name:         "duplicates within the cap",
+			tags:         append(slices.Clone(tooMany[:maxTagsPerCall]), "tag-0"),
+			expectedTags: tooMany[:maxTagsPerCall],
+		},
+		{
+			name:        "above the cap",
+			tags:        tooMany,
+			expectedErr: fmt.Sprintf("tags parameter must contain at most %d tags, got %d", maxTagsPerCall, maxTagsPerCall+1),
+		},
+		{
+			name:        "no tag",
+			tags:        []string{" ", "\x00"},
+			expectedErr: "tags parameter must contain at least one non-empty tag",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tags, err := sanitizeTags(tc.tags)
+			if tc.expectedErr != "" {
+				if err == nil || err.Error() != tc.expectedErr {
+					t.Errorf("expected error %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to sanitize tags: %v", err)
+			}
+
+			if !slices.Equal(tags, tc.expectedTags) {
+				t.Errorf("expected tags %q, got %q", tc.expectedTags, tags)
+			}
+		})
+	}
+}
@@ -6,11 +6,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"log/slog"
 	"maps"
+	"os"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mark3labs/mcp-go/client"
@@ -26,31 +27,110 @@ import (
 var (
 	// defaultInitTimeout is the default timeout for initializing an MCP client.
 	defaultInitTimeout = 15 * time.Second
+
+	// maxStartAttempts is the maximum number of attempts to start an MCP
+	// client before giving up.
+	maxStartAttempts = 3
+
+	// startRetryDelay is the delay between two attempts to start an MCP client.
+	startRetryDelay = 2 * time.Second
+)
+
+// Phases of the MCP client registration, reported in RegisterError.
+const (
+	PhaseStart      = "start"
+	PhaseInitialize = "initialize"
+	PhaseListTools  = "list tools"
+	// PhaseListResources lists the resources of the servers with resources
+	// enabled.
+	PhaseListResources = "list resources"
 )
 
+// ErrNoTools is the error of the registration of an MCP server exposing no
+// tools, e.g. because all of them are filtered out.
+var ErrNoTools = errors.New("no tools found")
+
+// RegisterError is returned when an MCP client fails to register. It reports
+// the registration phase which failed.
+type RegisterError struct {
+	Server string
+	Phase  string
+	Err    error
+}
+
+// Error implements the error interface.
+func (e *RegisterError) Error() string {
+	return fmt.Sprintf("failed to %s client for %s: %s", e.Phase, e.Server, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *RegisterError) Unwrap() error {
+	return e.Err
+}
+
 // Clients manages a collection of MCP clients and their associated tools.
 type Clients struct {
-	tools         []llms.Tool
-	toolsClients  map[string]*client.Client
+	// mu protects the fields below, as sessions may register and call tools
+	// concurrently.
+	mu sync.RWMutex
+
+	tools        []llms.Tool
+	toolsClients map[string]*ToolInfo
+	// uniqueClients are the clients owned by this instance, which are closed
+	// by Close. Clients shared with a cloned instance are owned by the
+	// original one.
 	uniqueClients []*client.Client
+	// tmpFiles are the temporary files created for the owned clients, which
+	// are removed by Close.
+	tmpFiles []string
+	// installation restricts the kube contexts available to the Kubernetes
+	// servers registered afterwards, all contexts are available if empty.
+	installation string
+	// servers are the owned clients registered from their configuration,
+	// keyed by server name, which Monitor reconnects when they fail.
+	servers map[string]*configuredServer
+}
+
+// ToolInfo holds the information about a registered tool.
+type ToolInfo struct {
+	Client           *client.Client // Client of the MCP server providing the tool
+	OriginalToolName string         // Name of the tool on the MCP server, which may differ from the name exposed to the LLM
+	Server           string         // Name of the MCP server providing the tool
+	Timeout          time.Duration  // Timeout for calling the tool, the caller's default applies if zero
+
+	// schema is the input schema of the tool, against which the arguments
+	// are validated.
+	schema mcp.ToolInputSchema
+	// readsResource is set for the tool reading the resources of the server.
+	readsResource bool
+	// stderr holds the recent stderr output of stdio servers, reported when
+	// calling the tool fails.
+	stderr *stderrBuffer
 }
 
 // New creates a new Clients instance.
 func New() *Clients {
 	c := &Clients{
 		tools:         make([]llms.Tool, 0),
-		toolsClients:  make(map[string]*client.Client),
+		toolsClients:  make(map[string]*ToolInfo),
 		uniqueClients: make([]*client.Client, 0),
 	}
 
 	return c
 }
 
-// Clone creates a new Clients instance with the same tools and clients.
-func (c Clients) Clone() *Clients {
+// Clone creates a new Clients instance with the same tools and clients. The
+// clone does not own the clients of c, closing the clone only closes the
+// clients registered on it afterwards, e.g. the non-shared servers of a
+// session.
+func (c *Clients) Clone() *Clients {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	newClients := &Clients{
-		tools:        make([]llms.Tool, len(c.tools)),
-		toolsClients: make(map[string]*client.Client, len(c.toolsClients)),
+		tools:         make([]llms.Tool, len(c.tools)),
+		toolsClients:  make(map[string]*ToolInfo, len(c.toolsClients)),
+		uniqueClients: make([]*client.Client, 0),
 	}
 
 	newClients.toolsClients = maps.Clone(c.toolsClients)
@@ -59,9 +139,28 @@ func (c Clients) Clone() *Clients {
 	return newClients
 }
 
+// SetInstallation restricts the kube contexts available to the Kubernetes MCP
+// servers registered afterwards to those of the installation, e.g. the one of
+// the alert investigated by a session.
+func (c *Clients) SetInstallation(installation string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.installation = installation
+}
+
 // RegisterServersConfig registers MCP servers from the provided configuration.
-func (c *Clients) RegisterServersConfig(ctx context.Context, mcpServers config.MCPServers) error {
-	serverCount := 0
+// A summary of the servers' status is logged once all of them are processed.
+// Servers failing to register are skipped, unless failFast is set in which
+// case the first failure is returned.
+func (c *Clients) RegisterServersConfig(ctx context.Context, mcpServers config.MCPServers, failFast bool) error {
+	if len(mcpServers) == 0 {
+		return nil
+	}
+
+	statuses := make([]serverStatus, 0, len(mcpServers))
+	defer func() { logServersSummary(statuses) }()
+
 	for name, server := range mcpServers {
 		select {
 		case <-ctx.Done():
@@ -70,32 +169,89 @@ func (c *Clients) RegisterServersConfig(ctx context.Context, mcpServers config.M
 			// Continue if context is not done
 		}
 
+		status := serverStatus{
+			name:      name,
+			transport: transportName(server),
+		}
+
 		if server.Disabled {
 			slog.Info("Skipping disabled MCP server", "server", name)
+			status.status = statusSkipped
+			statuses = append(statuses, status)
 			continue
 		}
 
+		toolsCount := c.toolsCount()
+		err := c.registerServerConfig(ctx, server, name)
+		if err != nil {
+			status.status = statusFailed
+			status.err = err
+			statuses = append(statuses, status)
+			if failFast {
+				return err
+			}
+
+			slog.Error("Skipping MCP server which failed to register", "server", name, "error", err)
+			continue
+		}
+
+		status.tools = c.toolsCount() - toolsCount
+		status.status = statusUp
+		statuses = append(statuses, status)
+	}
+
+	return nil
+}
+
+// registerServerConfig creates and registers the client of a configured MCP
+// server. Starting the client is retried up to maxStartAttempts times, as
+// starting may transiently fail.
+func (c *Clients) registerServerConfig(ctx context.Context, server config.MCPServer, name string) error {
+	for attempt := 1; ; attempt++ {
 		// Create a new MCP client.
-		sc, err := newClient(server)
+		c.mu.RLock()
+		installation := c.installation
+		c.mu.RUnlock()
+
+		sc, tmpFile, err := newClient(server, installation)
 		if err != nil {
 			return err
 		}
 
-		err = c.RegisterClient(ctx, sc, name, server.InitializeTimeoutSeconds)
+		err = c.RegisterClient(ctx, sc, name, server)
 		if err != nil {
-			// If the client failed to initialize, close it and continue.
-			return err
+			// The client is not tracked if it failed to register, close it
+			// before retrying or returning so that the stdio server process
+			// does not leak.
+			closeErr := sc.Close()
+			if closeErr != nil {
+				slog.Warn("Failed to close MCP client which failed to register", "server", name, "error", closeErr)
+			}
+		}
+		if tmpFile != "" {
+			c.trackTmpFile(tmpFile, err == nil)
+		}
+		if err == nil {
+			c.trackServer(name, server, sc, tmpFile)
 		}
 
-		serverCount++
-	}
+		var registerErr *RegisterError
+		if err == nil || attempt >= maxStartAttempts || !errors.As(err, &registerErr) || registerErr.Phase != PhaseStart {
+			return err
+		}
 
-	slog.Info("Finished initializing all MCP clients", "count", serverCount)
+		slog.Warn("Failed to start MCP client, retrying", "server", name, "attempt", attempt, "error", err)
 
-	return nil
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(startRetryDelay):
+		}
+	}
 }
 
-// RegisterServer registers a new MCP server.
+// RegisterServer registers a new in-process MCP server. The server is served
+// by an in-process client, which is closed along with the other clients.
 func (c *Clients) RegisterServer(ctx context.Context, mcpServer *server.MCPServer, name string) error {
 	// Create a new MCP client.
 	sc, err := client.NewInProcessClient(mcpServer)
@@ -103,30 +259,45 @@ func (c *Clients) RegisterServer(ctx context.Context, mcpServer *server.MCPServe
 		return err
 	}
 
-	err = c.RegisterClient(ctx, sc, name, nil)
+	err = c.RegisterClient(ctx, sc, name, config.MCPServer{})
 	if err != nil {
-		// If the client failed to initialize, close it and continue.
+		// The client is not tracked if it failed to initialize, close it so
+		// that the in-process server does not outlive the clients.
+		closeErr := sc.Close()
+		if closeErr != nil {
+			slog.Warn("Failed to close in-process MCP client", "server", name, "error", closeErr)
+		}
 		return err
 	}
 
-	slog.Info("Finished initializing all MCP clients")
+	slog.Info("Registered in-process MCP server", "server", name)
 
 	return nil
 }
 
-// RegisterClient registers a new MCP client.
-func (c *Clients) RegisterClient(ctx context.Context, sc *client.Client, name string, initializeTimeoutSeconds *int) error {
+// RegisterClient registers a new MCP client, using the provided server
+// configuration for its initialization and tools settings. A server exposing
+// no tools fails to register with ErrNoTools. The client is not closed if it
+// fails to register.
+func (c *Clients) RegisterClient(ctx context.Context, sc *client.Client, name string, server config.MCPServer) error {
 	slog.Info("Initializing MCP client", "server", name)
 
+	timeout := defaultInitTimeout
+	if server.InitializeTimeoutSeconds != nil {
+		timeout = time.Duration(*server.InitializeTimeoutSeconds) * time.Second
+	}
+
 	// Start the client.
-	err := sc.Start(ctx)
+	err := startClient(ctx, sc, timeout)
 	if err != nil {
-		return fmt.Errorf("failed to start client for %s: %w", name, err)
+		return &RegisterError{Server: name, Phase: PhaseStart, Err: err}
 	}
 
-	timeout := defaultInitTimeout
-	if initializeTimeoutSeconds != nil {
-		timeout = time.Duration(*initializeTimeoutSeconds) * time.Second
+	// Capture the stderr of stdio servers, which often holds the cause of
+	// their failures.
+	var stderr *stderrBuffer
+	if stdioTransport, ok := sc.GetTransport().(*transport.Stdio); ok && stdioTransport.Stderr() != nil {
+		stderr = captureStderr(name, stdioTransport.Stderr())
 	}
 
 	// Create a context with timeout for initialization.
@@ -136,69 +307,180 @@ func (c *Clients) RegisterClient(ctx context.Context, sc *client.Client, name st
 	// Initialize the client.
 	_, err = sc.Initialize(ctx, mcp.InitializeRequest{})
 	if err != nil {
-		t := sc.GetTransport()
-		stdioTransport, ok := t.(*transport.Stdio)
-		if ok {
-			stdioErr, readErr := io.ReadAll(stdioTransport.Stderr())
-			if readErr != nil {
-				slog.Warn("Failed to read stderr from MCP client", "server", name, "error", readErr)
-			} else if len(stdioErr) > 0 {
-				return fmt.Errorf("failed to initialize client for %s: Stderr: %s", name, string(stdioErr))
-			}
+		if output := stderr.String(); output != "" {
+			return &RegisterError{Server: name, Phase: PhaseInitialize, Err: fmt.Errorf("stderr: %s", output)}
 		}
-		return fmt.Errorf("failed to initialize client for %s: %w", name, err)
+		return &RegisterError{Server: name, Phase: PhaseInitialize, Err: err}
 	}
 
 	// List tools from the MCP server.
 	toolsResult, err := sc.ListTools(ctx, mcp.ListToolsRequest{})
 	if err != nil {
-		return fmt.Errorf("failed to list tools for %s: %w", name, err)
+		return &RegisterError{Server: name, Phase: PhaseListTools, Err: err}
 	}
+	toolsResult.Tools = filterTools(name, toolsResult.Tools, server.AllowedTools, server.DeniedTools)
 
-	if len(toolsResult.Tools) == 0 {
-		slog.Warn("No tools found for MCP client", "server", name)
-		err = sc.Close()
+	// Expose the resources of the server through a tool reading them.
+	readsResources := false
+	if server.Resources {
+		tool, ok, err := resourceTool(ctx, sc, name)
 		if err != nil {
-			return fmt.Errorf("failed to close client for %s: %w", name, err)
+			return &RegisterError{Server: name, Phase: PhaseListResources, Err: err}
 		}
-		// TODO: define and handle no tool error
-		return nil
+
+		switch {
+		case !ok:
+		case slices.ContainsFunc(toolsResult.Tools, func(t mcp.Tool) bool { return t.Name == readResourceTool }):
+			slog.Warn("Tool already exists, not exposing resources", "server", name, "tool", readResourceTool)
+		default:
+			toolsResult.Tools = append(toolsResult.Tools, tool)
+			readsResources = true
+		}
+	}
+
+	if len(toolsResult.Tools) == 0 {
+		return &RegisterError{Server: name, Phase: PhaseListTools, Err: ErrNoTools}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Tools are exposed to the LLM with their name prefixed by the server
+	// name, which may still collide once sanitized and truncated.
+	llmTools := convertToolsResultToLLMtools(name, toolsResult.Tools)
+	err = c.checkToolNames(name, toolsResult.Tools, llmTools)
+	if err != nil {
+		return err
 	}
 
 	c.uniqueClients = append(c.uniqueClients, sc)
 
 	// Register tools' client.
-	llmTools := convertToolsResultToLLMtools(toolsResult.Tools)
-	toolsCount := 0
-	for _, tool := range llmTools {
-		_, exists := c.toolsClients[tool.Function.Name]
-		if exists {
-			slog.Warn("Tool already exists, skipping", "server", name, "tool", tool.Function.Name)
-			continue
+	for i, tool := range llmTools {
+		originalName := toolsResult.Tools[i].Name
+
+		c.toolsClients[tool.Function.Name] = &ToolInfo{
+			Client:           sc,
+			OriginalToolName: originalName,
+			Server:           name,
+			Timeout:          server.ToolTimeouts[originalName],
+			schema:           toolsResult.Tools[i].InputSchema,
+			readsResource:    readsResources && originalName == readResourceTool,
+			stderr:           stderr,
 		}
-
-		c.toolsClients[tool.Function.Name] = sc
 		c.tools = append(c.tools, tool)
-		toolsCount++
 	}
 
-	slog.Info("Initialized MCP client", "server", name, "tools", toolsCount)
+	slog.Info("Initialized MCP client", "server", name, "tools", len(llmTools))
+
+	return nil
+}
+
+// checkToolNames returns an error if the name exposed to the LLM of one of the
+// tools of the server is already registered or is the name of another of its
+// tools. The caller must hold the lock.
+func (c *Clients) checkToolNames(server string, mcpTools []mcp.Tool, llmTools []llms.Tool) error {
+	names := make(map[string]string, len(llmTools))
+	for i, tool := range llmTools {
+		originalName := mcpTools[i].Name
+
+		if info, exists := c.toolsClients[tool.Function.Name]; exists {
+			return fmt.Errorf("tool %s of %s collides with tool %s of %s as %s", originalName, server, info.OriginalToolName, info.Server, tool.Function.Name)
+		}
+		if other, exists := names[tool.Function.Name]; exists {
+			return fmt.Errorf("tools %s and %s of %s collide as %s", other, originalName, server, tool.Function.Name)
+		}
+		names[tool.Function.Name] = originalName
+	}
 
 	return nil
 }
 
-// newClient creates a new MCP client from the provided configuration.
-func newClient(mcpServer config.MCPServer) (c *client.Client, err error) {
+// filterTools returns the tools of the server which are allowed: the tools
+// listed in allowed, or all of them if allowed is empty, which are not listed
+// in denied. Denied tools are never allowed.
+func filterTools(server string, tools []mcp.Tool, allowed, denied []string) []mcp.Tool {
+	if len(allowed) == 0 && len(denied) == 0 {
+		return tools
+	}
+
+	filtered := make([]mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if slices.Contains(denied, tool.Name) || (len(allowed) > 0 && !slices.Contains(allowed, tool.Name)) {
+			slog.Debug("Filtering out MCP tool", "server", server, "tool", tool.Name)
+			continue
+		}
+		filtered = append(filtered, tool)
+	}
+
+	return filtered
+}
+
+// startClient starts the MCP client, giving up after the provided timeout. The
+// client is not started with a timeout context as the context may be bound to
+// the client's lifetime (e.g. a stdio subprocess), instead it is closed when
+// starting times out.
+func startClient(ctx context.Context, sc *client.Client, timeout time.Duration) error {
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- sc.Start(ctx)
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(timeout):
+		err := sc.Close()
+		if err != nil {
+			slog.Warn("Failed to close MCP client after start timeout", "error", err)
+		}
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+}
+
+// trackTmpFile tracks a temporary file created for a client, so that it is
+// removed when closing the clients. The file is removed right away if the
+// client failed to register.
+func (c *Clients) trackTmpFile(tmpFile string, registered bool) {
+	if !registered {
+		removeTmpFile(tmpFile)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tmpFiles = append(c.tmpFiles, tmpFile)
+}
+
+// removeTmpFile removes a temporary file, logging failures.
+func removeTmpFile(tmpFile string) {
+	err := os.Remove(tmpFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("Failed to remove temporary file", "file", tmpFile, "error", err)
+	}
+}
+
+// newClient creates a new MCP client from the provided configuration. It also
+// returns the path of the temporary file created for the client, if any, which
+// the caller is responsible for removing. The kube contexts of Kubernetes
+// servers are restricted to the installation, if not empty.
+func newClient(mcpServer config.MCPServer, installation string) (c *client.Client, tmpFile string, err error) {
 	var t transport.Interface
 
-	switch {
-	case mcpServer.URL != "":
+	switch mcpServer.TransportType() {
+	case config.TransportHTTP:
 		t, err = transport.NewStreamableHTTP(mcpServer.URL)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create transport: %w", err)
+			return nil, "", fmt.Errorf("failed to create transport: %w", err)
+		}
+	case config.TransportSSE:
+		t, err = transport.NewSSE(mcpServer.URL)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create transport: %w", err)
 		}
-	case mcpServer.Command != "":
-		fallthrough
 	default:
 		mcpEnv := mcpServer.Env
 		// Create temporary kubeconfig file if the command is for Kubernetes.
@@ -206,10 +488,11 @@ func newClient(mcpServer config.MCPServer) (c *client.Client, err error) {
 		// current user's context.
 		if strings.Contains(mcpServer.Command, "kubernetes") {
 			// Create a temporary kubeconfig file.
-			kubeConfigFile, err := kubernetes.CreateTmpKubeConfigFile()
+			kubeConfigFile, err := kubernetes.CreateTmpKubeConfigFile(installation)
 			if err != nil {
-				return nil, err
+				return nil, "", err
 			}
+			tmpFile = kubeConfigFile
 			// Add the kubeconfig file to the environment variables.
 			if mcpEnv == nil {
 				mcpEnv = make([]string, 0)
@@ -217,30 +500,49 @@ func newClient(mcpServer config.MCPServer) (c *client.Client, err error) {
 
 			mcpEnv = append(mcpEnv, fmt.Sprintf("KUBECONFIG=%s", kubeConfigFile))
 
-			slog.Info("Using temporary kubeconfig file", "file", kubeConfigFile)
-			// TODO: handle cleanup of the temporary file properly
-			//defer os.Remove(kubeConfigFile) // Clean up the temporary file after use
+			slog.Info("Using temporary kubeconfig file", "file", kubeConfigFile, "installation", installation)
 		}
 		t = transport.NewStdio(mcpServer.Command, mcpEnv, mcpServer.Args...)
 	}
 
 	c = client.NewClient(t)
 
-	return c, nil
+	return c, tmpFile, nil
 }
 
-// Close closes all unique MCP clients.
+// serverName returns the name of the MCP server the client is connected to,
+// from the tools it provides. The caller must hold the lock.
+func (c *Clients) serverName(sc *client.Client) string {
+	for _, info := range c.toolsClients {
+		if info.Client == sc {
+			return info.Server
+		}
+	}
+
+	return "unknown server"
+}
+
+// Close closes the MCP clients owned by this instance.
 func (c *Clients) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	var errs []error
 
-	for name, client := range c.uniqueClients {
-		err := client.Close()
+	for _, sc := range c.uniqueClients {
+		err := sc.Close()
 		if err != nil {
-			err := fmt.Errorf("failed to close client for %s: %w", name, err)
+			err := fmt.Errorf("failed to close client for %s: %w", c.serverName(sc), err)
 			errs = append(errs, err)
 		}
 	}
 
+	// Temporary files are removed once the clients using them are closed.
+	for _, tmpFile := range c.tmpFiles {
+		removeTmpFile(tmpFile)
+	}
+	c.tmpFiles = nil
+
 	if len(errs) > 0 {
 		return errors.Join(errs...)
 	}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+// newTestServer returns an MCP server with a tool for each of the names.
+func newTestServer(toolNames ...string) *server.MCPServer {
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(true))
+	for _, name := range toolNames {
+		mcpServer.AddTool(mcp.NewTool(name), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(name), nil
+		})
+	}
+
+	return mcpServer
+}
+
+// newFailingServer returns the URL of an MCP server failing every request.
+func newFailingServer(t *testing.T) string {
+	t.Helper()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusInternalServerError)
+	}))
+	t.Cleanup(failing.Close)
+
+	return failing.URL
+}
+
+func TestRegisterServersConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		failFast bool
+	}{
+		{
+			name:     "fail fast",
+			failFast: true,
+		},
+		{
+			name:     "skip failing servers",
+			failFast: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			working := server.NewTestStreamableHTTPServer(newTestServer("ping"))
+			t.Cleanup(working.Close)
+
+			servers := config.MCPServers{
+				"failing": {URL: newFailingServer(t)},
+				"working": {URL: working.URL},
+			}
+
+			c := New()
+			t.Cleanup(func() { _ = c.Close() })
+
+			err := c.RegisterServersConfig(context.Background(), servers, tc.failFast)
+			if tc.failFast {
+				var registerErr *RegisterError
+				if !errors.As(err, &registerErr) || registerErr.Server != "failing" {
+					t.Fatalf("expected the registration error of the failing server, got %v", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("expected the failing server to be skipped, got %v", err)
+				}
+
+				tools := c.GetTools()
+				if len(tools) != 1 || tools[0].Function.Name != "mcp_working_ping" {
+					t.Errorf("expected the tool of the working server to be registered, got %v", tools)
+				}
+			}
+
+			// The client of the failing server is closed, not owned.
+			for _, sc := range c.uniqueClients {
+				if name := c.serverName(sc); name != "working" {
+					t.Errorf("expected only the working server to be owned, got %s", name)
+				}
+			}
+		})
+	}
+}
+
+func TestClientsConcurrentAccess(t *testing.T) {
+	ctx := context.Background()
+
+	c := New()
+	t.Cleanup(func() { _ = c.Close() })
+	err := c.RegisterServer(ctx, newTestServer("ping"), "shared")
+	if err != nil {
+		t.Fatalf("failed to register shared server: %v", err)
+	}
+
+	// Servers are registered while tools are listed and called, which the
+	// race detector reports if the tools are not guarded.
+	const servers = 8
+	var wg sync.WaitGroup
+	for i := range servers {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			err := c.RegisterServer(ctx, newTestServer("ping"), fmt.Sprintf("server%d", i))
+			if err != nil {
+				t.Errorf("failed to register server %d: %v", i, err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := c.CallTool(ctx, "mcp_shared_ping", nil)
+			if err != nil {
+				t.Errorf("failed to call tool: %v", err)
+			}
+			_ = c.GetTools()
+			_ = c.GetToolClient("mcp_shared_ping")
+		}()
+	}
+	wg.Wait()
+
+	tools := c.GetTools()
+	if len(tools) != servers+1 {
+		t.Fatalf("expected %d tools, got %d", servers+1, len(tools))
+	}
+	for i := range servers {
+		_, err := c.CallTool(ctx, fmt.Sprintf("mcp_server%d_ping", i), nil)
+		if err != nil {
+			t.Errorf("failed to call tool of server %d: %v", i, err)
+		}
+	}
+}
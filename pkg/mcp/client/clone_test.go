@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+func TestCloneClose(t *testing.T) {
+	ctx := context.Background()
+
+	shared := New()
+	t.Cleanup(func() { _ = shared.Close() })
+	err := shared.RegisterServer(ctx, newTestServer("ping"), "shared")
+	if err != nil {
+		t.Fatalf("failed to register shared server: %v", err)
+	}
+
+	// The session registers its own stdio server on a clone.
+	clone := shared.Clone()
+	err = clone.RegisterServersConfig(ctx, config.MCPServers{"local": stdioServerConfig(t)}, true)
+	if err != nil {
+		t.Fatalf("failed to register session server: %v", err)
+	}
+
+	for _, tool := range []string{"mcp_shared_ping", "mcp_local_ping"} {
+		_, err = clone.CallTool(ctx, tool, nil)
+		if err != nil {
+			t.Fatalf("failed to call tool %s: %v", tool, err)
+		}
+	}
+
+	localClient := clone.GetToolClient("mcp_local_ping")
+	sharedClient := clone.GetToolClient("mcp_shared_ping")
+
+	err = clone.Close()
+	if err != nil {
+		t.Fatalf("failed to close clone: %v", err)
+	}
+
+	// Only the client registered on the clone is closed.
+	err = localClient.Ping(ctx)
+	if err == nil {
+		t.Error("expected the session client to be closed")
+	}
+
+	err = sharedClient.Ping(ctx)
+	if err != nil {
+		t.Errorf("expected the shared client to remain open, got %v", err)
+	}
+
+	_, err = shared.CallTool(ctx, "mcp_shared_ping", nil)
+	if err != nil {
+		t.Errorf("expected the shared tool to remain callable, got %v", err)
+	}
+}
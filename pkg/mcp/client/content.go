@@ -0,0 +1,82 @@
+package client
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// ToolResult is the result of a tool call.
+type ToolResult struct {
+	// Text is the text content of the result. Non-text contents are replaced
+	// by a placeholder describing them, such as "[image: image/png, 1024 bytes]".
+	Text string
+	// Images are the images returned by the tool, in order, including the
+	// embedded image resources. They can be given to multimodal models.
+	Images []llms.BinaryContent
+}
+
+// toolResult converts the contents of an MCP tool call result. Text contents
+// and text resources are concatenated, images are decoded and the other
+// contents (audio, binary resources, resource links) are only described.
+func toolResult(contents []mcp.Content) ToolResult {
+	var result ToolResult
+	var text strings.Builder
+
+	addImage := func(mimeType, data string) {
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			fmt.Fprintf(&text, "[image: %s, invalid data]", mimeType)
+			return
+		}
+
+		result.Images = append(result.Images, llms.BinaryContent{MIMEType: mimeType, Data: decoded})
+		fmt.Fprintf(&text, "[image: %s, %d bytes]", mimeType, len(decoded))
+	}
+
+	for _, content := range contents {
+		switch c := content.(type) {
+		case mcp.TextContent:
+			text.WriteString(c.Text)
+		case mcp.ImageContent:
+			addImage(c.MIMEType, c.Data)
+		case mcp.AudioContent:
+			fmt.Fprintf(&text, "[audio: %s, omitted]", c.MIMEType)
+		case mcp.ResourceLink:
+			fmt.Fprintf(&text, "[resource link: %s]", c.URI)
+		case mcp.EmbeddedResource:
+			switch r := c.Resource.(type) {
+			case mcp.TextResourceContents:
+				text.WriteString(r.Text)
+			case *mcp.TextResourceContents:
+				text.WriteString(r.Text)
+			case mcp.BlobResourceContents:
+				addResource(&text, addImage, r)
+			case *mcp.BlobResourceContents:
+				addResource(&text, addImage, *r)
+			default:
+				text.WriteString("[resource: unsupported content, omitted]")
+			}
+		default:
+			text.WriteString("[unsupported content, omitted]")
+		}
+	}
+
+	result.Text = text.String()
+
+	return result
+}
+
+// addResource adds a binary resource to a tool result text, as an image if it
+// is one.
+func addResource(text *strings.Builder, addImage func(mimeType, data string), r mcp.BlobResourceContents) {
+	if strings.HasPrefix(r.MIMEType, "image/") {
+		addImage(r.MIMEType, r.Blob)
+		return
+	}
+
+	fmt.Fprintf(text, "[resource %s: %s, omitted]", r.URI, r.MIMEType)
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// testImage is the content of the images returned by the test tools.
+var testImage = []byte("\x89PNG\r\n\x1a\nimage")
+
+func TestToolResult(t *testing.T) {
+	image := base64.StdEncoding.EncodeToString(testImage)
+
+	testCases := []struct {
+		name           string
+		contents       []mcp.Content
+		expectedText   string
+		expectedImages int
+	}{
+		{
+			name:         "text",
+			contents:     []mcp.Content{mcp.NewTextContent("a"), mcp.NewTextContent("b")},
+			expectedText: "ab",
+		},
+		{
+			name:           "text and image",
+			contents:       []mcp.Content{mcp.NewTextContent("graph: "), mcp.NewImageContent(image, "image/png")},
+			expectedText:   "graph: [image: image/png, 13 bytes]",
+			expectedImages: 1,
+		},
+		{
+			name:         "invalid image",
+			contents:     []mcp.Content{mcp.NewImageContent("not base64!", "image/png")},
+			expectedText: "[image: image/png, invalid data]",
+		},
+		{
+			name:         "audio",
+			contents:     []mcp.Content{mcp.NewAudioContent(image, "audio/wav")},
+			expectedText: "[audio: audio/wav, omitted]",
+		},
+		{
+			name:         "resource link",
+			contents:     []mcp.Content{mcp.NewResourceLink("file:///logs", "logs", "", "text/plain")},
+			expectedText: "[resource link: file:///logs]",
+		},
+		{
+			name: "embedded resources",
+			contents: []mcp.Content{
+				mcp.NewEmbeddedResource(mcp.TextResourceContents{URI: "file:///a", MIMEType: "text/plain", Text: "resource text "}),
+				mcp.NewEmbeddedResource(mcp.BlobResourceContents{URI: "file:///b", MIMEType: "image/png", Blob: image}),
+				mcp.NewEmbeddedResource(mcp.BlobResourceContents{URI: "file:///c", MIMEType: "application/pdf", Blob: image}),
+			},
+			expectedText:   "resource text [image: image/png, 13 bytes][resource file:///c: application/pdf, omitted]",
+			expectedImages: 1,
+		},
+		{
+			name:         "no content",
+			expectedText: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := toolResult(tc.contents)
+			if result.Text != tc.expectedText {
+				t.Errorf("expected text %q, got %q", tc.expectedText, result.Text)
+			}
+
+			if len(result.Images) != tc.expectedImages {
+				t.Fatalf("expected %d images, got %d", tc.expectedImages, len(result.Images))
+			}
+			for _, image := range result.Images {
+				if image.MIMEType != "image/png" || !bytes.Equal(image.Data, testImage) {
+					t.Errorf("expected the decoded PNG image, got %s %q", image.MIMEType, image.Data)
+				}
+			}
+		})
+	}
+}
+
+func TestCallToolMixedContent(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(true))
+	mcpServer.AddTool(mcp.NewTool("graph"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.NewTextContent("CPU usage: "),
+				mcp.NewImageContent(base64.StdEncoding.EncodeToString(testImage), "image/png"),
+			},
+		}, nil
+	})
+
+	c := New()
+	t.Cleanup(func() { _ = c.Close() })
+	err := c.RegisterServer(context.Background(), mcpServer, "test")
+	if err != nil {
+		t.Fatalf("failed to register server: %v", err)
+	}
+
+	result, err := c.CallTool(context.Background(), "mcp_test_graph", nil)
+	if err != nil {
+		t.Fatalf("failed to call tool: %v", err)
+	}
+
+	expected := "CPU usage: [image: image/png, 13 bytes]"
+	if result.Text != expected {
+		t.Errorf("expected text %q, got %q", expected, result.Text)
+	}
+	if len(result.Images) != 1 || !bytes.Equal(result.Images[0].Data, testImage) {
+		t.Errorf("expected the image of the tool result, got %d images", len(result.Images))
+	}
+}
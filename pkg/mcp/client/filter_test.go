@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+func TestRegisterServerToolLists(t *testing.T) {
+	testCases := []struct {
+		name          string
+		allowed       []string
+		denied        []string
+		expectedTools []string
+		expectedErr   error
+	}{
+		{
+			name:          "no list",
+			expectedTools: []string{"mcp_test_delete", "mcp_test_get", "mcp_test_list"},
+		},
+		{
+			name:          "allowed tools",
+			allowed:       []string{"get", "list"},
+			expectedTools: []string{"mcp_test_get", "mcp_test_list"},
+		},
+		{
+			name:          "denied tools",
+			denied:        []string{"delete"},
+			expectedTools: []string{"mcp_test_get", "mcp_test_list"},
+		},
+		{
+			name:          "denied takes precedence",
+			allowed:       []string{"delete", "get"},
+			denied:        []string{"delete"},
+			expectedTools: []string{"mcp_test_get"},
+		},
+		{
+			name:          "unknown allowed tool",
+			allowed:       []string{"unknown"},
+			expectedTools: nil,
+			expectedErr:   ErrNoTools,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testServer := server.NewTestStreamableHTTPServer(newTestServer("delete", "get", "list"))
+			t.Cleanup(testServer.Close)
+
+			c := New()
+			t.Cleanup(func() { _ = c.Close() })
+
+			servers := config.MCPServers{
+				"test": {URL: testServer.URL, AllowedTools: tc.allowed, DeniedTools: tc.denied},
+			}
+			err := c.RegisterServersConfig(context.Background(), servers, true)
+			if tc.expectedErr != nil {
+				var registerErr *RegisterError
+				if !errors.As(err, &registerErr) || registerErr.Phase != PhaseListTools || !errors.Is(err, tc.expectedErr) {
+					t.Fatalf("expected the registration to fail listing tools with %v, got %v", tc.expectedErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("failed to register server: %v", err)
+			}
+
+			var tools []string
+			for _, tool := range c.GetTools() {
+				tools = append(tools, tool.Function.Name)
+			}
+			slices.Sort(tools)
+			if !slices.Equal(tools, tc.expectedTools) {
+				t.Errorf("expected tools %q, got %q", tc.expectedTools, tools)
+			}
+
+			// The filtered tools cannot be called either.
+			for _, tool := range []string{"mcp_test_delete", "mcp_test_get", "mcp_test_list"} {
+				registered := c.GetToolClient(tool) != nil
+				if expected := slices.Contains(tc.expectedTools, tool); registered != expected {
+					t.Errorf("expected tool %s to be registered: %t, got %t", tool, expected, registered)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://127.0.0.1:6443
+users:
+- name: test
+  user:
+    token: test
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+`
+
+func TestCloseRemovesTmpKubeConfig(t *testing.T) {
+	dir := t.TempDir()
+	kubeConfig := filepath.Join(dir, "config")
+	err := os.WriteFile(kubeConfig, []byte(testKubeConfig), 0600)
+	if err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+	t.Setenv("KUBECONFIG", kubeConfig)
+
+	// The test binary is served under a name identifying a Kubernetes server.
+	server := stdioServerConfig(t)
+	command := filepath.Join(dir, "mcp-kubernetes")
+	err = os.Symlink(server.Command, command)
+	if err != nil {
+		t.Fatalf("failed to link test executable: %v", err)
+	}
+	server.Command = command
+
+	c := New()
+	err = c.RegisterServersConfig(context.Background(), config.MCPServers{"kubernetes": server}, true)
+	if err != nil {
+		t.Fatalf("failed to register server: %v", err)
+	}
+
+	if len(c.tmpFiles) != 1 {
+		t.Fatalf("expected a temporary kubeconfig to be created, got %v", c.tmpFiles)
+	}
+	tmpFile := c.tmpFiles[0]
+	if _, err := os.Stat(tmpFile); err != nil {
+		t.Fatalf("expected the temporary kubeconfig to exist: %v", err)
+	}
+
+	err = c.Close()
+	if err != nil {
+		t.Fatalf("failed to close clients: %v", err)
+	}
+
+	if _, err := os.Stat(tmpFile); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the temporary kubeconfig to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+// stdioServerEnvVar makes the test binary serve a test MCP server over stdio
+// instead of running the tests, so that the tests can start stdio servers.
+const stdioServerEnvVar = "OKA_TEST_STDIO_SERVER"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(stdioServerEnvVar) != "" {
+		err := server.ServeStdio(newStdioTestServer())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+// failingToolStderr is the diagnostic the failing tool of the stdio test
+// server writes to its stderr.
+const failingToolStderr = "connection to cluster refused"
+
+// newStdioTestServer returns the MCP server served over stdio by the test
+// binary, with a ping tool and a failing tool writing a diagnostic to stderr.
+func newStdioTestServer() *server.MCPServer {
+	mcpServer := newTestServer("ping")
+	mcpServer.AddTool(mcp.NewTool("fail"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		fmt.Fprintln(os.Stderr, failingToolStderr)
+		// Leave the client time to capture the diagnostic, which is read
+		// concurrently with the response.
+		time.Sleep(100 * time.Millisecond)
+		return nil, errors.New("tool failed")
+	})
+
+	return mcpServer
+}
+
+// stdioServerConfig returns the configuration of a stdio MCP server served by
+// the test binary.
+func stdioServerConfig(t *testing.T) config.MCPServer {
+	t.Helper()
+
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatalf("failed to get test executable: %v", err)
+	}
+
+	return config.MCPServer{
+		Command: executable,
+		Env:     []string{stdioServerEnvVar + "=1"},
+	}
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/health"
+)
+
+// pingTimeout is the time given to an MCP server to answer a ping.
+const pingTimeout = 10 * time.Second
+
+// configuredServer is an MCP server registered from its configuration, which
+// can be reconnected.
+type configuredServer struct {
+	config config.MCPServer
+	// client is the client of the server, nil if reconnecting it failed.
+	client *client.Client
+	// tmpFile is the temporary file created for the client, if any.
+	tmpFile string
+}
+
+// trackServer tracks the client of a server registered from its
+// configuration, so that Monitor reconnects it when it fails. Clients closed
+// by RegisterClient because the server has no tool are not tracked.
+func (c *Clients) trackServer(name string, server config.MCPServer, sc *client.Client, tmpFile string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !slices.Contains(c.uniqueClients, sc) {
+		return
+	}
+
+	if c.servers == nil {
+		c.servers = make(map[string]*configuredServer)
+	}
+	c.servers[name] = &configuredServer{config: server, client: sc, tmpFile: tmpFile}
+}
+
+// Monitor pings the MCP servers registered from their configuration every
+// interval until the context is canceled. The servers which do not answer,
+// e.g. a crashed stdio subprocess, are reconnected and their tools refreshed.
+// Running sessions keep the tools they started with, the sessions started
+// afterwards use the reconnected clients. The MCP readiness check fails while
+// a server cannot be reconnected.
+func (c *Clients) Monitor(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			health.SetReady(health.CheckMCP, c.checkServers(ctx))
+		}
+	}
+}
+
+// checkServers pings the servers registered from their configuration and
+// reconnects those which do not answer. It returns whether all of them are
+// healthy.
+func (c *Clients) checkServers(ctx context.Context) bool {
+	c.mu.RLock()
+	servers := maps.Clone(c.servers)
+	c.mu.RUnlock()
+
+	healthy := true
+	for _, name := range slices.Sorted(maps.Keys(servers)) {
+		server := servers[name]
+
+		err := ping(ctx, server.client)
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return healthy
+		}
+
+		slog.Warn("MCP server is not answering, reconnecting", "server", name, "error", err)
+		err = c.reconnect(ctx, name, server)
+		if err != nil {
+			slog.Error("Failed to reconnect MCP server", "server", name, "error", err)
+			healthy = false
+			continue
+		}
+
+		slog.Info("Reconnected MCP server", "server", name)
+	}
+
+	return healthy
+}
+
+// ping pings an MCP server through its client.
+func ping(ctx context.Context, sc *client.Client) error {
+	if sc == nil {
+		return errors.New("not connected")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	return sc.Ping(ctx)
+}
+
+// reconnect closes the client of a server and registers a new one with its
+// configuration, refreshing its tools.
+func (c *Clients) reconnect(ctx context.Context, name string, server *configuredServer) error {
+	c.unregisterServer(name, server)
+
+	if server.client != nil {
+		err := server.client.Close()
+		if err != nil {
+			slog.Warn("Failed to close MCP client", "server", name, "error", err)
+		}
+	}
+	if server.tmpFile != "" {
+		removeTmpFile(server.tmpFile)
+	}
+
+	return c.registerServerConfig(ctx, server.config, name)
+}
+
+// unregisterServer removes the client of a server and its tools. The server
+// stays tracked without client, so that it is reconnected again if
+// registering it fails.
+func (c *Clients) unregisterServer(name string, server *configuredServer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tools = slices.DeleteFunc(c.tools, func(tool llms.Tool) bool {
+		info, ok := c.toolsClients[tool.Function.Name]
+		return ok && info.Server == name
+	})
+	maps.DeleteFunc(c.toolsClients, func(_ string, info *ToolInfo) bool {
+		return info.Server == name
+	})
+
+	if server.client != nil {
+		c.uniqueClients = slices.DeleteFunc(c.uniqueClients, func(sc *client.Client) bool {
+			return sc == server.client
+		})
+	}
+	if server.tmpFile != "" {
+		c.tmpFiles = slices.DeleteFunc(c.tmpFiles, func(tmpFile string) bool {
+			return tmpFile == server.tmpFile
+		})
+	}
+
+	c.servers[name] = &configuredServer{config: server.config}
+}
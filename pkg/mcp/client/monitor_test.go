@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+// restartableServer is an MCP server which can be stopped, failing every
+// request, and restarted with a new state, losing the sessions of its
+// clients.
+type restartableServer struct {
+	mu      sync.Mutex
+	handler http.Handler
+}
+
+// ServeHTTP serves the request with the current server, failing it if the
+// server is stopped.
+func (s *restartableServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	handler := s.handler
+	s.mu.Unlock()
+
+	if handler == nil {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	handler.ServeHTTP(w, r)
+}
+
+// start starts a new server with a tool for each of the names.
+func (s *restartableServer) start(toolNames ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handler = server.NewStreamableHTTPServer(newTestServer(toolNames...))
+}
+
+// stop stops the server.
+func (s *restartableServer) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handler = nil
+}
+
+func TestCheckServersReconnect(t *testing.T) {
+	ctx := context.Background()
+
+	mcpServer := &restartableServer{}
+	mcpServer.start("ping")
+	httpServer := httptest.NewServer(mcpServer)
+	t.Cleanup(httpServer.Close)
+
+	c := New()
+	t.Cleanup(func() { _ = c.Close() })
+	err := c.RegisterServersConfig(ctx, config.MCPServers{"test": {URL: httpServer.URL}}, true)
+	if err != nil {
+		t.Fatalf("failed to register server: %v", err)
+	}
+
+	if !c.checkServers(ctx) {
+		t.Fatal("expected the running server to be healthy")
+	}
+
+	// The tools of a dead server are removed until it is reconnected.
+	mcpServer.stop()
+	if c.checkServers(ctx) {
+		t.Error("expected the stopped server to be unhealthy")
+	}
+	if tools := c.GetTools(); len(tools) != 0 {
+		t.Errorf("expected the tools of the stopped server to be removed, got %d tools", len(tools))
+	}
+
+	// The restarted server is reconnected with its new tools.
+	mcpServer.start("ping", "pong")
+	if !c.checkServers(ctx) {
+		t.Fatal("expected the restarted server to be reconnected")
+	}
+	if tools := c.GetTools(); len(tools) != 2 {
+		t.Errorf("expected the tools of the restarted server to be refreshed, got %d tools", len(tools))
+	}
+	for _, tool := range []string{"mcp_test_ping", "mcp_test_pong"} {
+		result, err := c.CallTool(ctx, tool, nil)
+		if err != nil {
+			t.Errorf("failed to call tool %s after reconnecting: %v", tool, err)
+			continue
+		}
+		if expected := tool[len("mcp_test_"):]; result.Text != expected {
+			t.Errorf("expected tool result %q, got %q", expected, result.Text)
+		}
+	}
+
+	c.mu.RLock()
+	clients := len(c.uniqueClients)
+	c.mu.RUnlock()
+	if clients != 1 {
+		t.Errorf("expected the client of the server to be replaced, got %d clients", clients)
+	}
+}
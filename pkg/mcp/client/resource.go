@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// readResourceTool is the name of the tool reading the resources of an
+	// MCP server, exposed for the servers with resources enabled.
+	readResourceTool = "read_resource"
+
+	// maxListedResources is the maximum number of resources listed in the
+	// description of the read resource tool.
+	maxListedResources = 50
+)
+
+// resourceTool returns the tool reading the resources of an MCP server, whose
+// description lists the server's resources. It returns false if the server
+// has no resource.
+func resourceTool(ctx context.Context, sc *client.Client, server string) (mcp.Tool, bool, error) {
+	result, err := sc.ListResources(ctx, mcp.ListResourcesRequest{})
+	if err != nil {
+		return mcp.Tool{}, false, err
+	}
+	if len(result.Resources) == 0 {
+		slog.Warn("No resources found for MCP client", "server", server)
+		return mcp.Tool{}, false, nil
+	}
+
+	var description strings.Builder
+	fmt.Fprintf(&description, "Read a resource (file, log, document...) of the %s MCP server by its URI. Available resources:\n", server)
+	for i, resource := range result.Resources {
+		if i == maxListedResources {
+			fmt.Fprintf(&description, "- and %d more\n", len(result.Resources)-maxListedResources)
+			break
+		}
+
+		fmt.Fprintf(&description, "- %s: %s", resource.URI, resource.Name)
+		if resource.Description != "" {
+			fmt.Fprintf(&description, ", %s", resource.Description)
+		}
+		description.WriteString("\n")
+	}
+
+	tool := mcp.NewTool(readResourceTool,
+		mcp.WithDescription(description.String()),
+		mcp.WithString("uri", mcp.Required(), mcp.Description("URI of the resource to read")),
+	)
+
+	return tool, true, nil
+}
+
+// readResource reads the resource whose URI is given in the arguments of a
+// read resource tool call. The resource contents are converted like those of
+// a tool result.
+func readResource(ctx context.Context, sc *client.Client, args map[string]any) (ToolResult, error) {
+	uri, ok := args["uri"].(string)
+	if !ok || uri == "" {
+		return ToolResult{}, fmt.Errorf("missing resource uri")
+	}
+
+	req := mcp.ReadResourceRequest{}
+	req.Params.URI = uri
+
+	result, err := sc.ReadResource(ctx, req)
+	if err != nil {
+		return ToolResult{}, fmt.Errorf("failed to read resource %s: %w", uri, err)
+	}
+
+	contents := make([]mcp.Content, 0, len(result.Contents))
+	for _, resource := range result.Contents {
+		contents = append(contents, mcp.EmbeddedResource{Type: "resource", Resource: resource})
+	}
+
+	return toolResult(contents), nil
+}
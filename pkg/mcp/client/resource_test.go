@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+// newResourceServer returns the URL of an MCP server with a ping tool and a
+// runbook and a log resource.
+func newResourceServer(t *testing.T) string {
+	t.Helper()
+
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(true), server.WithResourceCapabilities(false, false))
+	mcpServer.AddTool(mcp.NewTool("ping"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ping"), nil
+	})
+	resources := map[string]string{
+		"file:///runbooks/disk.md": "Free some disk space.",
+		"file:///logs/app.log":     "error: disk full",
+	}
+	for uri, text := range resources {
+		resource := mcp.NewResource(uri, uri[strings.LastIndex(uri, "/")+1:], mcp.WithResourceDescription("test resource"), mcp.WithMIMEType("text/plain"))
+		mcpServer.AddResource(resource, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return []mcp.ResourceContents{
+				mcp.TextResourceContents{URI: uri, MIMEType: "text/plain", Text: text},
+			}, nil
+		})
+	}
+
+	testServer := server.NewTestStreamableHTTPServer(mcpServer)
+	t.Cleanup(testServer.Close)
+
+	return testServer.URL
+}
+
+func TestRegisterServerResources(t *testing.T) {
+	testCases := []struct {
+		name          string
+		resources     bool
+		expectedTools []string
+	}{
+		{
+			name:          "resources enabled",
+			resources:     true,
+			expectedTools: []string{"mcp_docs_ping", "mcp_docs_read_resource"},
+		},
+		{
+			name:          "resources disabled",
+			resources:     false,
+			expectedTools: []string{"mcp_docs_ping"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := New()
+			t.Cleanup(func() { _ = c.Close() })
+
+			servers := config.MCPServers{"docs": {URL: newResourceServer(t), Resources: tc.resources}}
+			err := c.RegisterServersConfig(context.Background(), servers, true)
+			if err != nil {
+				t.Fatalf("failed to register server: %v", err)
+			}
+
+			var tools []string
+			for _, tool := range c.GetTools() {
+				tools = append(tools, tool.Function.Name)
+
+				// The resources are listed in the description of the tool.
+				if tool.Function.Name == "mcp_docs_read_resource" {
+					for _, uri := range []string{"file:///runbooks/disk.md", "file:///logs/app.log"} {
+						if !strings.Contains(tool.Function.Description, uri) {
+							t.Errorf("expected the tool description to list %s, got %q", uri, tool.Function.Description)
+						}
+					}
+				}
+			}
+			slices.Sort(tools)
+			if !slices.Equal(tools, tc.expectedTools) {
+				t.Errorf("expected tools %q, got %q", tc.expectedTools, tools)
+			}
+		})
+	}
+}
+
+func TestReadResource(t *testing.T) {
+	c := New()
+	t.Cleanup(func() { _ = c.Close() })
+
+	servers := config.MCPServers{"docs": {URL: newResourceServer(t), Resources: true}}
+	err := c.RegisterServersConfig(context.Background(), servers, true)
+	if err != nil {
+		t.Fatalf("failed to register server: %v", err)
+	}
+
+	testCases := []struct {
+		name         string
+		args         map[string]any
+		expectedText string
+		expectedErr  string
+	}{
+		{
+			name:         "runbook",
+			args:         map[string]any{"uri": "file:///runbooks/disk.md"},
+			expectedText: "Free some disk space.",
+		},
+		{
+			name:         "log",
+			args:         map[string]any{"uri": "file:///logs/app.log"},
+			expectedText: "error: disk full",
+		},
+		{
+			name:        "unknown resource",
+			args:        map[string]any{"uri": "file:///unknown"},
+			expectedErr: "failed to read resource file:///unknown",
+		},
+		{
+			name:        "missing uri",
+			args:        map[string]any{},
+			expectedErr: "uri",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := c.CallTool(context.Background(), "mcp_docs_read_resource", tc.args)
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Errorf("expected error containing %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to read resource: %v", err)
+			}
+
+			if result.Text != tc.expectedText {
+				t.Errorf("expected resource text %q, got %q", tc.expectedText, result.Text)
+			}
+		})
+	}
+}
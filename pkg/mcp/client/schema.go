@@ -0,0 +1,102 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// validateArguments checks the arguments of a tool call against the input
+// schema of the tool: the required arguments must be given and the arguments
+// must have the type of their property. Other constraints of the schema are
+// left to the MCP server. The error lists all the invalid arguments, so that
+// the LLM can correct its call.
+func validateArguments(schema mcp.ToolInputSchema, args map[string]any) error {
+	var errs []error
+
+	for _, name := range schema.Required {
+		if _, ok := args[name]; !ok {
+			errs = append(errs, fmt.Errorf("missing required argument %q", name))
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(args)) {
+		property, ok := schema.Properties[name].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		types := schemaTypes(property["type"])
+		if len(types) == 0 || slices.ContainsFunc(types, func(t string) bool { return hasType(args[name], t) }) {
+			continue
+		}
+
+		errs = append(errs, fmt.Errorf("argument %q must be of type %s, got %s", name, strings.Join(types, " or "), jsonType(args[name])))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid arguments: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// schemaTypes returns the types allowed by the type keyword of a JSON schema,
+// which is either a type or a list of types.
+func schemaTypes(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		types := make([]string, 0, len(v))
+		for _, t := range v {
+			if s, ok := t.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+
+	return nil
+}
+
+// hasType returns whether a value unmarshaled from JSON has the given JSON
+// schema type. Unknown types match any value.
+func hasType(value any, schemaType string) bool {
+	switch schemaType {
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "string", "number", "boolean", "array", "object", "null":
+		return jsonType(value) == schemaType
+	}
+
+	return true
+}
+
+// jsonType returns the JSON type of a value unmarshaled from JSON.
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+
+	return fmt.Sprintf("%T", value)
+}
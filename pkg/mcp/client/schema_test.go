@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestValidateArguments(t *testing.T) {
+	schema := mcp.ToolInputSchema{
+		Type: "object",
+		Properties: map[string]any{
+			"namespace": map[string]any{"type": "string"},
+			"replicas":  map[string]any{"type": "integer"},
+			"ratio":     map[string]any{"type": "number"},
+			"force":     map[string]any{"type": "boolean"},
+			"labels":    map[string]any{"type": "object"},
+			"names":     map[string]any{"type": "array"},
+			"selector":  map[string]any{"type": []any{"string", "null"}},
+			"anything":  map[string]any{},
+		},
+		Required: []string{"namespace", "replicas"},
+	}
+
+	testCases := []struct {
+		name        string
+		args        map[string]any
+		expectedErr []string
+	}{
+		{
+			name: "valid arguments",
+			args: map[string]any{
+				"namespace": "default",
+				"replicas":  float64(3),
+				"ratio":     0.5,
+				"force":     true,
+				"labels":    map[string]any{"app": "api"},
+				"names":     []any{"a"},
+				"selector":  nil,
+				"anything":  float64(1),
+				"unknown":   "ignored",
+			},
+		},
+		{
+			name:        "missing required argument",
+			args:        map[string]any{"namespace": "default"},
+			expectedErr: []string{`missing required argument "replicas"`},
+		},
+		{
+			name:        "missing required arguments",
+			args:        map[string]any{},
+			expectedErr: []string{`missing required argument "namespace"`, `missing required argument "replicas"`},
+		},
+		{
+			name:        "wrong type",
+			args:        map[string]any{"namespace": float64(1), "replicas": float64(3)},
+			expectedErr: []string{`argument "namespace" must be of type string, got number`},
+		},
+		{
+			name:        "fractional integer",
+			args:        map[string]any{"namespace": "default", "replicas": 1.5},
+			expectedErr: []string{`argument "replicas" must be of type integer, got number`},
+		},
+		{
+			name:        "wrong type among several",
+			args:        map[string]any{"namespace": "default", "replicas": float64(3), "selector": true},
+			expectedErr: []string{`argument "selector" must be of type string or null, got boolean`},
+		},
+		{
+			name: "missing and wrong type",
+			args: map[string]any{"namespace": "default", "force": "yes"},
+			expectedErr: []string{
+				`missing required argument "replicas"`,
+				`argument "force" must be of type boolean, got string`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateArguments(schema, tc.args)
+			if len(tc.expectedErr) == 0 {
+				if err != nil {
+					t.Errorf("expected the arguments to be valid, got %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected the arguments to be invalid")
+			}
+			for _, expected := range tc.expectedErr {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected error containing %q, got %v", expected, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCallToolInvalidArguments(t *testing.T) {
+	called := false
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(true))
+	mcpServer.AddTool(
+		mcp.NewTool("scale", mcp.WithString("name", mcp.Required()), mcp.WithNumber("replicas", mcp.Required())),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			called = true
+			return mcp.NewToolResultText("scaled"), nil
+		},
+	)
+
+	c := New()
+	t.Cleanup(func() { _ = c.Close() })
+	err := c.RegisterServer(context.Background(), mcpServer, "test")
+	if err != nil {
+		t.Fatalf("failed to register server: %v", err)
+	}
+
+	// Invalid calls are rejected before reaching the server.
+	_, err = c.CallTool(context.Background(), "mcp_test_scale", map[string]any{"replicas": "3"})
+	expected := `failed to call tool mcp_test_scale: invalid arguments: missing required argument "name"`
+	if err == nil || !strings.Contains(err.Error(), expected) || !strings.Contains(err.Error(), `argument "replicas" must be of type number, got string`) {
+		t.Errorf("expected error containing %q and the wrong type, got %v", expected, err)
+	}
+	if called {
+		t.Error("expected the invalid call not to reach the server")
+	}
+
+	result, err := c.CallTool(context.Background(), "mcp_test_scale", map[string]any{"name": "api", "replicas": float64(3)})
+	if err != nil || result.Text != "scaled" {
+		t.Errorf("expected the valid call to succeed, got %q, %v", result.Text, err)
+	}
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// maxStderrSize is the number of bytes of the most recent stderr output of a
+// stdio MCP server kept to report its failures.
+const maxStderrSize = 4096
+
+// stderrBuffer keeps the most recent output written to the stderr of a stdio
+// MCP server.
+type stderrBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+// captureStderr copies the stderr of a stdio MCP server into a new buffer
+// until the server exits.
+func captureStderr(server string, stderr io.Reader) *stderrBuffer {
+	b := &stderrBuffer{}
+	go func() {
+		_, err := io.Copy(b, stderr)
+		if err != nil {
+			slog.Debug("Stopped capturing MCP server stderr", "server", server, "error", err)
+		}
+	}()
+
+	return b
+}
+
+// Write implements io.Writer, dropping the oldest output beyond
+// maxStderrSize.
+func (b *stderrBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > maxStderrSize {
+		b.buf = b.buf[len(b.buf)-maxStderrSize:]
+	}
+
+	return len(p), nil
+}
+
+// String returns the most recent stderr output, trimmed.
+func (b *stderrBuffer) String() string {
+	if b == nil {
+		return ""
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return strings.TrimSpace(string(b.buf))
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+func TestCallToolStderr(t *testing.T) {
+	ctx := context.Background()
+
+	c := New()
+	t.Cleanup(func() { _ = c.Close() })
+	err := c.RegisterServersConfig(ctx, config.MCPServers{"stdio": stdioServerConfig(t)}, true)
+	if err != nil {
+		t.Fatalf("failed to register stdio server: %v", err)
+	}
+
+	_, err = c.CallTool(ctx, "mcp_stdio_fail", nil)
+	if err == nil || !strings.Contains(err.Error(), "stderr: "+failingToolStderr) {
+		t.Errorf("expected the error to hold the stderr of the server, got %v", err)
+	}
+
+	// Successful calls are not affected by the stderr output.
+	result, err := c.CallTool(ctx, "mcp_stdio_ping", nil)
+	if err != nil || result.Text != "ping" {
+		t.Errorf("expected the ping tool to succeed, got %q, %v", result.Text, err)
+	}
+}
+
+func TestStderrBuffer(t *testing.T) {
+	b := &stderrBuffer{}
+
+	_, _ = b.Write([]byte("  first line\n"))
+	if output := b.String(); output != "first line" {
+		t.Errorf("expected the trimmed output, got %q", output)
+	}
+
+	// Only the most recent output is kept.
+	_, _ = b.Write([]byte(strings.Repeat("a", maxStderrSize)))
+	_, _ = b.Write([]byte("last"))
+	output := b.String()
+	if len(output) != maxStderrSize || !strings.HasSuffix(output, "last") || strings.Contains(output, "first") {
+		t.Errorf("expected the last %d bytes of output, got %d bytes", maxStderrSize, len(output))
+	}
+
+	var nilBuffer *stderrBuffer
+	if output := nilBuffer.String(); output != "" {
+		t.Errorf("expected no output without buffer, got %q", output)
+	}
+}
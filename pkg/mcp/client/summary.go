@@ -0,0 +1,60 @@
+package client
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+// Registration statuses of MCP servers, reported in the servers summary.
+const (
+	statusUp      = "up"
+	statusSkipped = "skipped"
+	statusFailed  = "failed"
+)
+
+// serverStatus is the registration status of an MCP server.
+type serverStatus struct {
+	name      string
+	status    string
+	transport string
+	tools     int
+	// err is the registration error of a failed server.
+	err error
+}
+
+// transportName returns the name of the transport used for the MCP server.
+func transportName(server config.MCPServer) string {
+	return server.TransportType()
+}
+
+// logServersSummary logs a consolidated summary of the registered MCP servers,
+// one line per server sorted by name.
+func logServersSummary(statuses []serverStatus) {
+	slices.SortFunc(statuses, func(a, b serverStatus) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	counts := make(map[string]int)
+	tools := 0
+	for _, s := range statuses {
+		counts[s.status]++
+		tools += s.tools
+	}
+
+	slog.Info("MCP servers summary",
+		statusUp, counts[statusUp],
+		statusSkipped, counts[statusSkipped],
+		statusFailed, counts[statusFailed],
+		"tools", tools)
+
+	for _, s := range statuses {
+		if s.err != nil {
+			slog.Warn("MCP server", "server", s.name, "status", s.status, "transport", s.transport, "error", s.err)
+			continue
+		}
+		slog.Info("MCP server", "server", s.name, "status", s.status, "transport", s.transport, "tools", s.tools)
+	}
+}
@@ -2,40 +2,101 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/tmc/langchaingo/llms"
 )
 
+// maxToolNameLength is the maximum length of a tool name accepted by the LLM
+// providers.
+const maxToolNameLength = 64
+
 // GetTools returns the list of available tools.
 func (c *Clients) GetTools() []llms.Tool {
-	return c.tools
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return slices.Clone(c.tools)
+}
+
+// toolsCount returns the number of available tools.
+func (c *Clients) toolsCount() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.tools)
+}
+
+// getToolInfo returns the information about a given tool.
+func (c *Clients) getToolInfo(toolName string) (*ToolInfo, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	info, ok := c.toolsClients[toolName]
+	return info, ok
 }
 
 // GetToolClient returns the MCP client for a given tool.
 func (c *Clients) GetToolClient(toolName string) *client.Client {
-	return c.toolsClients[toolName]
+	info, ok := c.getToolInfo(toolName)
+	if !ok {
+		return nil
+	}
+
+	return info.Client
+}
+
+// GetToolTimeout returns the configured timeout for calling a given tool, or
+// zero if the tool has no specific timeout.
+func (c *Clients) GetToolTimeout(toolName string) time.Duration {
+	info, ok := c.getToolInfo(toolName)
+	if !ok {
+		return 0
+	}
+
+	return info.Timeout
 }
 
-// CallTool calls a tool with the given name and arguments.
-func (c *Clients) CallTool(ctx context.Context, name string, args map[string]any) (string, error) {
-	client := c.GetToolClient(name)
-	if client == nil {
-		return "", fmt.Errorf("no client found for tool %s", name)
+// CallTool calls a tool with the given name, as exposed to the LLM, and
+// arguments. The tool is called on its MCP server with its original name,
+// except the tools reading resources which read them from their server.
+func (c *Clients) CallTool(ctx context.Context, name string, args map[string]any) (ToolResult, error) {
+	info, ok := c.getToolInfo(name)
+	if !ok || info.Client == nil {
+		return ToolResult{}, fmt.Errorf("no client found for tool %s", name)
+	}
+
+	// Invalid arguments are reported before reaching the server, whose
+	// errors are often less clear.
+	err := validateArguments(info.schema, args)
+	if err != nil {
+		return ToolResult{}, fmt.Errorf("failed to call tool %s: %w", name, err)
+	}
+
+	if info.readsResource {
+		return readResource(ctx, info.Client, args)
 	}
 
 	// Create a proper CallToolRequest.
 	req := mcp.CallToolRequest{}
 	// Set the tool name and arguments in the params field.
-	req.Params.Name = name
+	req.Params.Name = info.OriginalToolName
 	req.Params.Arguments = args
 
 	// Call the tool using the official client.
-	result, err := client.CallTool(ctx, req)
+	result, err := info.Client.CallTool(ctx, req)
 	if err != nil {
-		return "", fmt.Errorf("failed to call tool %s: %w", name, err)
+		// The stderr of stdio servers often holds the actual cause.
+		if output := info.stderr.String(); output != "" {
+			return ToolResult{}, fmt.Errorf("failed to call tool %s: %w, stderr: %s", name, err, output)
+		}
+		return ToolResult{}, fmt.Errorf("failed to call tool %s: %w", name, err)
 	}
 
 	// Check if the tool call resulted in an error.
@@ -52,30 +113,55 @@ func (c *Clients) CallTool(ctx context.Context, name string, args map[string]any
 			errMsgText = "Unknown error"
 		}
 
-		return "", fmt.Errorf(errMsgText)
+		return ToolResult{}, errors.New(errMsgText)
+	}
+
+	return toolResult(result.Content), nil
+}
+
+// GetOriginalToolName returns the name of a given tool on its MCP server, or
+// an empty string if the tool is not registered.
+func (c *Clients) GetOriginalToolName(toolName string) string {
+	info, ok := c.getToolInfo(toolName)
+	if !ok {
+		return ""
 	}
 
-	// Extract text content from the result.
-	var resultText string
-	for _, content := range result.Content {
-		if textContent, ok := content.(mcp.TextContent); ok {
-			resultText += textContent.Text
+	return info.OriginalToolName
+}
+
+// prefixedToolName returns the name of a tool as exposed to the LLM, prefixed
+// with the name of its server as mcp_<server>_<tool> to avoid collisions
+// between servers. Characters not allowed in function names by LLM providers
+// are replaced and the name is truncated to the maximum length.
+func prefixedToolName(serverName, toolName string) string {
+	name := fmt.Sprintf("mcp_%s_%s", serverName, toolName)
+
+	name = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			return r
 		}
+		return '_'
+	}, name)
+
+	if len(name) > maxToolNameLength {
+		name = name[:maxToolNameLength]
 	}
 
-	return resultText, nil
+	return name
 }
 
-// convertToolsResultToLLMtools converts a slice of MCP tools to a slice of
-// LangChainGo tools.
-func convertToolsResultToLLMtools(mcpTools []mcp.Tool) []llms.Tool {
+// convertToolsResultToLLMtools converts a slice of MCP tools of the given
+// server to a slice of LangChainGo tools, in the same order. Tool names are
+// prefixed with the server name, see prefixedToolName.
+func convertToolsResultToLLMtools(serverName string, mcpTools []mcp.Tool) []llms.Tool {
 	var llmsTools []llms.Tool
 
 	for _, mcpTool := range mcpTools {
 		llmTool := llms.Tool{
 			Type: "function",
 			Function: &llms.FunctionDefinition{
-				Name:        mcpTool.Name,
+				Name:        prefixedToolName(serverName, mcpTool.Name),
 				Description: mcpTool.Description,
 			},
 		}
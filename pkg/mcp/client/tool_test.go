@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestPrefixedToolName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		server   string
+		tool     string
+		expected string
+	}{
+		{
+			name:     "valid names",
+			server:   "kubernetes",
+			tool:     "list_pods",
+			expected: "mcp_kubernetes_list_pods",
+		},
+		{
+			name:     "sanitized characters",
+			server:   "my.server",
+			tool:     "get pods/logs",
+			expected: "mcp_my_server_get_pods_logs",
+		},
+		{
+			name:     "truncated name",
+			server:   "server",
+			tool:     strings.Repeat("a", 100),
+			expected: "mcp_server_" + strings.Repeat("a", maxToolNameLength-len("mcp_server_")),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			name := prefixedToolName(tc.server, tc.tool)
+			if name != tc.expected {
+				t.Errorf("expected tool name %q, got %q", tc.expected, name)
+			}
+		})
+	}
+}
+
+func TestRegisterServerToolNames(t *testing.T) {
+	ctx := context.Background()
+	c := New()
+	t.Cleanup(func() { _ = c.Close() })
+
+	// Servers providing tools with the same name are both available.
+	for _, server := range []string{"a", "b"} {
+		err := c.RegisterServer(ctx, newTestServer("list_pods"), server)
+		if err != nil {
+			t.Fatalf("failed to register server %s: %v", server, err)
+		}
+	}
+
+	var names []string
+	for _, tool := range c.GetTools() {
+		names = append(names, tool.Function.Name)
+	}
+	if !slices.Equal(names, []string{"mcp_a_list_pods", "mcp_b_list_pods"}) {
+		t.Errorf("expected the tools of both servers, got %q", names)
+	}
+	if original := c.GetOriginalToolName("mcp_b_list_pods"); original != "list_pods" {
+		t.Errorf("expected the original tool name list_pods, got %q", original)
+	}
+
+	testCases := []struct {
+		name   string
+		server string
+		tools  []string
+	}{
+		{
+			name:   "collision with a tool of another server",
+			server: "a_list",
+			tools:  []string{"pods"},
+		},
+		{
+			name:   "collision between tools of the server once sanitized",
+			server: "c",
+			tools:  []string{"get.pods", "get_pods"},
+		},
+		{
+			name:   "collision between tools of the server once truncated",
+			server: "d",
+			tools:  []string{strings.Repeat("a", 100) + "1", strings.Repeat("a", 100) + "2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := c.RegisterServer(ctx, newTestServer(tc.tools...), tc.server)
+			if err == nil || !strings.Contains(err.Error(), "collide") {
+				t.Errorf("expected a collision error, got %v", err)
+			}
+
+			if tools := c.GetTools(); len(tools) != 2 {
+				t.Errorf("expected no tool of the colliding server to be registered, got %d tools", len(tools))
+			}
+		})
+	}
+}
+
+func TestCallToolOriginalName(t *testing.T) {
+	ctx := context.Background()
+	c := New()
+	t.Cleanup(func() { _ = c.Close() })
+
+	for _, name := range []string{"a", "b"} {
+		mcpServer := server.NewMCPServer(name, "1.0.0", server.WithToolCapabilities(true))
+		mcpServer.AddTool(mcp.NewTool("list_pods"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(name + ": " + request.Params.Name), nil
+		})
+
+		err := c.RegisterServer(ctx, mcpServer, name)
+		if err != nil {
+			t.Fatalf("failed to register server %s: %v", name, err)
+		}
+	}
+
+	// Each tool is called on its server with its original name.
+	for _, name := range []string{"a", "b"} {
+		result, err := c.CallTool(ctx, "mcp_"+name+"_list_pods", nil)
+		if err != nil {
+			t.Fatalf("failed to call tool of server %s: %v", name, err)
+		}
+
+		expected := name + ": list_pods"
+		if result.Text != expected {
+			t.Errorf("expected result %q, got %q", expected, result.Text)
+		}
+	}
+}
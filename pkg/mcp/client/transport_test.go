@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+func TestNewClientTransport(t *testing.T) {
+	testCases := []struct {
+		name              string
+		server            config.MCPServer
+		expectedTransport transport.Interface
+	}{
+		{
+			name:              "url",
+			server:            config.MCPServer{URL: "http://localhost/mcp"},
+			expectedTransport: &transport.StreamableHTTP{},
+		},
+		{
+			name:              "command",
+			server:            config.MCPServer{Command: "mcp-server"},
+			expectedTransport: &transport.Stdio{},
+		},
+		{
+			name:              "http",
+			server:            config.MCPServer{Transport: config.TransportHTTP, URL: "http://localhost/mcp"},
+			expectedTransport: &transport.StreamableHTTP{},
+		},
+		{
+			name:              "sse",
+			server:            config.MCPServer{Transport: config.TransportSSE, URL: "http://localhost/sse"},
+			expectedTransport: &transport.SSE{},
+		},
+		{
+			name:              "stdio with url",
+			server:            config.MCPServer{Transport: config.TransportStdio, Command: "mcp-server", URL: "http://localhost/mcp"},
+			expectedTransport: &transport.Stdio{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _, err := newClient(tc.server, "")
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			if got := c.GetTransport(); reflect.TypeOf(got) != reflect.TypeOf(tc.expectedTransport) {
+				t.Errorf("expected transport %T, got %T", tc.expectedTransport, got)
+			}
+		})
+	}
+}
+
+func TestRegisterServersConfigSSE(t *testing.T) {
+	sseServer := server.NewTestServer(newTestServer("ping"))
+	t.Cleanup(sseServer.Close)
+
+	c := New()
+	t.Cleanup(func() { _ = c.Close() })
+
+	servers := config.MCPServers{
+		"sse": {Transport: config.TransportSSE, URL: sseServer.URL + "/sse"},
+	}
+	err := c.RegisterServersConfig(context.Background(), servers, true)
+	if err != nil {
+		t.Fatalf("failed to register SSE server: %v", err)
+	}
+
+	result, err := c.CallTool(context.Background(), "mcp_sse_ping", nil)
+	if err != nil {
+		t.Fatalf("failed to call tool: %v", err)
+	}
+	if result.Text != "ping" {
+		t.Errorf("expected tool result ping, got %q", result.Text)
+	}
+}
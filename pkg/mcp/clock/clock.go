@@ -0,0 +1,65 @@
+// Package clock provides an MCP server giving the current time, as LLMs do not
+// know it and need it to reason about the age of alerts and logs. The server
+// is meant to be registered in-process with the MCP clients, see
+// client.Clients.RegisterServer.
+package clock
+
+import (
+	"context"
+	"time"
+	// Embed the timezone database, which may be missing from the container
+	// image.
+	_ "time/tzdata"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// GetCurrentTimeToolName is the name of the tool used to get the current time.
+const GetCurrentTimeToolName = "get_current_time"
+
+// Server wraps the core MCP server and provides time-specific functionality.
+type Server struct {
+	*server.MCPServer
+}
+
+// NewServer creates a new MCP server with the current time tool registered.
+func NewServer(name, version string) *Server {
+	mcpServer := server.NewMCPServer(
+		name,
+		version,
+		server.WithToolCapabilities(true),
+	)
+
+	s := &Server{
+		mcpServer,
+	}
+
+	registerHandlers(s)
+
+	return s
+}
+
+// registerHandlers registers the tool handlers for the clock server.
+func registerHandlers(s *Server) {
+	getCurrentTime := mcp.NewTool(GetCurrentTimeToolName,
+		mcp.WithDescription("Get the current date and time"),
+		mcp.WithString("timezone",
+			mcp.Description("IANA name of the timezone to use, e.g. Europe/Berlin, defaults to UTC"),
+		),
+	)
+	s.AddTool(getCurrentTime, s.GetCurrentTime)
+}
+
+// GetCurrentTime is the tool implementation for getting the current time. It
+// returns the current time in RFC 3339 format in the requested timezone.
+func (s *Server) GetCurrentTime(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	timezone := request.GetString("timezone", "UTC")
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return mcp.NewToolResultError("invalid timezone: " + err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(time.Now().In(location).Format(time.RFC3339)), nil
+}
@@ -0,0 +1,121 @@
+package runbook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// fetchTimeout is the timeout of fetching a runbook over HTTP.
+	fetchTimeout = 30 * time.Second
+
+	// maxRunbookSize is the maximum size of a runbook, larger runbooks are
+	// refused to keep the session context small.
+	maxRunbookSize = 1 << 20
+)
+
+// fetchRunbook returns the content of the runbook at the given URL. Local
+// paths and file:// URLs are read from the runbook directory, http(s):// URLs
+// are fetched.
+func (s *Server) fetchRunbook(ctx context.Context, u *url.URL) (string, error) {
+	switch u.Scheme {
+	case "", "file":
+		return s.readRunbook(strings.TrimPrefix(u.Host+u.Path, "/"))
+	case "http", "https":
+		return s.getRunbook(ctx, u.String())
+	}
+
+	return "", fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+}
+
+// readRunbook reads the runbook at the given path, relative to the runbook
+// directory, which it cannot escape.
+func (s *Server) readRunbook(path string) (string, error) {
+	if s.runbookDir == "" {
+		return "", fmt.Errorf("no runbook directory is configured")
+	}
+
+	root, err := os.OpenRoot(s.runbookDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to open runbook directory: %w", err)
+	}
+	defer root.Close()
+
+	f, err := root.Open(filepath.FromSlash(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to open runbook: %w", err)
+	}
+	defer f.Close()
+
+	return readLimited(f)
+}
+
+// getRunbook fetches the runbook at the given HTTP URL. Only textual content
+// is accepted.
+func (s *Server) getRunbook(ctx context.Context, runbookURL string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, runbookURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch runbook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch runbook: %s", resp.Status)
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); !isText(contentType) {
+		return "", fmt.Errorf("unsupported runbook content type %q", contentType)
+	}
+
+	return readLimited(resp.Body)
+}
+
+// readLimited reads a runbook, refusing runbooks larger than the maximum size.
+func readLimited(r io.Reader) (string, error) {
+	content, err := io.ReadAll(io.LimitReader(r, maxRunbookSize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read runbook: %w", err)
+	}
+
+	if len(content) > maxRunbookSize {
+		return "", fmt.Errorf("runbook is larger than %d bytes", maxRunbookSize)
+	}
+
+	return string(content), nil
+}
+
+// isText returns true if the content type is textual, e.g. markdown, HTML or
+// YAML. A missing content type is assumed to be text.
+func isText(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	switch mediaType {
+	case "application/json", "application/xml", "application/yaml", "application/x-yaml":
+		return true
+	}
+
+	return strings.HasPrefix(mediaType, "text/")
+}
@@ -0,0 +1,109 @@
+package runbook
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// frontMatterDelimiter delimits the YAML front matter at the beginning of a
+// markdown runbook.
+const frontMatterDelimiter = "---"
+
+// runbookInfo describes a runbook of the runbook directory.
+type runbookInfo struct {
+	Path        string   `json:"path"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// frontMatter is the YAML front matter of a runbook.
+type frontMatter struct {
+	Title       string   `yaml:"title"`
+	Description string   `yaml:"description"`
+	Tags        []string `yaml:"tags"`
+}
+
+// listRunbooks returns the markdown runbooks found in the given directory and
+// its subdirectories, with the title, description and tags of their front
+// matter.
+// Paths are relative to the directory, as accepted by the get_runbook tool.
+func listRunbooks(dir string) ([]runbookInfo, error) {
+	var runbooks []runbookInfo
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isMarkdown(path) {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		// Runbooks with an invalid front matter are still listed.
+		fm, _ := parseFrontMatter(content)
+		runbooks = append(runbooks, runbookInfo{
+			Path:        filepath.ToSlash(relPath),
+			Title:       fm.Title,
+			Description: fm.Description,
+			Tags:        fm.Tags,
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runbooks: %w", err)
+	}
+
+	return runbooks, nil
+}
+
+// isMarkdown returns true if the file is a markdown file.
+func isMarkdown(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return true
+	}
+
+	return false
+}
+
+// parseFrontMatter parses the YAML front matter of a runbook, delimited by
+// "---" lines at its beginning. An empty front matter is returned if the
+// runbook has none.
+func parseFrontMatter(content []byte) (frontMatter, error) {
+	var fm frontMatter
+
+	content = bytes.TrimPrefix(content, []byte("\ufeff"))
+	lines := strings.SplitAfter(string(content), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != frontMatterDelimiter {
+		return fm, nil
+	}
+
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == frontMatterDelimiter {
+			err := yaml.Unmarshal([]byte(strings.Join(lines[1:i], "")), &fm)
+			if err != nil {
+				return fm, fmt.Errorf("invalid front matter: %w", err)
+			}
+
+			return fm, nil
+		}
+	}
+
+	return fm, fmt.Errorf("unterminated front matter")
+}
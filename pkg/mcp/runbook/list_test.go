@@ -0,0 +1,96 @@
+package runbook
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestListRunbooks(t *testing.T) {
+	s := newTestServer(t, map[string]string{
+		"pods.md":                  "---\ntitle: Pods crashing\ndescription: Investigate crash looping pods\ntags: [pods, crashloop]\n---\n# Pods",
+		"nodes/not-ready.markdown": "---\ntitle: Node not ready\n---\n# Nodes",
+		"no-front-matter.md":       "# No front matter",
+		"invalid.md":               "---\ntitle: [unterminated\n",
+		"notes.txt":                "not a runbook",
+	})
+
+	text, isError := callTool(t, s.ListRunbooks, nil)
+	if isError {
+		t.Fatalf("failed to list runbooks: %s", text)
+	}
+
+	var runbooks []runbookInfo
+	err := json.Unmarshal([]byte(text), &runbooks)
+	if err != nil {
+		t.Fatalf("failed to parse runbooks: %v", err)
+	}
+
+	// Runbooks are listed in lexical order, those without or with an invalid
+	// front matter only by path.
+	expected := []runbookInfo{
+		{Path: "invalid.md"},
+		{Path: "no-front-matter.md"},
+		{Path: "nodes/not-ready.markdown", Title: "Node not ready"},
+		{Path: "pods.md", Title: "Pods crashing", Description: "Investigate crash looping pods", Tags: []string{"pods", "crashloop"}},
+	}
+	if !reflect.DeepEqual(runbooks, expected) {
+		t.Errorf("expected runbooks %+v, got %+v", expected, runbooks)
+	}
+}
+
+func TestListRunbooksWithoutDirectory(t *testing.T) {
+	s := newTestServer(t, nil)
+	s.runbookDir = ""
+
+	text, isError := callTool(t, s.ListRunbooks, nil)
+	if !isError || text != "no runbook directory is configured" {
+		t.Errorf("expected an error without runbook directory, got %q", text)
+	}
+}
+
+func TestParseFrontMatter(t *testing.T) {
+	testCases := []struct {
+		name        string
+		content     string
+		expected    frontMatter
+		expectedErr bool
+	}{
+		{
+			name:     "front matter",
+			content:  "---\ntitle: Title\ntags:\n- a\n---\nbody",
+			expected: frontMatter{Title: "Title", Tags: []string{"a"}},
+		},
+		{
+			name:     "byte order mark",
+			content:  "\ufeff---\ntitle: Title\n---\n",
+			expected: frontMatter{Title: "Title"},
+		},
+		{
+			name:    "no front matter",
+			content: "# Title\n---\n",
+		},
+		{
+			name:        "unterminated",
+			content:     "---\ntitle: Title\n",
+			expectedErr: true,
+		},
+		{
+			name:        "invalid yaml",
+			content:     "---\ntitle: [a\n---\n",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fm, err := parseFrontMatter([]byte(tc.content))
+			if tc.expectedErr != (err != nil) {
+				t.Fatalf("expected error: %t, got %v", tc.expectedErr, err)
+			}
+			if !tc.expectedErr && !reflect.DeepEqual(fm, tc.expected) {
+				t.Errorf("expected front matter %+v, got %+v", tc.expected, fm)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package runbook
+
+import (
+	"strings"
+)
+
+// FindRunbook returns the path, relative to the runbook directory, of the
+// runbook whose front matter tags best match the given alert tags, or an empty
+// string if no runbook shares a tag with the alert. Runbooks are scored by the
+// number of tags they share with the alert, ties are broken by preferring the
+// most specific runbook, i.e. with the fewest tags, then by path.
+func FindRunbook(runbookDir string, tags []string) (string, error) {
+	runbooks, err := listRunbooks(runbookDir)
+	if err != nil {
+		return "", err
+	}
+
+	alertTags := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		alertTags[strings.ToLower(tag)] = true
+	}
+
+	var best runbookInfo
+	bestScore := 0
+	for _, runbook := range runbooks {
+		score := tagOverlap(runbook.Tags, alertTags)
+		if score == 0 {
+			continue
+		}
+
+		if score > bestScore ||
+			score == bestScore && len(runbook.Tags) < len(best.Tags) ||
+			score == bestScore && len(runbook.Tags) == len(best.Tags) && runbook.Path < best.Path {
+			best = runbook
+			bestScore = score
+		}
+	}
+
+	return best.Path, nil
+}
+
+// tagOverlap returns the number of distinct runbook tags found in the alert
+// tags, compared case-insensitively.
+func tagOverlap(runbookTags []string, alertTags map[string]bool) int {
+	seen := make(map[string]bool, len(runbookTags))
+	for _, tag := range runbookTags {
+		tag = strings.ToLower(tag)
+		if alertTags[tag] {
+			seen[tag] = true
+		}
+	}
+
+	return len(seen)
+}
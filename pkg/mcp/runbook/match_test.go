@@ -0,0 +1,106 @@
+package runbook
+
+import (
+	"testing"
+)
+
+func TestFindRunbook(t *testing.T) {
+	s := newTestServer(t, map[string]string{
+		"pods.md":          "---\ntags: [pods, kubernetes]\n---\n",
+		"pods-oom.md":      "---\ntags: [pods, kubernetes, oom]\n---\n",
+		"nodes.md":         "---\ntags: [nodes, kubernetes]\n---\n",
+		"b-network.md":     "---\ntags: [network]\n---\n",
+		"a-network.md":     "---\ntags: [Network]\n---\n",
+		"generic.md":       "---\ntags: [kubernetes, pods, nodes, network]\n---\n",
+		"no-tags.md":       "# No tags",
+		"ignored/pods.txt": "---\ntags: [pods]\n---\n",
+	})
+
+	testCases := []struct {
+		name     string
+		tags     []string
+		expected string
+	}{
+		{
+			name:     "most shared tags",
+			tags:     []string{"pods", "kubernetes", "oom"},
+			expected: "pods-oom.md",
+		},
+		{
+			name:     "fewest tags on equal score",
+			tags:     []string{"pods", "kubernetes"},
+			expected: "pods.md",
+		},
+		{
+			name:     "path on equal score and tags",
+			tags:     []string{"network"},
+			expected: "a-network.md",
+		},
+		{
+			name:     "case insensitive",
+			tags:     []string{"NODES", "Kubernetes"},
+			expected: "nodes.md",
+		},
+		{
+			name:     "duplicate alert tags",
+			tags:     []string{"oom", "oom", "oom"},
+			expected: "pods-oom.md",
+		},
+		{
+			name:     "no match",
+			tags:     []string{"database"},
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path, err := FindRunbook(s.runbookDir, tc.tags)
+			if err != nil {
+				t.Fatalf("failed to find runbook: %v", err)
+			}
+			if path != tc.expected {
+				t.Errorf("expected runbook %q, got %q", tc.expected, path)
+			}
+		})
+	}
+}
+
+func TestFindRunbookForAlertTool(t *testing.T) {
+	s := newTestServer(t, map[string]string{
+		"pods.md": "---\ntags: [pods]\n---\n",
+	})
+
+	testCases := []struct {
+		name          string
+		args          map[string]any
+		expected      string
+		expectedError bool
+	}{
+		{
+			name:     "match",
+			args:     map[string]any{"tags": []any{"pods", "critical"}},
+			expected: "pods.md",
+		},
+		{
+			name:     "no match",
+			args:     map[string]any{"tags": []any{"nodes"}},
+			expected: "No runbook matches the alert tags.",
+		},
+		{
+			name:          "missing tags",
+			args:          map[string]any{},
+			expected:      "tags parameter is required",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			text, isError := callTool(t, s.FindRunbookForAlert, tc.args)
+			if isError != tc.expectedError || text != tc.expected {
+				t.Errorf("expected %q (error: %t), got %q (error: %t)", tc.expected, tc.expectedError, text, isError)
+			}
+		})
+	}
+}
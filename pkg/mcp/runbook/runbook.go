@@ -1,12 +1,13 @@
-// Package runbook provides an MCP server for retrieving runbooks.
+// Package runbook provides an MCP server for retrieving runbooks. The server is
+// meant to be registered in-process with the MCP clients, see
+// client.Clients.RegisterServer.
 package runbook
 
 import (
 	"context"
-	"errors"
-	"log"
+	"encoding/json"
+	"net/http"
 	"net/url"
-	"os"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -14,13 +15,30 @@ import (
 	"github.com/giantswarm/oka/pkg/config"
 )
 
+const (
+	// GetRunbookToolName is the name of the tool used to retrieve a runbook.
+	GetRunbookToolName = "get_runbook"
+
+	// ListRunbooksToolName is the name of the tool used to list the runbooks
+	// of the runbook directory.
+	ListRunbooksToolName = "list_runbooks"
+
+	// FindRunbookForAlertToolName is the name of the tool used to find the
+	// runbook matching the tags of an alert.
+	FindRunbookForAlertToolName = "find_runbook_for_alert"
+)
+
 // Server wraps the core MCP server and provides runbook-specific functionality.
 type Server struct {
 	*server.MCPServer
+
+	httpClient *http.Client
+	runbookDir string
 }
 
-// NewServer creates a new MCP server with the runbook tool registered.
-// It initializes the underlying MCP server and registers the `get_runbook` tool.
+// NewServer creates a new MCP server with the runbook tools registered.
+// It initializes the underlying MCP server and registers the `get_runbook`,
+// `list_runbooks` and `find_runbook_for_alert` tools.
 func NewServer(name, version string, conf *config.Config) *Server {
 	mcpServer := server.NewMCPServer(
 		name,
@@ -29,7 +47,9 @@ func NewServer(name, version string, conf *config.Config) *Server {
 	)
 
 	s := &Server{
-		mcpServer,
+		MCPServer:  mcpServer,
+		httpClient: &http.Client{},
+		runbookDir: conf.RunbookDir,
 	}
 
 	registerHandlers(s)
@@ -37,52 +57,98 @@ func NewServer(name, version string, conf *config.Config) *Server {
 	return s
 }
 
-// Start starts the server, listening on standard input/output.
-// It blocks until the context is canceled or an error occurs.
-func (s Server) Start(ctx context.Context) error {
-	stdioServer := server.NewStdioServer(s.MCPServer)
-
-	// Set the logger for the stdio server.
-	stdioServer.SetErrorLogger(log.Default())
-
-	err := stdioServer.Listen(ctx, os.Stdin, os.Stdout)
-	// Don't return an error if the context was canceled, as it's an expected shutdown.
-	if errors.Is(err, context.Canceled) {
-		return nil
-	}
-
-	return err
-}
-
 // registerHandlers registers the tool handlers for the runbook server.
 func registerHandlers(s *Server) {
-	getRunbook := mcp.NewTool("get_runbook",
+	getRunbook := mcp.NewTool(GetRunbookToolName,
 		mcp.WithDescription("Get the runbook for a specific alert"),
 		mcp.WithString("url",
-			mcp.Description("URL of the runbook"),
+			mcp.Description("URL of the runbook, either http(s):// or a path relative to the runbook directory"),
 			mcp.Required(),
 		),
 	)
 	s.AddTool(getRunbook, s.GetRunbook)
 
+	listRunbooks := mcp.NewTool(ListRunbooksToolName,
+		mcp.WithDescription("List the available runbooks with their title and description, to find the runbook matching an alert"),
+	)
+	s.AddTool(listRunbooks, s.ListRunbooks)
+
+	findRunbookForAlert := mcp.NewTool(FindRunbookForAlertToolName,
+		mcp.WithDescription("Find the runbook whose tags best match the tags of an alert, returns its path to use with get_runbook"),
+		mcp.WithArray("tags",
+			mcp.Description("Tags of the alert"),
+			mcp.Required(),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	)
+	s.AddTool(findRunbookForAlert, s.FindRunbookForAlert)
 }
 
 // GetRunbook is the tool implementation for retrieving a runbook.
 // It takes a URL as input and returns the content of the corresponding runbook
-// file.
+// file, read from the runbook directory for local paths and file:// URLs or
+// fetched for http(s):// URLs.
 func (s *Server) GetRunbook(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	requestURL := request.GetString("url", "")
 	if requestURL == "" {
 		return mcp.NewToolResultError("url parameter is required"), nil
 	}
 
-	_, err := url.Parse(requestURL)
+	u, err := url.Parse(requestURL)
 	if err != nil {
 		return mcp.NewToolResultError("invalid URL format: " + err.Error()), nil
 	}
 
-	// TODO: implement the logic to retrieve the runbook content from the URL.
-	content := ""
+	content, err := s.fetchRunbook(ctx, u)
+	if err != nil {
+		return mcp.NewToolResultError("failed to get runbook: " + err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(content), nil
+}
+
+// ListRunbooks is the tool implementation for listing the runbooks. It returns
+// the markdown runbooks of the runbook directory as JSON, with their path and
+// the title, description and tags of their front matter.
+func (s *Server) ListRunbooks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.runbookDir == "" {
+		return mcp.NewToolResultError("no runbook directory is configured"), nil
+	}
+
+	runbooks, err := listRunbooks(s.runbookDir)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	content, err := json.Marshal(runbooks)
+	if err != nil {
+		return mcp.NewToolResultError("failed to marshal runbooks: " + err.Error()), nil
+	}
 
 	return mcp.NewToolResultText(string(content)), nil
 }
+
+// FindRunbookForAlert is the tool implementation for finding the runbook of an
+// alert. It takes the alert tags as input and returns the path of the runbook
+// whose tags best match them, see FindRunbook.
+func (s *Server) FindRunbookForAlert(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	tags := request.GetStringSlice("tags", nil)
+	if len(tags) == 0 {
+		return mcp.NewToolResultError("tags parameter is required"), nil
+	}
+
+	if s.runbookDir == "" {
+		return mcp.NewToolResultError("no runbook directory is configured"), nil
+	}
+
+	path, err := FindRunbook(s.runbookDir, tags)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if path == "" {
+		return mcp.NewToolResultText("No runbook matches the alert tags."), nil
+	}
+
+	return mcp.NewToolResultText(path), nil
+}
@@ -0,0 +1,181 @@
+package runbook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+// newTestServer returns a runbook server reading the runbooks, keyed by path,
+// written to a temporary runbook directory.
+func newTestServer(t *testing.T, runbooks map[string]string) *Server {
+	t.Helper()
+
+	dir := t.TempDir()
+	for path, content := range runbooks {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			t.Fatalf("failed to create runbook directory: %v", err)
+		}
+		err = os.WriteFile(path, []byte(content), 0600)
+		if err != nil {
+			t.Fatalf("failed to write runbook: %v", err)
+		}
+	}
+
+	return NewServer("runbook", "test", &config.Config{RunbookDir: dir})
+}
+
+// callTool calls the tool handler with the arguments and returns the text of
+// its result, along with whether it is an error.
+func callTool(t *testing.T, handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]any) (string, bool) {
+	t.Helper()
+
+	var request mcp.CallToolRequest
+	request.Params.Arguments = args
+
+	result, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("failed to call tool: %v", err)
+	}
+
+	var text strings.Builder
+	for _, content := range result.Content {
+		if c, ok := content.(mcp.TextContent); ok {
+			text.WriteString(c.Text)
+		}
+	}
+
+	return text.String(), result.IsError
+}
+
+func TestGetRunbook(t *testing.T) {
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/runbook.md":
+			w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+			_, _ = w.Write([]byte("# Remote runbook"))
+		case "/large.md":
+			w.Header().Set("Content-Type", "text/markdown")
+			_, _ = w.Write([]byte(strings.Repeat("x", maxRunbookSize+1)))
+		case "/image.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("png"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(httpServer.Close)
+
+	s := newTestServer(t, map[string]string{
+		"alerts/pods.md": "# Pods runbook",
+		"large.md":       strings.Repeat("x", maxRunbookSize+1),
+	})
+
+	testCases := []struct {
+		name          string
+		url           string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "local path",
+			url:      "alerts/pods.md",
+			expected: "# Pods runbook",
+		},
+		{
+			name:     "file url",
+			url:      "file:///alerts/pods.md",
+			expected: "# Pods runbook",
+		},
+		{
+			name:          "local path outside the runbook directory",
+			url:           "../secret.md",
+			expectedError: "failed to open runbook",
+		},
+		{
+			name:          "missing local file",
+			url:           "missing.md",
+			expectedError: "failed to open runbook",
+		},
+		{
+			name:          "oversized local file",
+			url:           "large.md",
+			expectedError: "runbook is larger than",
+		},
+		{
+			name:     "http url",
+			url:      httpServer.URL + "/runbook.md",
+			expected: "# Remote runbook",
+		},
+		{
+			name:          "http not found",
+			url:           httpServer.URL + "/missing.md",
+			expectedError: "404 Not Found",
+		},
+		{
+			name:          "oversized http body",
+			url:           httpServer.URL + "/large.md",
+			expectedError: "runbook is larger than",
+		},
+		{
+			name:          "unsupported content type",
+			url:           httpServer.URL + "/image.png",
+			expectedError: `unsupported runbook content type "image/png"`,
+		},
+		{
+			name:          "unsupported scheme",
+			url:           "ftp://example.com/runbook.md",
+			expectedError: `unsupported URL scheme "ftp"`,
+		},
+		{
+			name:          "missing url",
+			url:           "",
+			expectedError: "url parameter is required",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			text, isError := callTool(t, s.GetRunbook, map[string]any{"url": tc.url})
+			if tc.expectedError != "" {
+				if !isError || !strings.Contains(text, tc.expectedError) {
+					t.Errorf("expected an error containing %q, got %q", tc.expectedError, text)
+				}
+				return
+			}
+
+			if isError || text != tc.expected {
+				t.Errorf("expected runbook %q, got %q", tc.expected, text)
+			}
+		})
+	}
+}
+
+func TestIsText(t *testing.T) {
+	testCases := map[string]bool{
+		"":                         true,
+		"text/markdown":            true,
+		"text/html; charset=utf-8": true,
+		"application/json":         true,
+		"application/x-yaml":       true,
+		"application/octet-stream": false,
+		"image/png":                false,
+		"invalid content type; a=": false,
+	}
+
+	for contentType, expected := range testCases {
+		if result := isText(contentType); result != expected {
+			t.Errorf("expected isText(%q) to be %t, got %t", contentType, expected, result)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Package metrics provides the Prometheus metrics of OKA and the server
+// exposing them.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// namespace is the prefix of the metric names.
+const namespace = "oka"
+
+// Registry is the registry of the OKA metrics, along with the Go runtime and
+// process metrics.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	// AlertsFetched counts the alerts returned by the OpsGenie query.
+	AlertsFetched = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "alerts_fetched_total",
+		Help:      "Number of alerts returned by the OpsGenie query.",
+	})
+	// AlertFetchErrors counts the failed OpsGenie queries.
+	AlertFetchErrors = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "alert_fetch_errors_total",
+		Help:      "Number of failed OpsGenie queries.",
+	})
+
+	// SessionsStarted counts the sessions started.
+	SessionsStarted = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sessions_started_total",
+		Help:      "Number of sessions started.",
+	})
+	// SessionsCompleted counts the sessions completed, by outcome.
+	SessionsCompleted = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sessions_completed_total",
+		Help:      "Number of sessions completed, by outcome.",
+	}, []string{"outcome"})
+	// SessionsFailed counts the sessions which failed before completing the
+	// investigation.
+	SessionsFailed = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sessions_failed_total",
+		Help:      "Number of sessions which failed before completing the investigation.",
+	})
+
+	// ToolCalls counts the tool calls, by tool and status, "success",
+	// "error", "denied" or "cached" for the repeated calls.
+	ToolCalls = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_calls_total",
+		Help:      "Number of tool calls, by tool and status.",
+	}, []string{"tool", "status"})
+
+	// LLMCallDuration observes the duration of the successful LLM calls,
+	// retries included, by model.
+	LLMCallDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "llm_call_duration_seconds",
+		Help:      "Duration of the successful LLM calls, retries included, by model.",
+		Buckets:   []float64{1, 2.5, 5, 10, 20, 40, 80, 160, 320},
+	}, []string{"model"})
+	// LLMTokens counts the tokens used by the LLM calls, by model and type,
+	// "prompt" or "completion".
+	LLMTokens = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_tokens_total",
+		Help:      "Number of tokens used by the LLM calls, by model and type.",
+	}, []string{"model", "type"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
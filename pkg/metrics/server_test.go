@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// freeAddress returns a local address with a free port.
+func freeAddress(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().String()
+}
+
+func TestServe(t *testing.T) {
+	address := freeAddress(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(ctx, address)
+	}()
+
+	AlertsFetched.Add(3)
+	SessionsCompleted.WithLabelValues("resolved").Inc()
+	ToolCalls.WithLabelValues("mcp_test_echo", "success").Inc()
+
+	// Scrape the metrics once the server is listening.
+	var body string
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get("http://" + address + "/metrics")
+		if err == nil {
+			b, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			body = string(b)
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("failed to scrape metrics: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, expected := range []string{
+		"oka_alerts_fetched_total ",
+		`oka_sessions_completed_total{outcome="resolved"} `,
+		`oka_tool_calls_total{status="success",tool="mcp_test_echo"} `,
+		"go_goroutines ",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected the metrics to contain %q", expected)
+		}
+	}
+
+	// The server shuts down with the context.
+	cancel()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("expected the server to shut down cleanly, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the server to shut down once the context is canceled")
+	}
+}
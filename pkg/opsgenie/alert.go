@@ -8,6 +8,8 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
 	"github.com/opsgenie/opsgenie-go-sdk-v2/client"
@@ -24,12 +26,23 @@ const (
 	// This limit is enforced by the OpsGenie API.
 	// Reference: https://docs.opsgenie.com/docs/alert-api#list-alerts
 	maxTotalAlerts = 20000
+
+	// maxNotesPerRequest is the maximum number of alert notes that can be
+	// fetched in a single API request.
+	// Reference: https://docs.opsgenie.com/docs/alert-api-continued#list-alert-notes
+	maxNotesPerRequest = 100
+
+	// maxTotalNotes is the maximum total number of notes fetched for an alert.
+	maxTotalNotes = 500
 )
 
 // AlertClient is a wrapper around the OpsGenie alert client that provides
 // enhanced functionality for fetching and managing alerts.
 type AlertClient struct {
 	*alert.Client
+
+	maxRetries   int
+	retryBackoff time.Duration
 }
 
 // NewAlertClient creates a new AlertClient instance configured with the provided API URL and API key.
@@ -38,11 +51,31 @@ type AlertClient struct {
 // Parameters:
 //   - apiUrl: The OpsGenie API URL endpoint (e.g., "https://api.opsgenie.com")
 //   - envVar: The name of the environment variable containing the API key
+//   - maxRetries: The number of times transient failures of listing and getting alerts are retried
+//   - retryBackoff: The initial delay between two attempts, growing exponentially
 //
 // Returns:
 //   - *AlertClient: A configured alert client ready for use
 //   - error: An error if the client creation fails or if the API key is missing
-func NewAlertClient(apiUrl, envVar string) (*AlertClient, error) {
+func NewAlertClient(apiUrl, envVar string, maxRetries int, retryBackoff time.Duration) (*AlertClient, error) {
+	alertClient, err := alert.NewClient(newClientConfig(apiUrl, envVar))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OpsGenie alert client: %w", err)
+	}
+
+	a := &AlertClient{
+		Client:       alertClient,
+		maxRetries:   maxRetries,
+		retryBackoff: retryBackoff,
+	}
+
+	return a, nil
+}
+
+// newClientConfig returns the configuration of the OpsGenie clients, using the
+// API key retrieved from the envVar environment variable. The clients' logs are
+// discarded, and network errors are not retried by the SDK, see retryPolicy.
+func newClientConfig(apiUrl, envVar string) *client.Config {
 	logger := logrus.New()
 	logger.Out = io.Discard
 
@@ -50,18 +83,10 @@ func NewAlertClient(apiUrl, envVar string) (*AlertClient, error) {
 		OpsGenieAPIURL: client.ApiUrl(apiUrl),
 		ApiKey:         os.Getenv(envVar),
 		Logger:         logger,
+		RetryPolicy:    retryPolicy,
 	}
 
-	alertClient, err := alert.NewClient(config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create OpsGenie alert client: %w", err)
-	}
-
-	a := &AlertClient{
-		Client: alertClient,
-	}
-
-	return a, nil
+	return config
 }
 
 // ListAlerts retrieves alerts from OpsGenie based on the provided query string.
@@ -104,7 +129,9 @@ func (a *AlertClient) ListAlerts(ctx context.Context, query string) ([]alert.Ale
 			Query:  query,
 		}
 
-		response, err := a.Client.List(ctx, listRequest)
+		response, err := retry(ctx, a.maxRetries, a.retryBackoff, func() (*alert.ListAlertResult, error) {
+			return a.Client.List(ctx, listRequest)
+		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to list alerts: %w", err)
 		}
@@ -134,19 +161,21 @@ func (a *AlertClient) ListAlerts(ctx context.Context, query string) ([]alert.Ale
 //   - *alert.GetAlertResult: The alert details
 //   - error: An error if the API request fails
 func (a *AlertClient) GetAlert(ctx context.Context, id string) (*alert.GetAlertResult, error) {
-	slog.Debug("fetching alert", "id", id)
+	slog.Debug("fetching alert", "alert.id", id)
 
 	getRequest := &alert.GetAlertRequest{
 		IdentifierValue: id,
 		IdentifierType:  alert.ALERTID,
 	}
 
-	response, err := a.Client.Get(ctx, getRequest)
+	response, err := retry(ctx, a.maxRetries, a.retryBackoff, func() (*alert.GetAlertResult, error) {
+		return a.Client.Get(ctx, getRequest)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get alert with ID %s: %w", id, err)
 	}
 
-	slog.Debug("fetched alert", "id", response.Id)
+	slog.Debug("fetched alert", "alert.id", response.Id)
 
 	return response, nil
 }
@@ -164,7 +193,7 @@ func (a *AlertClient) GetAlert(ctx context.Context, id string) (*alert.GetAlertR
 //   - *alert.AcknowledgeResult: The result of the acknowledgement operation
 //   - error: An error if the API request fails or the context is cancelled
 func (a *AlertClient) AcknowledgeAlert(ctx context.Context, id, user, note, source string) (*alert.RequestStatusResult, error) {
-	slog.Debug("acknowledging alert", "id", id, "user", user, "source", source)
+	slog.Debug("acknowledging alert", "alert.id", id, "user", user, "source", source)
 
 	ackRequest := &alert.AcknowledgeAlertRequest{
 		IdentifierValue: id,
@@ -188,7 +217,7 @@ func (a *AlertClient) AcknowledgeAlert(ctx context.Context, id, user, note, sour
 		return nil, fmt.Errorf("failed to acknowledge alert with ID %s: %s", id, result.Status)
 	}
 
-	slog.Debug("acknowledged alert", "id", id, "requestId", result.RequestId)
+	slog.Debug("acknowledged alert", "alert.id", id, "requestId", result.RequestId)
 
 	return result, nil
 }
@@ -206,7 +235,7 @@ func (a *AlertClient) AcknowledgeAlert(ctx context.Context, id, user, note, sour
 //   - *alert.RequestStatusResult: The result of the unacknowledgement operation
 //   - error: An error if the API request fails or the context is cancelled
 func (a *AlertClient) UnacknowledgeAlert(ctx context.Context, id, user, note, source string) (*alert.RequestStatusResult, error) {
-	slog.Debug("unacknowledging alert", "id", id, "user", user, "source", source)
+	slog.Debug("unacknowledging alert", "alert.id", id, "user", user, "source", source)
 
 	unackRequest := &alert.UnacknowledgeAlertRequest{
 		IdentifierValue: id,
@@ -230,7 +259,351 @@ func (a *AlertClient) UnacknowledgeAlert(ctx context.Context, id, user, note, so
 		return nil, fmt.Errorf("failed to unacknowledge alert with ID %s: %s", id, result.Status)
 	}
 
-	slog.Debug("unacknowledged alert", "id", id, "requestId", result.RequestId)
+	slog.Debug("unacknowledged alert", "alert.id", id, "requestId", result.RequestId)
+
+	return result, nil
+}
+
+// ListAlertNotes retrieves the notes of an alert from OpsGenie, oldest first.
+// The method handles pagination automatically, fetching up to maxTotalNotes
+// notes.
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout control
+//   - id: The identifier of the alert whose notes to retrieve
+//
+// Returns:
+//   - []alert.AlertNote: The notes of the alert
+//   - error: An error if the API request fails
+func (a *AlertClient) ListAlertNotes(ctx context.Context, id string) ([]alert.AlertNote, error) {
+	slog.Debug("fetching alert notes", "alert.id", id)
+
+	notes := make([]alert.AlertNote, 0)
+	offset := ""
+
+	for len(notes) < maxTotalNotes {
+		listRequest := &alert.ListAlertNotesRequest{
+			IdentifierValue: id,
+			IdentifierType:  alert.ALERTID,
+			Offset:          offset,
+			Direction:       alert.NEXT,
+			Order:           alert.Asc,
+			Limit:           maxNotesPerRequest,
+		}
+
+		response, err := a.Client.ListAlertNotes(ctx, listRequest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list notes of alert with ID %s: %w", id, err)
+		}
+
+		notes = append(notes, response.AlertLog...)
+
+		// A partial page means there are no more notes.
+		if len(response.AlertLog) < maxNotesPerRequest {
+			break
+		}
+		offset = response.AlertLog[len(response.AlertLog)-1].Offset
+	}
+
+	slog.Debug("fetched alert notes", "alert.id", id, "count", len(notes))
+
+	return notes, nil
+}
+
+// CloseAlert closes an alert in OpsGenie.
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout control
+//   - id: The identifier of the alert to close
+//   - user: Display name of the request owner
+//   - note: Additional note to add to the alert
+//   - source: Display name of the request source
+//
+// Returns:
+//   - *alert.RequestStatusResult: The result of the close operation
+//   - error: An error if the API request fails or the context is cancelled
+func (a *AlertClient) CloseAlert(ctx context.Context, id, user, note, source string) (*alert.RequestStatusResult, error) {
+	slog.Debug("closing alert", "alert.id", id, "user", user, "source", source)
+
+	closeRequest := &alert.CloseAlertRequest{
+		IdentifierValue: id,
+		IdentifierType:  alert.ALERTID,
+		User:            user,
+		Note:            note,
+		Source:          source,
+	}
+
+	response, err := a.Client.Close(ctx, closeRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to close alert with ID %s: %w", id, err)
+	}
+
+	result, err := response.RetrieveStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve status of close request: %w", err)
+	}
+
+	if !result.IsSuccess {
+		return nil, fmt.Errorf("failed to close alert with ID %s: %s", id, result.Status)
+	}
+
+	slog.Debug("closed alert", "alert.id", id, "requestId", result.RequestId)
+
+	return result, nil
+}
+
+// AddNote adds a note to an alert in OpsGenie.
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout control
+//   - id: The identifier of the alert to add the note to
+//   - user: Display name of the request owner
+//   - note: The note to add to the alert
+//   - source: Display name of the request source
+//
+// Returns:
+//   - *alert.RequestStatusResult: The result of the add note operation
+//   - error: An error if the API request fails or the context is cancelled
+func (a *AlertClient) AddNote(ctx context.Context, id, user, note, source string) (*alert.RequestStatusResult, error) {
+	slog.Debug("adding note to alert", "alert.id", id, "user", user, "source", source)
+
+	noteRequest := &alert.AddNoteRequest{
+		IdentifierValue: id,
+		IdentifierType:  alert.ALERTID,
+		User:            user,
+		Note:            note,
+		Source:          source,
+	}
+
+	response, err := a.Client.AddNote(ctx, noteRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add note to alert with ID %s: %w", id, err)
+	}
+
+	result, err := response.RetrieveStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve status of add note request: %w", err)
+	}
+
+	if !result.IsSuccess {
+		return nil, fmt.Errorf("failed to add note to alert with ID %s: %s", id, result.Status)
+	}
+
+	slog.Debug("added note to alert", "alert.id", id, "requestId", result.RequestId)
+
+	return result, nil
+}
+
+// AssignAlert assigns an alert in OpsGenie to a user.
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout control
+//   - id: The identifier of the alert to assign
+//   - ownerID: The identifier of the user to assign the alert to
+//   - user: Display name of the request owner
+//   - source: Display name of the request source
+//
+// Returns:
+//   - *alert.RequestStatusResult: The result of the assign operation
+//   - error: An error if the API request fails or the context is cancelled
+func (a *AlertClient) AssignAlert(ctx context.Context, id, ownerID, user, source string) (*alert.RequestStatusResult, error) {
+	slog.Debug("assigning alert", "alert.id", id, "owner", ownerID, "user", user, "source", source)
+
+	assignRequest := &alert.AssignRequest{
+		IdentifierValue: id,
+		IdentifierType:  alert.ALERTID,
+		Owner:           alert.User{ID: ownerID},
+		User:            user,
+		Source:          source,
+	}
+
+	response, err := a.Client.AssignAlert(ctx, assignRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign alert with ID %s: %w", id, err)
+	}
+
+	result, err := response.RetrieveStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve status of assign request: %w", err)
+	}
+
+	if !result.IsSuccess {
+		return nil, fmt.Errorf("failed to assign alert with ID %s: %s", id, result.Status)
+	}
+
+	slog.Debug("assigned alert", "alert.id", id, "owner", ownerID, "requestId", result.RequestId)
+
+	return result, nil
+}
+
+// EscalateAlert escalates an alert in OpsGenie to the next level of an
+// escalation.
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout control
+//   - id: The identifier of the alert to escalate
+//   - escalationID: The identifier of the escalation to escalate the alert with
+//   - user: Display name of the request owner
+//   - source: Display name of the request source
+//
+// Returns:
+//   - *alert.RequestStatusResult: The result of the escalate operation
+//   - error: An error if the API request fails or the context is cancelled
+func (a *AlertClient) EscalateAlert(ctx context.Context, id, escalationID, user, source string) (*alert.RequestStatusResult, error) {
+	slog.Debug("escalating alert", "alert.id", id, "escalation", escalationID, "user", user, "source", source)
+
+	escalateRequest := &alert.EscalateToNextRequest{
+		IdentifierValue: id,
+		IdentifierType:  alert.ALERTID,
+		Escalation:      alert.Escalation{ID: escalationID},
+		User:            user,
+		Source:          source,
+	}
+
+	response, err := a.Client.EscalateToNext(ctx, escalateRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to escalate alert with ID %s: %w", id, err)
+	}
+
+	result, err := response.RetrieveStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve status of escalate request: %w", err)
+	}
+
+	if !result.IsSuccess {
+		return nil, fmt.Errorf("failed to escalate alert with ID %s: %s", id, result.Status)
+	}
+
+	slog.Debug("escalated alert", "alert.id", id, "escalation", escalationID, "requestId", result.RequestId)
+
+	return result, nil
+}
+
+// SnoozeAlert snoozes an alert in OpsGenie until the given time.
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout control
+//   - id: The identifier of the alert to snooze
+//   - until: The time at which the alert is not snoozed anymore
+//   - user: Display name of the request owner
+//   - note: Additional note to add to the alert
+//   - source: Display name of the request source
+//
+// Returns:
+//   - *alert.RequestStatusResult: The result of the snooze operation
+//   - error: An error if the API request fails or the context is cancelled
+func (a *AlertClient) SnoozeAlert(ctx context.Context, id string, until time.Time, user, note, source string) (*alert.RequestStatusResult, error) {
+	slog.Debug("snoozing alert", "alert.id", id, "until", until, "user", user, "source", source)
+
+	snoozeRequest := &alert.SnoozeAlertRequest{
+		IdentifierValue: id,
+		IdentifierType:  alert.ALERTID,
+		EndTime:         until,
+		User:            user,
+		Note:            note,
+		Source:          source,
+	}
+
+	response, err := a.Client.Snooze(ctx, snoozeRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snooze alert with ID %s: %w", id, err)
+	}
+
+	result, err := response.RetrieveStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve status of snooze request: %w", err)
+	}
+
+	if !result.IsSuccess {
+		return nil, fmt.Errorf("failed to snooze alert with ID %s: %s", id, result.Status)
+	}
+
+	slog.Debug("snoozed alert", "alert.id", id, "until", until, "requestId", result.RequestId)
+
+	return result, nil
+}
+
+// AddTags adds tags to an alert in OpsGenie.
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout control
+//   - id: The identifier of the alert to add the tags to
+//   - tags: The tags to add to the alert
+//   - user: Display name of the request owner
+//   - source: Display name of the request source
+//
+// Returns:
+//   - *alert.RequestStatusResult: The result of the add tags operation
+//   - error: An error if the API request fails or the context is cancelled
+func (a *AlertClient) AddTags(ctx context.Context, id string, tags []string, user, source string) (*alert.RequestStatusResult, error) {
+	slog.Debug("adding tags to alert", "alert.id", id, "tags", tags, "user", user, "source", source)
+
+	tagsRequest := &alert.AddTagsRequest{
+		IdentifierValue: id,
+		IdentifierType:  alert.ALERTID,
+		Tags:            tags,
+		User:            user,
+		Source:          source,
+	}
+
+	response, err := a.Client.AddTags(ctx, tagsRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add tags to alert with ID %s: %w", id, err)
+	}
+
+	result, err := response.RetrieveStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve status of add tags request: %w", err)
+	}
+
+	if !result.IsSuccess {
+		return nil, fmt.Errorf("failed to add tags to alert with ID %s: %s", id, result.Status)
+	}
+
+	slog.Debug("added tags to alert", "alert.id", id, "requestId", result.RequestId)
+
+	return result, nil
+}
+
+// RemoveTags removes tags from an alert in OpsGenie.
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout control
+//   - id: The identifier of the alert to remove the tags from
+//   - tags: The tags to remove from the alert, which cannot contain commas
+//   - user: Display name of the request owner
+//   - source: Display name of the request source
+//
+// Returns:
+//   - *alert.RequestStatusResult: The result of the remove tags operation
+//   - error: An error if the API request fails or the context is cancelled
+func (a *AlertClient) RemoveTags(ctx context.Context, id string, tags []string, user, source string) (*alert.RequestStatusResult, error) {
+	slog.Debug("removing tags from alert", "alert.id", id, "tags", tags, "user", user, "source", source)
+
+	// The tags to remove are given as a comma-separated list.
+	tagsRequest := &alert.RemoveTagsRequest{
+		IdentifierValue: id,
+		IdentifierType:  alert.ALERTID,
+		Tags:            strings.Join(tags, ","),
+		User:            user,
+		Source:          source,
+	}
+
+	response, err := a.Client.RemoveTags(ctx, tagsRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove tags from alert with ID %s: %w", id, err)
+	}
+
+	result, err := response.RetrieveStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve status of remove tags request: %w", err)
+	}
+
+	if !result.IsSuccess {
+		return nil, fmt.Errorf("failed to remove tags from alert with ID %s: %s", id, result.Status)
+	}
+
+	slog.Debug("removed tags from alert", "alert.id", id, "requestId", result.RequestId)
 
 	return result, nil
 }
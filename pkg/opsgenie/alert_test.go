@@ -0,0 +1,136 @@
+package opsgenie
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+)
+
+// alertAction is an action on an alert performed by the alert client.
+type alertAction struct {
+	name string
+	path string
+	do   func(ctx context.Context, c *AlertClient) (*alert.RequestStatusResult, error)
+	// expectedBody is a field of the request body expected to be set.
+	expectedBody string
+}
+
+// snoozeUntil is the end of the snooze window of the snooze action, which
+// OpsGenie requires to be in the future.
+var snoozeUntil = time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+// alertActions are the actions on an alert tested against the fake OpsGenie
+// API.
+var alertActions = []alertAction{
+	{
+		name: "close",
+		path: "POST /v2/alerts/alert-1/close",
+		do: func(ctx context.Context, c *AlertClient) (*alert.RequestStatusResult, error) {
+			return c.CloseAlert(ctx, "alert-1", "oka", "Resolved by restarting the pod", "OKA")
+		},
+		expectedBody: `"note":"Resolved by restarting the pod"`,
+	},
+	{
+		name: "assign",
+		path: "POST /v2/alerts/alert-1/assign",
+		do: func(ctx context.Context, c *AlertClient) (*alert.RequestStatusResult, error) {
+			return c.AssignAlert(ctx, "alert-1", "user-1", "oka", "OKA")
+		},
+		expectedBody: `"owner":{"id":"user-1"}`,
+	},
+	{
+		name: "escalate",
+		path: "POST /v2/alerts/alert-1/escalate",
+		do: func(ctx context.Context, c *AlertClient) (*alert.RequestStatusResult, error) {
+			return c.EscalateAlert(ctx, "alert-1", "escalation-1", "oka", "OKA")
+		},
+		expectedBody: `"escalation":{"id":"escalation-1"}`,
+	},
+	{
+		name: "snooze",
+		path: "POST /v2/alerts/alert-1/snooze",
+		do: func(ctx context.Context, c *AlertClient) (*alert.RequestStatusResult, error) {
+			return c.SnoozeAlert(ctx, "alert-1", snoozeUntil, "oka", "Flapping", "OKA")
+		},
+		expectedBody: `"endTime":"` + snoozeUntil.Format(time.RFC3339) + `"`,
+	},
+}
+
+func TestAlertActions(t *testing.T) {
+	testCases := []struct {
+		name          string
+		actionFails   bool
+		statusSuccess bool
+		expectedErr   string
+	}{
+		{
+			name:          "success",
+			statusSuccess: true,
+		},
+		{
+			name:        "request rejected",
+			actionFails: true,
+			expectedErr: "Alert does not exist",
+		},
+		{
+			name:          "request failed",
+			statusSuccess: false,
+			expectedErr:   "alert-1: Alert is not in a valid state",
+		},
+	}
+
+	for _, action := range alertActions {
+		for _, tc := range testCases {
+			t.Run(action.name+" "+tc.name, func(t *testing.T) {
+				var body string
+				fake := newFakeOpsGenie(t)
+				if tc.actionFails {
+					fake.handleError(action.path, http.StatusNotFound, "Alert does not exist")
+				} else {
+					fake.handle(action.path, func(r *http.Request) any {
+						var b json.RawMessage
+						_ = json.NewDecoder(r.Body).Decode(&b)
+						body = string(b)
+						return nil
+					})
+				}
+				fake.handle("GET /v2/alerts/requests/test", func(r *http.Request) any {
+					if !tc.statusSuccess {
+						return alert.RequestStatusResult{IsSuccess: false, Status: "Alert is not in a valid state"}
+					}
+					return alert.RequestStatusResult{IsSuccess: true, Status: "Processed", AlertID: "alert-1"}
+				})
+
+				alertClient, err := NewAlertClient(fake.apiURL(), fakeAPIKeyEnvVar, 0, time.Millisecond)
+				if err != nil {
+					t.Fatalf("failed to create alert client: %v", err)
+				}
+
+				result, err := action.do(context.Background(), alertClient)
+				if tc.expectedErr != "" {
+					if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+						t.Errorf("expected error containing %q, got %v", tc.expectedErr, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("failed to %s alert: %v", action.name, err)
+				}
+
+				if !result.IsSuccess || result.AlertID != "alert-1" {
+					t.Errorf("expected a successful request status, got %+v", result)
+				}
+				for _, expected := range []string{action.expectedBody, `"user":"oka"`, `"source":"OKA"`} {
+					if !strings.Contains(body, expected) {
+						t.Errorf("expected request body containing %s, got %s", expected, body)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package opsgenie
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeAPIKeyEnvVar is the environment variable holding the API key of the
+// clients of the fake OpsGenie API.
+const fakeAPIKeyEnvVar = "OKA_TEST_OPSGENIE_API_KEY"
+
+// fakeOpsGenie is a fake OpsGenie API, serving the data returned by the
+// handlers registered with handle and recording the requests it receives.
+type fakeOpsGenie struct {
+	server *httptest.Server
+	mux    *http.ServeMux
+
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+// newFakeOpsGenie starts a fake OpsGenie API, stopped at the end of the test.
+func newFakeOpsGenie(t *testing.T) *fakeOpsGenie {
+	t.Helper()
+	t.Setenv(fakeAPIKeyEnvVar, "test")
+
+	f := &fakeOpsGenie{
+		mux: http.NewServeMux(),
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.requests = append(f.requests, r)
+		f.mu.Unlock()
+
+		f.mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(f.server.Close)
+
+	return f
+}
+
+// apiURL returns the API URL of the fake, the OpsGenie clients use plain HTTP
+// for hosts which are not OpsGenie API hosts.
+func (f *fakeOpsGenie) apiURL() string {
+	return strings.TrimPrefix(f.server.URL, "http://")
+}
+
+// handle registers the handler of the pattern, its result is served as the
+// data of the response.
+func (f *fakeOpsGenie) handle(pattern string, handler func(r *http.Request) any) {
+	f.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "test")
+		w.Header().Set("X-Response-Time", "0.01")
+		w.Header().Set("X-RateLimit-State", "OK")
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data":      handler(r),
+			"took":      0.01,
+			"requestId": "test",
+		})
+	})
+}
+
+// handleError registers an error response with the status code and message
+// for the pattern.
+func (f *fakeOpsGenie) handleError(pattern string, statusCode int, message string) {
+	f.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"message":   message,
+			"took":      0.01,
+			"requestId": "test",
+		})
+	})
+}
+
+// queries returns the query parameters of the requests to the path.
+func (f *fakeOpsGenie) queries(path string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var queries []string
+	for _, r := range f.requests {
+		if r.URL.Path == path {
+			queries = append(queries, r.URL.Query().Get("query"))
+		}
+	}
+
+	return queries
+}
@@ -0,0 +1,196 @@
+package opsgenie
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+	"github.com/opsgenie/opsgenie-go-sdk-v2/incident"
+)
+
+const (
+	// maxIncidentsPerRequest is the maximum number of incidents that can be
+	// fetched in a single API request.
+	// Reference: https://docs.opsgenie.com/docs/incident-api#list-incidents
+	maxIncidentsPerRequest = 100
+)
+
+// IncidentClient is a wrapper around the OpsGenie incident client that
+// provides functionality for listing the open incidents and correlating alerts
+// with their incidents.
+type IncidentClient struct {
+	*incident.Client
+
+	// alerts caches the alerts of the open incidents across polls, keyed by
+	// incident ID.
+	mu     sync.Mutex
+	alerts map[string]incidentAlerts
+}
+
+// incidentAlerts are the alerts of an incident, as of its last update.
+type incidentAlerts struct {
+	updatedAt time.Time
+	alertIDs  []string
+}
+
+// IncidentAlert is an alert investigated along with the other alerts of its
+// incident. It marshals to the alert's JSON enriched with the incident's
+// fields.
+type IncidentAlert struct {
+	*alert.GetAlertResult
+
+	IncidentID     string         `json:"incidentId"`
+	IncidentAlerts []AlertSummary `json:"incidentAlerts"`
+}
+
+// AlertSummary summarizes an alert of an incident.
+type AlertSummary struct {
+	Id        string         `json:"id"`
+	Message   string         `json:"message"`
+	Priority  alert.Priority `json:"priority"`
+	Status    string         `json:"status"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+// NewIncidentClient creates a new IncidentClient instance configured with the
+// provided API URL and the API key retrieved from the envVar environment
+// variable.
+func NewIncidentClient(apiUrl, envVar string) (*IncidentClient, error) {
+	incidentClient, err := incident.NewClient(newClientConfig(apiUrl, envVar))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OpsGenie incident client: %w", err)
+	}
+
+	c := &IncidentClient{
+		Client: incidentClient,
+		alerts: make(map[string]incidentAlerts),
+	}
+
+	return c, nil
+}
+
+// ListOpenIncidents retrieves the open incidents of the team from OpsGenie, or
+// every open incident if the team is empty. The method handles pagination
+// automatically.
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout control
+//   - team: Name of the team responding to the incidents
+//
+// Returns:
+//   - []incident.Incident: The open incidents
+//   - error: An error if an API request fails
+func (c *IncidentClient) ListOpenIncidents(ctx context.Context, team string) ([]incident.Incident, error) {
+	incidents := make([]incident.Incident, 0)
+
+	for offset := 0; ; offset += maxIncidentsPerRequest {
+		listRequest := &incident.ListRequest{
+			Offset: offset,
+			Limit:  maxIncidentsPerRequest,
+			Query:  openIncidentsQuery(team),
+		}
+
+		response, err := c.Client.List(ctx, listRequest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list incidents: %w", err)
+		}
+
+		incidents = append(incidents, response.Incidents...)
+
+		if len(response.Incidents) < maxIncidentsPerRequest {
+			break
+		}
+	}
+
+	slog.Debug("fetched open incidents", "team", team, "count", len(incidents))
+
+	return incidents, nil
+}
+
+// OpenIncidentAlerts returns the incident ID of every alert linked to an open
+// incident of the teams, keyed by alert ID. The alerts of an incident are
+// cached until it is updated, e.g. when an alert is added to it, and until it
+// is not open anymore, so that they are only fetched once.
+//
+// Parameters:
+//   - ctx: Context for request cancellation and timeout control
+//   - teams: Names of the teams responding to the incidents
+//
+// Returns:
+//   - map[string]string: The incident IDs keyed by alert ID
+//   - error: An error if an API request fails
+func (c *IncidentClient) OpenIncidentAlerts(ctx context.Context, teams []string) (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	incidentIDs := make(map[string]string)
+	listed := make(map[string]bool)
+	for _, team := range teams {
+		incidents, err := c.ListOpenIncidents(ctx, team)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, i := range incidents {
+			if listed[i.Id] {
+				continue
+			}
+			listed[i.Id] = true
+
+			cached, ok := c.alerts[i.Id]
+			if !ok || !cached.updatedAt.Equal(i.UpdatedAt) {
+				alertsRequest := &incident.GetResponderAlertsRequest{
+					Id:         i.Id,
+					Identifier: incident.Id,
+				}
+
+				alerts, err := c.Client.GetResponderAlerts(ctx, alertsRequest)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get alerts of incident with ID %s: %w", i.Id, err)
+				}
+
+				cached = incidentAlerts{updatedAt: i.UpdatedAt, alertIDs: alerts.AlertIds}
+				c.alerts[i.Id] = cached
+			}
+
+			for _, alertID := range cached.alertIDs {
+				incidentIDs[alertID] = i.Id
+			}
+		}
+	}
+
+	// Forget the incidents which are not open anymore.
+	for id := range c.alerts {
+		if !listed[id] {
+			delete(c.alerts, id)
+		}
+	}
+
+	slog.Debug("fetched incident alerts", "count", len(incidentIDs))
+
+	return incidentIDs, nil
+}
+
+// openIncidentsQuery returns the query listing the open incidents of the
+// team, or every open incident if the team is empty.
+func openIncidentsQuery(team string) string {
+	if team == "" {
+		return "status: open"
+	}
+
+	return fmt.Sprintf("status: open AND responders: %q", team)
+}
+
+// summarizeAlert returns the summary of an alert.
+func summarizeAlert(a alert.Alert) AlertSummary {
+	return AlertSummary{
+		Id:        a.Id,
+		Message:   a.Message,
+		Priority:  a.Priority,
+		Status:    a.Status,
+		CreatedAt: a.CreatedAt,
+	}
+}
@@ -0,0 +1,151 @@
+package opsgenie
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/incident"
+)
+
+func TestDispatchIncidents(t *testing.T) {
+	fake := newFakeOpsGenie(t)
+	fake.handle("GET /v1/incidents", func(r *http.Request) any {
+		query := r.URL.Query().Get("query")
+		switch {
+		case strings.Contains(query, `"team-a"`):
+			return []incident.Incident{
+				{Id: "incident-1", Message: "Cluster down", Status: "open", Priority: incident.P1},
+				{Id: "incident-2", Message: "Disk almost full", Status: "open", Priority: incident.P5},
+			}
+		case strings.Contains(query, `"team-b"`):
+			return []incident.Incident{
+				{Id: "incident-1", Message: "Cluster down", Status: "open", Priority: incident.P1},
+				{Id: "incident-3", Message: "Certificate expiring", Status: "open", Priority: incident.P3},
+			}
+		}
+		return []incident.Incident{}
+	})
+
+	incidentClient, err := NewIncidentClient(fake.apiURL(), fakeAPIKeyEnvVar)
+	if err != nil {
+		t.Fatalf("failed to create incident client: %v", err)
+	}
+
+	s := &Service{
+		incidentClient: incidentClient,
+		incidentState:  newStateStore(""),
+		minPriority:    "P3",
+	}
+	queries := []teamQuery{{team: "team-a"}, {team: "team-b"}}
+	ctx := context.Background()
+
+	incidents, err := s.listIncidents(ctx, queries)
+	if err != nil {
+		t.Fatalf("failed to list incidents: %v", err)
+	}
+	if len(incidents) != 3 {
+		t.Fatalf("expected 3 incidents merged across teams, got %d", len(incidents))
+	}
+
+	expectedQueries := []string{`status: open AND responders: "team-a"`, `status: open AND responders: "team-b"`}
+	if queries := fake.queries("/v1/incidents"); !slices.Equal(queries, expectedQueries) {
+		t.Errorf("expected queries %q, got %q", expectedQueries, queries)
+	}
+
+	queryChan := make(chan any, len(incidents))
+	count := s.dispatchIncidents(ctx, incidents, queryChan)
+	if count != 2 {
+		t.Fatalf("expected 2 dispatched incidents, got %d", count)
+	}
+
+	var dispatched []string
+	for range count {
+		openIncident, ok := (<-queryChan).(*incident.Incident)
+		if !ok {
+			t.Fatal("expected an incident payload")
+		}
+		dispatched = append(dispatched, openIncident.Id)
+	}
+	if !slices.Equal(dispatched, []string{"incident-1", "incident-3"}) {
+		t.Errorf("expected incidents incident-1 and incident-3 to be dispatched, got %q", dispatched)
+	}
+
+	// The incidents are only dispatched once, even without state file.
+	count = s.dispatchIncidents(ctx, incidents, queryChan)
+	if count != 0 {
+		t.Errorf("expected no incident dispatched again, got %d", count)
+	}
+}
+
+func TestOpenIncidentsQuery(t *testing.T) {
+	testCases := []struct {
+		team     string
+		expected string
+	}{
+		{
+			team:     "",
+			expected: "status: open",
+		},
+		{
+			team:     "team-a",
+			expected: `status: open AND responders: "team-a"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		query := openIncidentsQuery(tc.team)
+		if query != tc.expected {
+			t.Errorf("expected query %q for team %q, got %q", tc.expected, tc.team, query)
+		}
+	}
+}
+
+func TestOpenIncidentAlertsCache(t *testing.T) {
+	updatedAt := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+
+	fake := newFakeOpsGenie(t)
+	fake.handle("GET /v1/incidents", func(r *http.Request) any {
+		return []incident.Incident{{Id: "incident-1", Status: "open", UpdatedAt: updatedAt}}
+	})
+	fake.handle("GET /v1/incidents/incident-1/responder-alert-ids", func(r *http.Request) any {
+		return []string{"alert-1", "alert-2"}
+	})
+
+	incidentClient, err := NewIncidentClient(fake.apiURL(), fakeAPIKeyEnvVar)
+	if err != nil {
+		t.Fatalf("failed to create incident client: %v", err)
+	}
+
+	ctx := context.Background()
+	for range 2 {
+		incidentIDs, err := incidentClient.OpenIncidentAlerts(ctx, []string{"team-a"})
+		if err != nil {
+			t.Fatalf("failed to get incident alerts: %v", err)
+		}
+
+		expected := map[string]string{"alert-1": "incident-1", "alert-2": "incident-1"}
+		if !maps.Equal(incidentIDs, expected) {
+			t.Errorf("expected incident alerts %v, got %v", expected, incidentIDs)
+		}
+	}
+
+	if requests := fake.queries("/v1/incidents/incident-1/responder-alert-ids"); len(requests) != 1 {
+		t.Errorf("expected the alerts of the incident to be fetched once, got %d requests", len(requests))
+	}
+
+	// The alerts are fetched again once the incident is updated.
+	updatedAt = updatedAt.Add(time.Minute)
+	_, err = incidentClient.OpenIncidentAlerts(ctx, []string{"team-a"})
+	if err != nil {
+		t.Fatalf("failed to get incident alerts: %v", err)
+	}
+
+	if requests := fake.queries("/v1/incidents/incident-1/responder-alert-ids"); len(requests) != 2 {
+		t.Errorf("expected the alerts of the updated incident to be fetched again, got %d requests", len(requests))
+	}
+}
@@ -0,0 +1,29 @@
+package opsgenie
+
+import (
+	"strings"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+)
+
+// installationKey is the alert detail, or tag prefix, identifying the
+// installation an alert was raised on.
+const installationKey = "installation"
+
+// Installation returns the installation the alert was raised on, from its
+// "installation" detail or its "installation:<name>" tag, or an empty string
+// if the alert does not identify one.
+func Installation(a *alert.GetAlertResult) string {
+	if installation := strings.TrimSpace(a.Details[installationKey]); installation != "" {
+		return installation
+	}
+
+	for _, tag := range a.Tags {
+		name, ok := strings.CutPrefix(tag, installationKey+":")
+		if ok && strings.TrimSpace(name) != "" {
+			return strings.TrimSpace(name)
+		}
+	}
+
+	return ""
+}
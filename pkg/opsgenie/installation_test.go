@@ -0,0 +1,45 @@
+package opsgenie
+
+import (
+	"testing"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+)
+
+func TestInstallation(t *testing.T) {
+	testCases := []struct {
+		name     string
+		alert    *alert.GetAlertResult
+		expected string
+	}{
+		{
+			name:     "detail",
+			alert:    &alert.GetAlertResult{Details: map[string]string{"installation": " gazelle "}, Tags: []string{"installation:golem"}},
+			expected: "gazelle",
+		},
+		{
+			name:     "tag",
+			alert:    &alert.GetAlertResult{Tags: []string{"team:phoenix", "installation:golem"}},
+			expected: "golem",
+		},
+		{
+			name:     "empty detail and tag",
+			alert:    &alert.GetAlertResult{Details: map[string]string{"installation": ""}, Tags: []string{"installation: "}},
+			expected: "",
+		},
+		{
+			name:     "no installation",
+			alert:    &alert.GetAlertResult{Tags: []string{"team:phoenix"}},
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			installation := Installation(tc.alert)
+			if installation != tc.expected {
+				t.Errorf("expected installation %q, got %q", tc.expected, installation)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package opsgenie
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+)
+
+// AckNoteData holds the data available to the acknowledgement note template.
+type AckNoteData struct {
+	Alert       *alert.GetAlertResult // Alert being acknowledged
+	SessionID   string                // ID of the session investigating the alert
+	SlackHandle string                // Slack handle the investigation report is posted to
+}
+
+// TemplateAckNote templates the note added to an alert when OKA acknowledges
+// it.
+func TemplateAckNote(noteTemplate string, data AckNoteData) (string, error) {
+	if noteTemplate == "" {
+		return "", fmt.Errorf("acknowledgement note template cannot be empty")
+	}
+
+	tmpl, err := template.New("opsgenieAckNote").Parse(noteTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse OpsGenie acknowledgement note template: %w", err)
+	}
+
+	var note strings.Builder
+	err = tmpl.Execute(&note, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute OpsGenie acknowledgement note template: %w", err)
+	}
+
+	return note.String(), nil
+}
@@ -0,0 +1,31 @@
+package opsgenie
+
+import (
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+)
+
+// priorityRanks ranks the alert priorities, P1 being the highest.
+var priorityRanks = map[alert.Priority]int{
+	alert.P1: 1,
+	alert.P2: 2,
+	alert.P3: 3,
+	alert.P4: 4,
+	alert.P5: 5,
+}
+
+// meetsMinPriority returns true if the alert priority is at least the minimum
+// priority. Every alert meets an empty minimum priority, and alerts with an
+// unknown priority always meet it.
+func meetsMinPriority(priority alert.Priority, minPriority string) bool {
+	minRank, ok := priorityRanks[alert.Priority(minPriority)]
+	if !ok {
+		return true
+	}
+
+	rank, ok := priorityRanks[priority]
+	if !ok {
+		return true
+	}
+
+	return rank <= minRank
+}
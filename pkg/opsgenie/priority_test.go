@@ -0,0 +1,113 @@
+package opsgenie
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+	"golang.org/x/time/rate"
+)
+
+func TestMeetsMinPriority(t *testing.T) {
+	testCases := []struct {
+		name        string
+		priority    alert.Priority
+		minPriority string
+		expected    bool
+	}{
+		{
+			name:        "no minimum priority",
+			priority:    alert.P5,
+			minPriority: "",
+			expected:    true,
+		},
+		{
+			name:        "higher priority",
+			priority:    alert.P1,
+			minPriority: "P2",
+			expected:    true,
+		},
+		{
+			name:        "same priority",
+			priority:    alert.P2,
+			minPriority: "P2",
+			expected:    true,
+		},
+		{
+			name:        "lower priority",
+			priority:    alert.P3,
+			minPriority: "P2",
+			expected:    false,
+		},
+		{
+			name:        "unknown priority",
+			priority:    "",
+			minPriority: "P2",
+			expected:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := meetsMinPriority(tc.priority, tc.minPriority); result != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestDispatchAlertsMinPriority(t *testing.T) {
+	priorities := map[string]alert.Priority{
+		"alert-p1": alert.P1,
+		"alert-p2": alert.P2,
+		"alert-p3": alert.P3,
+		"alert-p5": alert.P5,
+	}
+
+	fake := newFakeOpsGenie(t)
+	fake.handle("GET /v2/alerts/{id}", func(r *http.Request) any {
+		return alert.GetAlertResult{Id: r.PathValue("id"), Priority: priorities[r.PathValue("id")]}
+	})
+
+	alertClient, err := NewAlertClient(fake.apiURL(), fakeAPIKeyEnvVar, 0, time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to create alert client: %v", err)
+	}
+
+	s := &Service{
+		alertClient:      alertClient,
+		fetchConcurrency: 1,
+		fetchLimiter:     rate.NewLimiter(rate.Inf, 0),
+		minPriority:      "P2",
+	}
+
+	var alerts []alert.Alert
+	for id, priority := range priorities {
+		alerts = append(alerts, alert.Alert{Id: id, Priority: priority})
+	}
+
+	queryChan := make(chan any, len(alerts))
+	s.dispatchAlerts(context.Background(), alerts, nil, queryChan)
+	close(queryChan)
+
+	var dispatched []string
+	for payload := range queryChan {
+		dispatched = append(dispatched, payload.(*alert.GetAlertResult).Id)
+	}
+	slices.Sort(dispatched)
+
+	expected := []string{"alert-p1", "alert-p2"}
+	if !slices.Equal(dispatched, expected) {
+		t.Errorf("expected alerts %q to be dispatched, got %q", expected, dispatched)
+	}
+
+	// The details of the filtered alerts are not fetched.
+	for _, id := range []string{"alert-p3", "alert-p5"} {
+		if n := len(fake.queries("/v2/alerts/" + id)); n != 0 {
+			t.Errorf("expected the details of %s not to be fetched, got %d requests", id, n)
+		}
+	}
+}
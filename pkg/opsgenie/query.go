@@ -5,35 +5,112 @@ import (
 	"strings"
 	"text/template"
 	"time"
+
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/templates"
 )
 
-// TemplateQuery templates the OpsGenie query string with the provided team and
-// the current date.
-func TemplateQuery(queryString, team string) (string, error) {
+// teamQuery is the OpsGenie query fetching the alerts of a team. The query is
+// rendered on every poll so that its dates are current.
+type teamQuery struct {
+	team        string
+	queryString string
+	tags        []string
+	template    *template.Template
+}
+
+// String returns the team and its query string, for logging.
+func (q teamQuery) String() string {
+	if q.team == "" {
+		return q.queryString
+	}
+
+	return fmt.Sprintf("%s: %s", q.team, q.queryString)
+}
+
+// render renders the query at the given time.
+func (q teamQuery) render(now time.Time) (string, error) {
+	return renderQuery(q.template, q.team, q.tags, now)
+}
+
+// parseQueries parses the query string of every configured team. The queries
+// are rendered once so that errors, e.g. a missing team, are reported before
+// polling.
+func parseQueries(conf *config.Config) ([]teamQuery, error) {
+	teams := conf.OpsGenie.GetTeams()
+
+	queries := make([]teamQuery, 0, len(teams))
+	for _, team := range teams {
+		q, err := parseQuery(team.QueryString, team.Name, conf.OpsGenie.QueryTags)
+		if err == nil {
+			_, err = q.render(time.Now())
+		}
+		if err != nil {
+			if team.Name != "" {
+				return nil, fmt.Errorf("team %s: %w", team.Name, err)
+			}
+			return nil, err
+		}
+		queries = append(queries, q)
+	}
+
+	return queries, nil
+}
+
+// parseQuery parses the query string of a team.
+func parseQuery(queryString, team string, tags []string) (teamQuery, error) {
 	if queryString == "" {
-		return "", fmt.Errorf("query string cannot be empty")
+		return teamQuery{}, fmt.Errorf("query string cannot be empty")
+	}
+
+	queryTemplate, err := templates.ParseQuery(queryString)
+	if err != nil {
+		return teamQuery{}, fmt.Errorf("failed to parse OpsGenie query template: %w", err)
 	}
 
-	if team == "" {
-		return "", fmt.Errorf("team cannot be empty")
+	q := teamQuery{
+		team:        team,
+		queryString: queryString,
+		tags:        tags,
+		template:    queryTemplate,
 	}
 
-	queryTemplate, err := template.New("opsgenieQuery").Parse(queryString)
+	return q, nil
+}
+
+// TemplateQuery templates the OpsGenie query string with the provided team and
+// tags, and the current date, with the sprig functions available. The team is
+// only required when the query string references it.
+func TemplateQuery(queryString, team string, tags []string) (string, error) {
+	q, err := parseQuery(queryString, team, tags)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse OpsGenie query template: %w", err)
+		return "", err
 	}
 
-	queryTemplateData := struct {
-		Team  string
-		Today string
-	}{
-		Team:  team,
-		Today: time.Now().UTC().Truncate(24 * time.Hour).Format("02-01-2006T15:04:05"),
+	return q.render(time.Now())
+}
+
+// renderQuery renders the query template with the provided team and tags, and
+// the dates relative to now in the OpsGenie format.
+func renderQuery(queryTemplate *template.Template, team string, tags []string, now time.Time) (string, error) {
+	now = now.UTC()
+	today := now.Truncate(24 * time.Hour)
+	queryTemplateData := map[string]any{
+		"Now":       now.Format(templates.QueryTimeFormat),
+		"Today":     today.Format(templates.QueryTimeFormat),
+		"Yesterday": today.AddDate(0, 0, -1).Format(templates.QueryTimeFormat),
+		"Tags":      tags,
+	}
+	if team != "" {
+		queryTemplateData["Team"] = team
 	}
 
 	var query strings.Builder
-	err = queryTemplate.Execute(&query, queryTemplateData)
+	err := queryTemplate.Execute(&query, queryTemplateData)
 	if err != nil {
+		if team == "" && strings.Contains(err.Error(), `"Team"`) {
+			return "", fmt.Errorf("team cannot be empty when the query string references it")
+		}
 		return "", fmt.Errorf("failed to execute OpsGenie query template: %w", err)
 	}
 
@@ -0,0 +1,110 @@
+package opsgenie
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTeamQueryRender(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name        string
+		queryString string
+		team        string
+		tags        []string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:        "today",
+			queryString: "createdAt > {{ .Today }}",
+			expected:    "createdAt > 15-03-2024T00:00:00",
+		},
+		{
+			name:        "now and yesterday",
+			queryString: "createdAt > {{ .Yesterday }} AND createdAt < {{ .Now }}",
+			expected:    "createdAt > 14-03-2024T00:00:00 AND createdAt < 15-03-2024T10:30:00",
+		},
+		{
+			name:        "team",
+			queryString: `responder: "{{ .Team }}" AND status: open`,
+			team:        "team-a",
+			expected:    `responder: "team-a" AND status: open`,
+		},
+		{
+			name:        "tags",
+			queryString: `tag: ({{ join " OR " .Tags }})`,
+			tags:        []string{"cluster-a", "cluster-b"},
+			expected:    "tag: (cluster-a OR cluster-b)",
+		},
+		{
+			name:        "sprig functions",
+			queryString: `status: {{ "OPEN" | lower }}`,
+			expected:    "status: open",
+		},
+		{
+			name:        "opsgenie date",
+			queryString: `createdAt > {{ "2024-03-15T10:30:00Z" | toDate "2006-01-02T15:04:05Z07:00" | dateModify "-2h" | opsgenieDate }}`,
+			expected:    "createdAt > 15-03-2024T08:30:00",
+		},
+		{
+			name:        "missing team",
+			queryString: `responder: "{{ .Team }}"`,
+			expectedErr: "team cannot be empty",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := parseQuery(tc.queryString, tc.team, tc.tags)
+			if err != nil {
+				t.Fatalf("failed to parse query: %v", err)
+			}
+
+			query, err := q.render(now)
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to render query: %v", err)
+			}
+
+			if query != tc.expected {
+				t.Errorf("expected query %q, got %q", tc.expected, query)
+			}
+		})
+	}
+}
+
+func TestTeamQueryRenderEveryPoll(t *testing.T) {
+	q, err := parseQuery("createdAt > {{ .Now }}", "", nil)
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	first, err := q.render(time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("failed to render query: %v", err)
+	}
+
+	second, err := q.render(time.Date(2024, 3, 15, 10, 31, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("failed to render query: %v", err)
+	}
+
+	if first == second {
+		t.Errorf("expected the query to change between polls, got %q twice", first)
+	}
+}
+
+func TestParseQueryEmpty(t *testing.T) {
+	_, err := parseQuery("", "team-a", nil)
+	if err == nil {
+		t.Fatal("expected an error for an empty query string")
+	}
+}
@@ -0,0 +1,83 @@
+package opsgenie
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+func TestReloadInterval(t *testing.T) {
+	fake := newFakeOpsGenie(t)
+	fake.handle("GET /v2/alerts", func(r *http.Request) any {
+		return []alert.Alert{}
+	})
+
+	path := filepath.Join(t.TempDir(), "oka.yaml")
+	writeConfig := func(interval string) *config.Config {
+		t.Helper()
+
+		content := "opsgenie:\n  env_var: " + fakeAPIKeyEnvVar + "\n  interval: " + interval + "\n  query_string: \"status: open\"\n"
+		err := os.WriteFile(path, []byte(content), 0600)
+		if err != nil {
+			t.Fatalf("failed to write config file: %v", err)
+		}
+
+		conf, err := config.LoadConfig(path, true)
+		if err != nil {
+			t.Fatalf("failed to load config: %v", err)
+		}
+
+		return conf
+	}
+
+	s, err := NewService(writeConfig("1h"))
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	s.alertClient, err = NewAlertClient(fake.apiURL(), fakeAPIKeyEnvVar, 0, time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to create alert client: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Start(ctx, make(chan any))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	// Nothing is polled before the initial interval elapses.
+	time.Sleep(50 * time.Millisecond)
+	if polls := len(fake.queries("/v2/alerts")); polls != 0 {
+		t.Fatalf("expected no poll within the initial interval, got %d", polls)
+	}
+
+	// The new interval of the mutated file applies without restarting.
+	err = s.Reload(writeConfig("10ms"))
+	if err != nil {
+		t.Fatalf("failed to reload service: %v", err)
+	}
+
+	deadline := time.After(10 * time.Second)
+	for len(fake.queries("/v2/alerts")) < 2 {
+		select {
+		case <-deadline:
+			t.Fatalf("expected the service to poll with the reloaded interval, got %d polls", len(fake.queries("/v2/alerts")))
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	if queries, _ := s.settings(); len(queries) != 1 || queries[0].String() != "status: open" {
+		t.Errorf("expected the reloaded query, got %v", queries)
+	}
+}
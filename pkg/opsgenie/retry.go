@@ -0,0 +1,86 @@
+package opsgenie
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/client"
+)
+
+// maxRetryBackoff is the maximum delay between two attempts of a request.
+const maxRetryBackoff = time.Minute
+
+// retry calls f until it succeeds, retrying transient failures up to
+// maxRetries times. The delay between attempts grows exponentially from
+// backoff, with jitter, and waiting is interrupted if the context is
+// canceled.
+func retry[T any](ctx context.Context, maxRetries int, backoff time.Duration, f func() (T, error)) (T, error) {
+	for attempt := 0; ; attempt++ {
+		result, err := f()
+		if err == nil || attempt >= maxRetries || !isTransient(err) {
+			return result, err
+		}
+
+		delay := retryDelay(backoff, attempt)
+		slog.Debug("retrying OpsGenie request", "attempt", attempt+1, "delay", delay, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// retryDelay returns the delay before the next attempt, doubling the backoff
+// on each attempt with up to 50% of jitter.
+func retryDelay(backoff time.Duration, attempt int) time.Duration {
+	if backoff <= 0 {
+		return 0
+	}
+
+	delay := backoff << attempt
+	if delay <= 0 || delay > maxRetryBackoff {
+		delay = maxRetryBackoff
+	}
+
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// isTransient returns true if the request may succeed if retried, i.e. it
+// failed on a network error, a rate limit or a server error. Other failures,
+// e.g. invalid requests or responses which cannot be decoded, fail the same
+// way when retried.
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *client.ApiError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// retryPolicy is the retry policy of the OpsGenie SDK, retrying rate limited
+// and server error responses like its default policy but not network errors.
+// The SDK reports the network errors it gave up retrying as plain errors, they
+// are returned as is instead so that retry recognizes them.
+func retryPolicy(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || (resp.StatusCode >= http.StatusInternalServerError && resp.StatusCode != http.StatusNotImplemented), nil
+}
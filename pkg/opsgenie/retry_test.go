@@ -0,0 +1,200 @@
+package opsgenie
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/client"
+)
+
+// flakyCall fails with err the given number of times, then succeeds.
+type flakyCall struct {
+	failures int
+	err      error
+	calls    int
+}
+
+// call records the call and fails until the failures are exhausted.
+func (f *flakyCall) call() (string, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return "", f.err
+	}
+
+	return "ok", nil
+}
+
+func TestRetry(t *testing.T) {
+	serverErr := &client.ApiError{StatusCode: http.StatusServiceUnavailable}
+
+	testCases := []struct {
+		name          string
+		failures      int
+		err           error
+		maxRetries    int
+		expectedCalls int
+		expectedErr   bool
+	}{
+		{
+			name:          "success",
+			maxRetries:    3,
+			expectedCalls: 1,
+		},
+		{
+			name:          "transient failures",
+			failures:      2,
+			err:           serverErr,
+			maxRetries:    3,
+			expectedCalls: 3,
+		},
+		{
+			name:          "rate limited",
+			failures:      1,
+			err:           &client.ApiError{StatusCode: http.StatusTooManyRequests},
+			maxRetries:    3,
+			expectedCalls: 2,
+		},
+		{
+			name:          "network error",
+			failures:      1,
+			err:           &url.Error{Op: "Get", URL: "https://api.opsgenie.com/v2/alerts", Err: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}},
+			maxRetries:    3,
+			expectedCalls: 2,
+		},
+		{
+			name:          "network timeout",
+			failures:      1,
+			err:           &url.Error{Op: "Get", URL: "https://api.opsgenie.com/v2/alerts", Err: os.ErrDeadlineExceeded},
+			maxRetries:    3,
+			expectedCalls: 2,
+		},
+		{
+			name:          "non-transient error",
+			failures:      1,
+			err:           errors.New("invalid character '<' looking for beginning of value"),
+			maxRetries:    3,
+			expectedCalls: 1,
+			expectedErr:   true,
+		},
+		{
+			name:          "retries exhausted",
+			failures:      5,
+			err:           serverErr,
+			maxRetries:    3,
+			expectedCalls: 4,
+			expectedErr:   true,
+		},
+		{
+			name:          "permanent failure",
+			failures:      1,
+			err:           &client.ApiError{StatusCode: http.StatusNotFound},
+			maxRetries:    3,
+			expectedCalls: 1,
+			expectedErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &flakyCall{failures: tc.failures, err: tc.err}
+
+			result, err := retry(context.Background(), tc.maxRetries, time.Millisecond, f.call)
+			if tc.expectedErr {
+				if !errors.Is(err, tc.err) {
+					t.Errorf("expected error %v, got %v", tc.err, err)
+				}
+			} else if err != nil || result != "ok" {
+				t.Errorf("expected the call to succeed, got %q, %v", result, err)
+			}
+
+			if f.calls != tc.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tc.expectedCalls, f.calls)
+			}
+		})
+	}
+}
+
+func TestRetryCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &flakyCall{failures: 5, err: &client.ApiError{StatusCode: http.StatusBadGateway}}
+
+	// The context is canceled while waiting for the second attempt.
+	time.AfterFunc(10*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := retry(ctx, 3, time.Minute, f.call)
+	if err == nil {
+		t.Fatal("expected an error once canceled")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the cancelation to interrupt the backoff, waited %s", elapsed)
+	}
+	if f.calls != 1 {
+		t.Errorf("expected 1 call, got %d", f.calls)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempt := range 10 {
+		delay := retryDelay(time.Second, attempt)
+		base := min(time.Second<<attempt, maxRetryBackoff)
+		if delay < base/2 || delay > base {
+			t.Errorf("expected the delay of attempt %d within [%s, %s], got %s", attempt, base/2, base, delay)
+		}
+	}
+
+	if delay := retryDelay(0, 3); delay != 0 {
+		t.Errorf("expected no delay without backoff, got %s", delay)
+	}
+}
+
+func TestGetAlertRetryNetworkError(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	fake := newFakeOpsGenie(t)
+	fake.mux.HandleFunc("GET /v2/alerts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		first := calls == 1
+		mu.Unlock()
+
+		// Drop the connection of the first request.
+		if first {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				_ = conn.Close()
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": {"id": "alert-1"}, "took": 0.01, "requestId": "test"}`))
+	})
+
+	alertClient, err := NewAlertClient(fake.apiURL(), fakeAPIKeyEnvVar, 3, time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to create alert client: %v", err)
+	}
+
+	a, err := alertClient.GetAlert(context.Background(), "alert-1")
+	if err != nil {
+		t.Fatalf("expected the network error to be retried, got %v", err)
+	}
+	if a.Id != "alert-1" {
+		t.Errorf("expected alert-1, got %s", a.Id)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
@@ -0,0 +1,28 @@
+package opsgenie
+
+import (
+	"strings"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+)
+
+// IsOwnAlert returns true if the alert was created by OKA itself, i.e. its
+// source or owner matches the identity used for OKA's actions in OpsGenie.
+// Identities are compared case-insensitively and empty ones never match.
+func IsOwnAlert(a alert.Alert, actionSource, actionUser string) bool {
+	return isOwn(a.Source, a.Owner, actionSource, actionUser)
+}
+
+// isOwn returns true if the given alert source or owner matches the identity
+// used for OKA's actions in OpsGenie.
+func isOwn(source, owner, actionSource, actionUser string) bool {
+	if actionSource != "" && strings.EqualFold(source, actionSource) {
+		return true
+	}
+
+	if actionUser != "" && strings.EqualFold(owner, actionUser) {
+		return true
+	}
+
+	return false
+}
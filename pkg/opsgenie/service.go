@@ -2,86 +2,505 @@ package opsgenie
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+	"github.com/opsgenie/opsgenie-go-sdk-v2/incident"
+	"golang.org/x/time/rate"
+
 	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/health"
+	"github.com/giantswarm/oka/pkg/metrics"
 )
 
 // Service is a service for fetching alerts from OpsGenie.
 type Service struct {
-	alertClient *AlertClient
-	query       string
-	interval    time.Duration
+	actionSource     string
+	actionUser       string
+	alertClient      *AlertClient
+	fetchConcurrency int
+	fetchIncidents   bool
+	fetchLimiter     *rate.Limiter
+	groupByIncident  bool
+	incidentClient   *IncidentClient
+	incidentState    *stateStore
+	minPriority      string
+	state            *stateStore
+
+	// The queries and interval can be changed at runtime with Reload.
+	mu       sync.Mutex
+	queries  []teamQuery
+	interval time.Duration
+	reloaded chan struct{}
 }
 
 // NewService creates a new OpsGenie service.
 func NewService(conf *config.Config) (*Service, error) {
-	alertClient, err := NewAlertClient(conf.OpsGenie.APIUrl, conf.OpsGenie.EnvVar)
+	alertClient, err := NewAlertClient(conf.OpsGenie.APIUrl, conf.OpsGenie.EnvVar, conf.OpsGenie.MaxRetries, conf.OpsGenie.RetryBackoff)
 	if err != nil {
 		return nil, err
 	}
 
-	query, err := TemplateQuery(conf.OpsGenie.QueryString, conf.OpsGenie.Team)
+	queries, err := parseQueries(conf)
 	if err != nil {
 		return nil, err
 	}
 
+	fetchConcurrency := conf.OpsGenie.DetailFetchConcurrency
+	if fetchConcurrency < 1 {
+		fetchConcurrency = 1
+	}
+
+	// Detail fetches are not rate limited unless a rate is configured.
+	fetchLimiter := rate.NewLimiter(rate.Inf, 0)
+	if conf.OpsGenie.DetailFetchRate > 0 {
+		fetchLimiter = rate.NewLimiter(rate.Limit(conf.OpsGenie.DetailFetchRate), 1)
+	}
+
+	// The incident client is only needed to group alerts by incident or to
+	// fetch incidents.
+	var incidentClient *IncidentClient
+	if conf.OpsGenie.GroupByIncident || conf.OpsGenie.FetchIncidents {
+		incidentClient, err = NewIncidentClient(conf.OpsGenie.APIUrl, conf.OpsGenie.EnvVar)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The state is only recorded if a state file is configured.
+	var state *stateStore
+	if conf.OpsGenie.StateFile != "" {
+		state, err = loadState(conf.OpsGenie.StateFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Incidents cannot be acknowledged to skip them once investigated, they
+	// are recorded in memory if no state file is configured.
+	incidentState := state
+	if incidentState == nil {
+		incidentState = newStateStore("")
+	}
+
 	s := &Service{
-		alertClient: alertClient,
-		interval:    conf.OpsGenie.Interval,
-		query:       query,
+		actionSource:     conf.OpsGenie.ActionSource,
+		actionUser:       conf.OpsGenie.ActionUser,
+		alertClient:      alertClient,
+		fetchConcurrency: fetchConcurrency,
+		fetchIncidents:   conf.OpsGenie.FetchIncidents,
+		fetchLimiter:     fetchLimiter,
+		groupByIncident:  conf.OpsGenie.GroupByIncident,
+		incidentClient:   incidentClient,
+		incidentState:    incidentState,
+		interval:         conf.OpsGenie.Interval,
+		minPriority:      conf.OpsGenie.MinPriority,
+		queries:          queries,
+		reloaded:         make(chan struct{}, 1),
+		state:            state,
 	}
 
 	return s, nil
 }
 
+// AlertClient returns the OpsGenie alert client used by the service.
+func (s *Service) AlertClient() *AlertClient {
+	return s.alertClient
+}
+
 // Start starts the OpsGenie service, which periodically fetches alerts and
-// sends them to the provided channel.
-func (s *Service) Start(ctx context.Context, queryChan chan<- any) {
-	slog.Info("OpsGenie service started", "interval", s.interval, "query", s.query)
+// sends them to the provided channel until the context is canceled. Failures
+// to fetch alerts are logged and retried on the next poll.
+func (s *Service) Start(ctx context.Context, queryChan chan<- any) error {
+	queries, interval := s.settings()
+	slog.Info("OpsGenie service started", "interval", interval, "queries", queries, "fetch_incidents", s.fetchIncidents, "detail_fetch_concurrency", s.fetchConcurrency, "detail_fetch_rate", s.fetchLimiter.Limit())
 	defer slog.Info("OpsGenie service stopped")
 
-	ticker := time.Tick(s.interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 	for {
 		select {
 		case <-ctx.Done():
-			return
-		case <-ticker:
+			return nil
+		case <-s.reloaded:
+			_, interval := s.settings()
+			ticker.Reset(interval)
+		case <-ticker.C:
 			slog.Info("Fetching alerts from OpsGenie")
 
-			alerts, err := s.alertClient.ListAlerts(ctx, s.query)
+			queries, _ := s.settings()
+			alerts, teams, err := s.listAlerts(ctx, queries)
 			if err != nil {
 				slog.Error("Failed to fetch alerts from OpsGenie", "error", err)
+				metrics.AlertFetchErrors.Inc()
+				health.SetReady(health.CheckOpsGenie, false)
 				continue
 			}
 
-			if len(alerts) == 0 {
+			var incidents []incident.Incident
+			if s.fetchIncidents {
+				incidents, err = s.listIncidents(ctx, queries)
+				if err != nil {
+					slog.Error("Failed to fetch incidents from OpsGenie", "error", err)
+					metrics.AlertFetchErrors.Inc()
+					health.SetReady(health.CheckOpsGenie, false)
+					continue
+				}
+			}
+			metrics.AlertsFetched.Add(float64(len(alerts)))
+			health.SetReady(health.CheckOpsGenie, true)
+
+			// Forget the alerts and incidents which are not returned anymore.
+			s.state.evict(alerts, incidents)
+			if s.incidentState != s.state {
+				s.incidentState.evict(nil, incidents)
+			}
+
+			if len(alerts) == 0 && len(incidents) == 0 {
 				slog.Info("No new alerts found in OpsGenie")
+				s.saveState()
 				continue
 			}
 
-			count := 0
-			for _, alert := range alerts {
-				if alert.Acknowledged {
-					continue // Skip acknowledged alerts
-				}
+			count := s.dispatchAlerts(ctx, alerts, teams, queryChan)
+			incidentCount := s.dispatchIncidents(ctx, incidents, queryChan)
+			s.saveState()
 
-				a, err := s.alertClient.GetAlert(ctx, alert.Id)
-				if err != nil {
-					slog.Warn("Failed to get alert from OpsGenie", "id", alert.Id, "error", err)
-					continue
+			slog.Info("Fetched new alerts from OpsGenie", "new", count, "total", len(alerts), "new_incidents", incidentCount, "total_incidents", len(incidents))
+		}
+	}
+}
+
+// ListAlerts returns the alerts matching the configured queries, without
+// dispatching them.
+func (s *Service) ListAlerts(ctx context.Context) ([]alert.Alert, error) {
+	queries, _ := s.settings()
+
+	alerts, _, err := s.listAlerts(ctx, queries)
+	return alerts, err
+}
+
+// listAlerts returns the alerts matching the queries of every team, merged
+// so that an alert returned by several queries is listed once, along with the
+// team whose query returned each alert, by alert ID. An alert is attributed
+// to the first team returning it.
+func (s *Service) listAlerts(ctx context.Context, queries []teamQuery) ([]alert.Alert, map[string]string, error) {
+	var alerts []alert.Alert
+	teams := make(map[string]string)
+
+	now := time.Now()
+	for _, q := range queries {
+		query, err := q.render(now)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		teamAlerts, err := s.alertClient.ListAlerts(ctx, query)
+		if err != nil {
+			if q.team != "" {
+				return nil, nil, fmt.Errorf("failed to list alerts of team %s: %w", q.team, err)
+			}
+			return nil, nil, err
+		}
+
+		for _, a := range teamAlerts {
+			if _, ok := teams[a.Id]; ok {
+				continue
+			}
+			teams[a.Id] = q.team
+			alerts = append(alerts, a)
+		}
+	}
+
+	return alerts, teams, nil
+}
+
+// listIncidents returns the open incidents of the teams of the queries, merged
+// so that an incident of several teams is listed once.
+func (s *Service) listIncidents(ctx context.Context, queries []teamQuery) ([]incident.Incident, error) {
+	var incidents []incident.Incident
+	listed := make(map[string]bool)
+	teams := make(map[string]bool)
+
+	for _, q := range queries {
+		if teams[q.team] {
+			continue
+		}
+		teams[q.team] = true
+
+		teamIncidents, err := s.incidentClient.ListOpenIncidents(ctx, q.team)
+		if err != nil {
+			if q.team != "" {
+				return nil, fmt.Errorf("failed to list incidents of team %s: %w", q.team, err)
+			}
+			return nil, err
+		}
+
+		for _, i := range teamIncidents {
+			if listed[i.Id] {
+				continue
+			}
+			listed[i.Id] = true
+			incidents = append(incidents, i)
+		}
+	}
+
+	return incidents, nil
+}
+
+// saveState saves the state of the dispatched alerts, logging failures.
+func (s *Service) saveState() {
+	err := s.state.save()
+	if err != nil {
+		slog.Warn("Failed to save the state of dispatched alerts", "error", err)
+	}
+}
+
+// alertGroup is an alert to investigate, along with the other alerts of its
+// incident if alerts are grouped by incident.
+type alertGroup struct {
+	alertID    string
+	incidentID string
+	siblings   []alert.Alert
+}
+
+// dispatchAlerts fetches the details of the alerts to investigate and sends
+// them to the provided channel, tagged with the team whose query returned
+// them. Acknowledged alerts, alerts created by OKA itself, alerts below the
+// minimum priority and alerts already dispatched are skipped.
+//
+// Details are fetched by at most fetchConcurrency concurrent requests at the
+// configured rate, rate limited responses are retried by the OpsGenie client.
+// Alerts are dispatched in no particular order. It returns the number of
+// dispatched alerts.
+func (s *Service) dispatchAlerts(ctx context.Context, alerts []alert.Alert, teams map[string]string, queryChan chan<- any) int {
+	var (
+		count atomic.Int64
+		wg    sync.WaitGroup
+	)
+
+	sem := make(chan struct{}, s.fetchConcurrency)
+
+loop:
+	for _, group := range s.groupAlerts(ctx, alerts, teams) {
+		if s.state.contains(group.alertID) {
+			slog.Debug("Skipping alert already dispatched", "alert.id", group.alertID)
+			continue
+		}
+
+		// Wait for a free worker slot.
+		select {
+		case <-ctx.Done():
+			break loop
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(group alertGroup) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := s.fetchLimiter.Wait(ctx)
+			if err != nil {
+				return
+			}
+
+			a, err := s.alertClient.GetAlert(ctx, group.alertID)
+			if err != nil {
+				slog.Warn("Failed to get alert from OpsGenie", "alert.id", group.alertID, "error", err)
+				return
+			}
+			tagSourceTeam(a, teams[group.alertID])
+
+			var payload any = a
+			if group.incidentID != "" {
+				incidentAlert := &IncidentAlert{
+					GetAlertResult: a,
+					IncidentID:     group.incidentID,
+					IncidentAlerts: make([]AlertSummary, 0, len(group.siblings)),
+				}
+				for _, sibling := range group.siblings {
+					incidentAlert.IncidentAlerts = append(incidentAlert.IncidentAlerts, summarizeAlert(sibling))
 				}
+				payload = incidentAlert
+			}
 
-				// TODO: Maybe find the installation related to this alert and pass an
-				// installation parameter in order to restrict the available kube contexts.
+			// Send the alert to the channel for further processing.
+			select {
+			case <-ctx.Done():
+			case queryChan <- payload:
+				s.state.add(group.alertID)
+				count.Add(1)
+				slog.Debug("Dispatched alert", "alert.id", group.alertID, "team", teams[group.alertID])
+			}
+		}(group)
+	}
+
+	wg.Wait()
+
+	return int(count.Load())
+}
+
+// dispatchIncidents sends the open incidents to the provided channel, the
+// sessions investigate them from their JSON. Incidents below the minimum
+// priority and incidents already dispatched are skipped, unlike alerts they
+// have no acknowledgement. It returns the number of dispatched incidents.
+func (s *Service) dispatchIncidents(ctx context.Context, incidents []incident.Incident, queryChan chan<- any) int {
+	count := 0
+	for i := range incidents {
+		openIncident := &incidents[i]
+		if !meetsMinPriority(alert.Priority(openIncident.Priority), s.minPriority) {
+			slog.Debug("Skipping incident below the minimum priority", "incident", openIncident.Id, "priority", openIncident.Priority, "min_priority", s.minPriority)
+			continue
+		}
+
+		if s.incidentState.contains(openIncident.Id) {
+			slog.Debug("Skipping incident already dispatched", "incident", openIncident.Id)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return count
+		case queryChan <- openIncident:
+			s.incidentState.add(openIncident.Id)
+			count++
+		}
+	}
+
+	return count
+}
+
+// groupAlerts returns the alerts to investigate. When alerts are grouped by
+// incident, a single alert is investigated per incident, the oldest one, along
+// with the other alerts of the incident. An incident is not investigated again
+// once one of its alerts is acknowledged. Alerts are not grouped if the
+// incidents cannot be fetched.
+func (s *Service) groupAlerts(ctx context.Context, alerts []alert.Alert, teams map[string]string) []alertGroup {
+	candidates := make([]alert.Alert, 0, len(alerts))
+	for _, a := range alerts {
+		if IsOwnAlert(a, s.actionSource, s.actionUser) {
+			slog.Warn("Skipping alert created by OKA", "alert.id", a.Id, "source", a.Source, "owner", a.Owner)
+			continue
+		}
+
+		if !meetsMinPriority(a.Priority, s.minPriority) {
+			slog.Debug("Skipping alert below the minimum priority", "alert.id", a.Id, "priority", a.Priority, "min_priority", s.minPriority)
+			continue
+		}
+
+		candidates = append(candidates, a)
+	}
 
-				// Send the alert to the channel for further processing.
-				queryChan <- a
-				count++
+	var incidentIDs map[string]string
+	if s.groupByIncident {
+		incidentIDs = s.incidentAlerts(ctx, candidates, teams)
+	}
+
+	groups := make([]alertGroup, 0, len(candidates))
+	incidents := make(map[string]*alertGroup)
+	acknowledgedIncidents := make(map[string]bool)
+
+	for _, a := range candidates {
+		incidentID := incidentIDs[a.Id]
+		if incidentID == "" {
+			if !a.Acknowledged {
+				groups = append(groups, alertGroup{alertID: a.Id})
 			}
+			continue
+		}
+
+		if a.Acknowledged {
+			acknowledgedIncidents[incidentID] = true
+		}
+
+		group, ok := incidents[incidentID]
+		if !ok {
+			group = &alertGroup{incidentID: incidentID}
+			incidents[incidentID] = group
+		}
+		group.siblings = append(group.siblings, a)
+	}
 
-			slog.Info("Fetched new alerts from OpsGenie", "new", count, "total", len(alerts))
+	for incidentID, group := range incidents {
+		if acknowledgedIncidents[incidentID] {
+			slog.Debug("Skipping incident with acknowledged alerts", "incident", incidentID)
+			continue
 		}
+
+		// Alerts are listed most recent first, investigate the oldest one
+		// which is likely the closest to the root cause.
+		oldest := len(group.siblings) - 1
+		group.alertID = group.siblings[oldest].Id
+		group.siblings = group.siblings[:oldest]
+		groups = append(groups, *group)
+
+		slog.Info("Grouped alerts by incident", "incident", incidentID, "alert.id", group.alertID, "siblings", len(group.siblings))
+	}
+
+	return groups
+}
+
+// incidentAlerts returns the incident ID of the alerts linked to an open
+// incident, keyed by alert ID. Only the incidents of the teams of the alerts to
+// dispatch, neither acknowledged nor already dispatched, are fetched, and none
+// if there is no such alert.
+func (s *Service) incidentAlerts(ctx context.Context, alerts []alert.Alert, teams map[string]string) map[string]string {
+	var alertTeams []string
+	listed := make(map[string]bool)
+	for _, a := range alerts {
+		if a.Acknowledged || s.state.contains(a.Id) || listed[teams[a.Id]] {
+			continue
+		}
+		listed[teams[a.Id]] = true
+		alertTeams = append(alertTeams, teams[a.Id])
+	}
+
+	if len(alertTeams) == 0 {
+		return nil
+	}
+
+	incidentIDs, err := s.incidentClient.OpenIncidentAlerts(ctx, alertTeams)
+	if err != nil {
+		slog.Warn("Failed to fetch incidents from OpsGenie, alerts are not grouped", "error", err)
+		return nil
+	}
+
+	return incidentIDs
+}
+
+// Reload applies the queries and interval of the given configuration, taking
+// effect from the next poll.
+func (s *Service) Reload(conf *config.Config) error {
+	queries, err := parseQueries(conf)
+	if err != nil {
+		return err
 	}
+
+	s.mu.Lock()
+	s.queries = queries
+	s.interval = conf.OpsGenie.Interval
+	s.mu.Unlock()
+
+	// The ticker is reset by the polling loop.
+	select {
+	case s.reloaded <- struct{}{}:
+	default:
+	}
+
+	slog.Info("Reloaded OpsGenie service", "interval", conf.OpsGenie.Interval, "queries", queries)
+
+	return nil
+}
+
+// settings returns the current queries and interval.
+func (s *Service) settings() ([]teamQuery, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.queries, s.interval
 }
@@ -0,0 +1,230 @@
+package opsgenie
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+	"github.com/opsgenie/opsgenie-go-sdk-v2/incident"
+	"golang.org/x/time/rate"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+func TestGroupAlerts(t *testing.T) {
+	fake := newFakeOpsGenie(t)
+	fake.handle("GET /v1/incidents", func(r *http.Request) any {
+		return []incident.Incident{{Id: "incident-1", Status: "open"}}
+	})
+	fake.handle("GET /v1/incidents/incident-1/responder-alert-ids", func(r *http.Request) any {
+		return []string{"alert-1", "alert-2"}
+	})
+
+	incidentClient, err := NewIncidentClient(fake.apiURL(), fakeAPIKeyEnvVar)
+	if err != nil {
+		t.Fatalf("failed to create incident client: %v", err)
+	}
+
+	s := &Service{
+		groupByIncident: true,
+		incidentClient:  incidentClient,
+		state:           newStateStore(""),
+	}
+	ctx := context.Background()
+	now := time.Now()
+
+	// Alerts are listed most recent first.
+	alerts := []alert.Alert{
+		{Id: "alert-3", Priority: alert.P3, CreatedAt: now},
+		{Id: "alert-2", Priority: alert.P3, CreatedAt: now.Add(-time.Minute)},
+		{Id: "alert-1", Priority: alert.P3, CreatedAt: now.Add(-2 * time.Minute)},
+	}
+	teams := map[string]string{"alert-1": "team-a", "alert-2": "team-a", "alert-3": "team-a"}
+
+	groups := s.groupAlerts(ctx, alerts, teams)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(groups))
+	}
+
+	for _, group := range groups {
+		switch group.alertID {
+		case "alert-3":
+			if group.incidentID != "" {
+				t.Errorf("expected alert-3 not to be grouped, got incident %s", group.incidentID)
+			}
+		case "alert-1":
+			if group.incidentID != "incident-1" || len(group.siblings) != 1 || group.siblings[0].Id != "alert-2" {
+				t.Errorf("expected alert-1 to be grouped with alert-2 in incident-1, got %+v", group)
+			}
+		default:
+			t.Errorf("unexpected group of alert %s", group.alertID)
+		}
+	}
+
+	if queries := fake.queries("/v1/incidents"); len(queries) != 1 || queries[0] != `status: open AND responders: "team-a"` {
+		t.Errorf("expected the incidents of team-a to be listed once, got %q", queries)
+	}
+}
+
+func TestGroupAlertsWithoutAlertToDispatch(t *testing.T) {
+	fake := newFakeOpsGenie(t)
+	fake.handle("GET /v1/incidents", func(r *http.Request) any {
+		return []incident.Incident{}
+	})
+
+	incidentClient, err := NewIncidentClient(fake.apiURL(), fakeAPIKeyEnvVar)
+	if err != nil {
+		t.Fatalf("failed to create incident client: %v", err)
+	}
+
+	s := &Service{
+		groupByIncident: true,
+		incidentClient:  incidentClient,
+		minPriority:     "P3",
+		state:           newStateStore(""),
+	}
+	s.state.add("alert-3")
+
+	alerts := []alert.Alert{
+		{Id: "alert-1", Priority: alert.P3, Acknowledged: true},
+		{Id: "alert-2", Priority: alert.P5},
+		{Id: "alert-3", Priority: alert.P1},
+	}
+
+	groups := s.groupAlerts(context.Background(), alerts, map[string]string{})
+	if len(groups) != 1 || groups[0].alertID != "alert-3" {
+		t.Errorf("expected only alert-3 to be returned, got %+v", groups)
+	}
+
+	if queries := fake.queries("/v1/incidents"); len(queries) != 0 {
+		t.Errorf("expected no incident to be listed, got %q", queries)
+	}
+}
+
+func TestDispatchAlertsFetchConcurrency(t *testing.T) {
+	const fetchConcurrency = 2
+
+	var (
+		mu          sync.Mutex
+		inFlight    int
+		maxInFlight int
+	)
+	fake := newFakeOpsGenie(t)
+	fake.handle("GET /v2/alerts/{id}", func(r *http.Request) any {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+
+		// Hold the request so that the fetches overlap.
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		return alert.GetAlertResult{Id: r.PathValue("id"), Priority: alert.P3}
+	})
+
+	// The detail fetches are not rate limited so that only the concurrency
+	// bounds them.
+	path := filepath.Join(t.TempDir(), "oka.yaml")
+	content := fmt.Sprintf("opsgenie:\n  env_var: %s\n  query_string: \"status: open\"\n  detail_fetch_concurrency: %d\n", fakeAPIKeyEnvVar, fetchConcurrency)
+	err := os.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	conf, err := config.LoadConfig(path, true)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	s, err := NewService(conf)
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	if s.fetchLimiter.Limit() != rate.Inf {
+		t.Fatalf("expected the detail fetches not to be rate limited, got %v", s.fetchLimiter.Limit())
+	}
+	s.alertClient, err = NewAlertClient(fake.apiURL(), fakeAPIKeyEnvVar, 0, time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to create alert client: %v", err)
+	}
+
+	var alerts []alert.Alert
+	for i := range 8 {
+		alerts = append(alerts, alert.Alert{Id: fmt.Sprintf("alert-%d", i), Priority: alert.P3})
+	}
+
+	queryChan := make(chan any, len(alerts))
+	count := s.dispatchAlerts(context.Background(), alerts, nil, queryChan)
+	if count != len(alerts) {
+		t.Errorf("expected %d dispatched alerts, got %d", len(alerts), count)
+	}
+	if len(queryChan) != len(alerts) {
+		t.Errorf("expected %d alerts sent to the channel, got %d", len(alerts), len(queryChan))
+	}
+
+	if maxInFlight > fetchConcurrency {
+		t.Errorf("expected at most %d concurrent fetches, got %d", fetchConcurrency, maxInFlight)
+	}
+	if maxInFlight < fetchConcurrency {
+		t.Errorf("expected the fetches to run concurrently, got at most %d at once", maxInFlight)
+	}
+}
+
+func TestListAlerts(t *testing.T) {
+	fake := newFakeOpsGenie(t)
+	fake.handle("GET /v2/alerts", func(r *http.Request) any {
+		// A single page of alerts.
+		if offset := r.URL.Query().Get("offset"); offset != "" && offset != "0" {
+			return []alert.Alert{}
+		}
+		return []alert.Alert{
+			{Id: "alert-1", Priority: alert.P1, Message: "first"},
+			{Id: "alert-2", Priority: alert.P3, Message: "second", Acknowledged: true},
+		}
+	})
+
+	alertClient, err := NewAlertClient(fake.apiURL(), fakeAPIKeyEnvVar, 0, time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to create alert client: %v", err)
+	}
+	query, err := parseQuery("status: open", "", nil)
+	if err != nil {
+		t.Fatalf("failed to parse query: %v", err)
+	}
+
+	s := &Service{
+		alertClient: alertClient,
+		queries:     []teamQuery{query},
+	}
+
+	alerts, err := s.ListAlerts(context.Background())
+	if err != nil {
+		t.Fatalf("failed to list alerts: %v", err)
+	}
+
+	if len(alerts) != 2 || alerts[0].Id != "alert-1" || alerts[1].Id != "alert-2" || !alerts[1].Acknowledged {
+		t.Errorf("expected alerts alert-1 and acknowledged alert-2, got %+v", alerts)
+	}
+	queries := fake.queries("/v2/alerts")
+	if len(queries) == 0 || queries[0] != "status: open" {
+		t.Errorf("expected the configured query to run, got %q", queries)
+	}
+
+	// Listing the alerts neither fetches nor acknowledges them.
+	fake.mu.Lock()
+	requests := len(fake.requests)
+	fake.mu.Unlock()
+	if requests != len(queries) {
+		t.Errorf("expected only the alerts to be listed, got %d requests for %d queries", requests, len(queries))
+	}
+}
@@ -0,0 +1,189 @@
+package opsgenie
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+	"github.com/opsgenie/opsgenie-go-sdk-v2/incident"
+)
+
+// stateStore records the IDs of the alerts, and incidents, already dispatched
+// to sessions in a file, so that they are not investigated again after a
+// restart. A store without file only records them in memory, and a nil store
+// records nothing.
+type stateStore struct {
+	mu   sync.Mutex
+	path string
+	// ids holds the dispatch time of the alerts, keyed by alert ID.
+	ids map[string]time.Time
+}
+
+// newStateStore creates an empty state store saved to the given file, or only
+// kept in memory if the path is empty.
+func newStateStore(path string) *stateStore {
+	return &stateStore{
+		path: path,
+		ids:  make(map[string]time.Time),
+	}
+}
+
+// loadState loads the state store from the given file. The store is empty if
+// the file does not exist yet.
+func loadState(path string) (*stateStore, error) {
+	s := newStateStore(path)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	err = json.Unmarshal(data, &s.ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+// contains returns true if the alert was already dispatched.
+func (s *stateStore) contains(id string) bool {
+	if s == nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.ids[id]
+	return ok
+}
+
+// add records the alert as dispatched.
+func (s *stateStore) add(id string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ids[id] = time.Now()
+}
+
+// claim records the alert as dispatched, unless it already was. It returns
+// true if the alert was recorded.
+func (s *stateStore) claim(id string) bool {
+	if s == nil {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.ids[id]; ok {
+		return false
+	}
+	s.ids[id] = time.Now()
+
+	return true
+}
+
+// remove forgets the alert, so that it can be dispatched again.
+func (s *stateStore) remove(id string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.ids, id)
+}
+
+// evictBefore forgets the alerts dispatched before the given time.
+func (s *stateStore) evictBefore(t time.Time) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, dispatchedAt := range s.ids {
+		if dispatchedAt.Before(t) {
+			delete(s.ids, id)
+		}
+	}
+}
+
+// evict forgets the alerts and incidents which are not in the given ones
+// anymore, so that the state does not grow forever.
+func (s *stateStore) evict(alerts []alert.Alert, incidents []incident.Incident) {
+	if s == nil {
+		return
+	}
+
+	current := make(map[string]bool, len(alerts)+len(incidents))
+	for _, a := range alerts {
+		current[a.Id] = true
+	}
+	for _, i := range incidents {
+		current[i.Id] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id := range s.ids {
+		if !current[id] {
+			delete(s.ids, id)
+		}
+	}
+}
+
+// save writes the state to its file. The file is replaced atomically so that
+// a crash does not corrupt it.
+func (s *stateStore) save() error {
+	if s == nil || s.path == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	data, err := json.Marshal(s.ids)
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create state file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(data)
+	if err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	err = tmpFile.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	err = os.Rename(tmpFile.Name(), s.path)
+	if err != nil {
+		return fmt.Errorf("failed to replace state file: %w", err)
+	}
+
+	return nil
+}
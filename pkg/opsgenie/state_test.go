@@ -0,0 +1,117 @@
+package opsgenie
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+	"github.com/opsgenie/opsgenie-go-sdk-v2/incident"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	// The store is empty until the file exists.
+	s, err := loadState(path)
+	if err != nil {
+		t.Fatalf("failed to load missing state file: %v", err)
+	}
+	if s.contains("alert-1") {
+		t.Error("expected an empty state")
+	}
+
+	s.add("alert-1")
+	s.add("incident-1")
+	err = s.save()
+	if err != nil {
+		t.Fatalf("failed to save state: %v", err)
+	}
+
+	loaded, err := loadState(path)
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	for _, id := range []string{"alert-1", "incident-1"} {
+		if !loaded.contains(id) {
+			t.Errorf("expected %s to be loaded", id)
+		}
+	}
+	if loaded.contains("alert-2") {
+		t.Error("expected alert-2 not to be loaded")
+	}
+
+	// The file is replaced without leaving temporary files behind.
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("failed to read state directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the state file, got %d files", len(entries))
+	}
+}
+
+func TestLoadStateInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	err := os.WriteFile(path, []byte("not json"), 0600)
+	if err != nil {
+		t.Fatalf("failed to write state file: %v", err)
+	}
+
+	_, err = loadState(path)
+	if err == nil {
+		t.Error("expected an error loading an invalid state file")
+	}
+}
+
+func TestStateEvict(t *testing.T) {
+	s := newStateStore("")
+	for _, id := range []string{"alert-1", "alert-2", "incident-1", "incident-2"} {
+		s.add(id)
+	}
+
+	s.evict([]alert.Alert{{Id: "alert-1"}}, []incident.Incident{{Id: "incident-1"}})
+
+	testCases := map[string]bool{
+		"alert-1":    true,
+		"alert-2":    false,
+		"incident-1": true,
+		"incident-2": false,
+	}
+	for id, expected := range testCases {
+		if s.contains(id) != expected {
+			t.Errorf("expected %s to be kept: %t", id, expected)
+		}
+	}
+}
+
+func TestStateEvictBefore(t *testing.T) {
+	s := newStateStore("")
+	s.ids["old"] = time.Now().Add(-2 * time.Hour)
+	s.add("recent")
+
+	s.evictBefore(time.Now().Add(-time.Hour))
+
+	if s.contains("old") {
+		t.Error("expected the old alert to be evicted")
+	}
+	if !s.contains("recent") {
+		t.Error("expected the recent alert to be kept")
+	}
+}
+
+func TestNilState(t *testing.T) {
+	var s *stateStore
+	s.add("alert-1")
+
+	if s.contains("alert-1") {
+		t.Error("expected a nil state to record nothing")
+	}
+	if !s.claim("alert-1") {
+		t.Error("expected a nil state to claim every alert")
+	}
+	if err := s.save(); err != nil {
+		t.Errorf("expected saving a nil state to be a no-op, got %v", err)
+	}
+}
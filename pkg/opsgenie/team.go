@@ -0,0 +1,46 @@
+package opsgenie
+
+import (
+	"strings"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+)
+
+// sourceTeamKey is the tag prefix identifying the team whose query fetched
+// an alert.
+const sourceTeamKey = "oka-team"
+
+// AlertMatchesTeam returns true if the given team is one of the alert's
+// responders. Team names are compared case-insensitively.
+func AlertMatchesTeam(a *alert.GetAlertResult, team string) bool {
+	for _, responder := range a.Responders {
+		if responder.Type == alert.TeamResponder && strings.EqualFold(responder.Name, team) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SourceTeam returns the team whose query fetched the alert, from its
+// "oka-team:<name>" tag, or an empty string if the alert was not tagged.
+func SourceTeam(a *alert.GetAlertResult) string {
+	for _, tag := range a.Tags {
+		if team, ok := strings.CutPrefix(tag, sourceTeamKey+":"); ok {
+			return team
+		}
+	}
+
+	return ""
+}
+
+// tagSourceTeam tags the alert with the team whose query fetched it, so that
+// its investigation is scoped to the team. Only the fetched alert is tagged,
+// not the alert in OpsGenie.
+func tagSourceTeam(a *alert.GetAlertResult, team string) {
+	if team == "" || SourceTeam(a) != "" {
+		return
+	}
+
+	a.Tags = append(a.Tags, sourceTeamKey+":"+team)
+}
@@ -0,0 +1,206 @@
+package opsgenie
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+	"golang.org/x/time/rate"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+func TestParseQueries(t *testing.T) {
+	const defaultQuery = `responder: "{{ .Team }}" AND status: open`
+
+	testCases := []struct {
+		name            string
+		opsgenie        config.OpsGenie
+		expectedQueries []string
+		expectedErr     string
+	}{
+		{
+			name:            "single team",
+			opsgenie:        config.OpsGenie{QueryString: defaultQuery, Team: "team-a"},
+			expectedQueries: []string{`responder: "team-a" AND status: open`},
+		},
+		{
+			name: "multiple teams",
+			opsgenie: config.OpsGenie{
+				QueryString: defaultQuery,
+				Team:        "ignored",
+				Teams: []config.OpsGenieTeam{
+					{Name: "team-a"},
+					{Name: "team-b", QueryString: `responder: "{{ .Team }}" AND priority: P1`},
+				},
+			},
+			expectedQueries: []string{
+				`responder: "team-a" AND status: open`,
+				`responder: "team-b" AND priority: P1`,
+			},
+		},
+		{
+			name:            "no team",
+			opsgenie:        config.OpsGenie{QueryString: "status: open"},
+			expectedQueries: []string{"status: open"},
+		},
+		{
+			name: "invalid team query",
+			opsgenie: config.OpsGenie{
+				QueryString: defaultQuery,
+				Teams: []config.OpsGenieTeam{
+					{Name: "team-a"},
+					{Name: "team-b", QueryString: "status: {{ .Status "},
+				},
+			},
+			expectedErr: "team team-b: failed to parse OpsGenie query template",
+		},
+		{
+			name:        "missing team",
+			opsgenie:    config.OpsGenie{QueryString: defaultQuery},
+			expectedErr: "team cannot be empty",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			queries, err := parseQueries(&config.Config{OpsGenie: &tc.opsgenie})
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to parse queries: %v", err)
+			}
+
+			var rendered []string
+			for _, q := range queries {
+				query, err := q.render(time.Now())
+				if err != nil {
+					t.Fatalf("failed to render query %s: %v", q, err)
+				}
+				rendered = append(rendered, query)
+			}
+
+			if !slices.Equal(rendered, tc.expectedQueries) {
+				t.Errorf("expected queries %q, got %q", tc.expectedQueries, rendered)
+			}
+		})
+	}
+}
+
+func TestDispatchAlertsMultipleTeams(t *testing.T) {
+	teamAlerts := map[string][]alert.Alert{
+		`responder: "team-a"`: {{Id: "alert-1", Priority: alert.P3}, {Id: "alert-2", Priority: alert.P3}},
+		`responder: "team-b"`: {{Id: "alert-2", Priority: alert.P3}, {Id: "alert-3", Priority: alert.P3}},
+	}
+
+	fake := newFakeOpsGenie(t)
+	fake.handle("GET /v2/alerts", func(r *http.Request) any {
+		// A single page of alerts per team.
+		if offset := r.URL.Query().Get("offset"); offset != "" && offset != "0" {
+			return []alert.Alert{}
+		}
+		return teamAlerts[r.URL.Query().Get("query")]
+	})
+	fake.handle("GET /v2/alerts/{id}", func(r *http.Request) any {
+		return alert.GetAlertResult{Id: r.PathValue("id"), Priority: alert.P3, Tags: []string{"cluster:wc1"}}
+	})
+
+	alertClient, err := NewAlertClient(fake.apiURL(), fakeAPIKeyEnvVar, 0, time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to create alert client: %v", err)
+	}
+	queries, err := parseQueries(&config.Config{OpsGenie: &config.OpsGenie{
+		QueryString: `responder: "{{ .Team }}"`,
+		Teams:       []config.OpsGenieTeam{{Name: "team-a"}, {Name: "team-b"}},
+	}})
+	if err != nil {
+		t.Fatalf("failed to parse queries: %v", err)
+	}
+
+	s := &Service{
+		alertClient:      alertClient,
+		fetchConcurrency: 1,
+		fetchLimiter:     rate.NewLimiter(rate.Inf, 0),
+	}
+
+	ctx := context.Background()
+	alerts, teams, err := s.listAlerts(ctx, queries)
+	if err != nil {
+		t.Fatalf("failed to list alerts: %v", err)
+	}
+
+	// The alert returned by both queries is listed once, for the first team.
+	var ids []string
+	for _, a := range alerts {
+		ids = append(ids, a.Id)
+	}
+	if !slices.Equal(ids, []string{"alert-1", "alert-2", "alert-3"}) {
+		t.Errorf("expected the alerts of both teams to be merged, got %q", ids)
+	}
+
+	queryChan := make(chan any, len(alerts))
+	count := s.dispatchAlerts(ctx, alerts, teams, queryChan)
+	if count != 3 {
+		t.Fatalf("expected 3 dispatched alerts, got %d", count)
+	}
+	close(queryChan)
+
+	expectedTeams := map[string]string{"alert-1": "team-a", "alert-2": "team-a", "alert-3": "team-b"}
+	for payload := range queryChan {
+		a, ok := payload.(*alert.GetAlertResult)
+		if !ok {
+			t.Fatalf("expected an alert to be dispatched, got %T", payload)
+		}
+		if team := SourceTeam(a); team != expectedTeams[a.Id] {
+			t.Errorf("expected %s to be tagged with team %q, got %q", a.Id, expectedTeams[a.Id], team)
+		}
+		if !slices.Contains(a.Tags, "cluster:wc1") {
+			t.Errorf("expected %s to keep its tags, got %q", a.Id, a.Tags)
+		}
+	}
+}
+
+func TestTagSourceTeam(t *testing.T) {
+	testCases := []struct {
+		name         string
+		tags         []string
+		team         string
+		expectedTeam string
+	}{
+		{
+			name:         "untagged alert",
+			tags:         []string{"cluster:wc1"},
+			team:         "team-a",
+			expectedTeam: "team-a",
+		},
+		{
+			name:         "already tagged alert",
+			tags:         []string{"oka-team:team-b"},
+			team:         "team-a",
+			expectedTeam: "team-b",
+		},
+		{
+			name: "no team",
+			tags: []string{"cluster:wc1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &alert.GetAlertResult{Tags: slices.Clone(tc.tags)}
+			tagSourceTeam(a, tc.team)
+
+			if team := SourceTeam(a); team != tc.expectedTeam {
+				t.Errorf("expected source team %q, got %q", tc.expectedTeam, team)
+			}
+		})
+	}
+}
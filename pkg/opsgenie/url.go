@@ -0,0 +1,22 @@
+package opsgenie
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AlertURL returns the URL of the alert with the given ID in the OpsGenie web
+// UI of the account with the given domain, e.g. "example" for
+// example.app.opsgenie.com. The UI is hosted on the region of the API URL,
+// e.g. example.app.eu.opsgenie.com for api.eu.opsgenie.com. It returns an
+// empty string if the domain is not set.
+func AlertURL(apiURL, domain, id string) string {
+	if domain == "" || id == "" {
+		return ""
+	}
+
+	host := strings.TrimPrefix(apiURL, "https://")
+	host = strings.TrimPrefix(host, "api.")
+
+	return fmt.Sprintf("https://%s.app.%s/alert/detail/%s/details", domain, host, id)
+}
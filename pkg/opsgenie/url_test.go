@@ -0,0 +1,57 @@
+package opsgenie
+
+import (
+	"testing"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/client"
+)
+
+func TestAlertURL(t *testing.T) {
+	testCases := []struct {
+		name     string
+		apiURL   string
+		domain   string
+		id       string
+		expected string
+	}{
+		{
+			name:     "us region",
+			apiURL:   string(client.API_URL),
+			domain:   "example",
+			id:       "alert-1",
+			expected: "https://example.app.opsgenie.com/alert/detail/alert-1/details",
+		},
+		{
+			name:     "eu region",
+			apiURL:   string(client.API_URL_EU),
+			domain:   "example",
+			id:       "alert-1",
+			expected: "https://example.app.eu.opsgenie.com/alert/detail/alert-1/details",
+		},
+		{
+			name:     "api url with scheme",
+			apiURL:   "https://" + string(client.API_URL_EU),
+			domain:   "example",
+			id:       "alert-1",
+			expected: "https://example.app.eu.opsgenie.com/alert/detail/alert-1/details",
+		},
+		{
+			name:   "no domain",
+			apiURL: string(client.API_URL),
+			id:     "alert-1",
+		},
+		{
+			name:   "no alert ID",
+			apiURL: string(client.API_URL),
+			domain: "example",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if url := AlertURL(tc.apiURL, tc.domain, tc.id); url != tc.expected {
+				t.Errorf("expected URL %q, got %q", tc.expected, url)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package opsgenie
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/health"
+)
+
+// Modes of receiving the alerts from OpsGenie.
+const (
+	ModePoll    = "poll"
+	ModeWebhook = "webhook"
+)
+
+const (
+	// webhookSecretHeader is the header holding the shared secret of the
+	// webhook requests.
+	webhookSecretHeader = "X-Webhook-Secret"
+
+	// webhookCreateAction is the webhook action sent when an alert is created.
+	webhookCreateAction = "Create"
+
+	// maxWebhookPayloadSize is the maximum size of a webhook payload.
+	maxWebhookPayloadSize = 1 << 20
+
+	// webhookDedupeWindow is how long the alerts received by the webhook are
+	// recorded, so that an alert posted again is not investigated twice.
+	webhookDedupeWindow = 24 * time.Hour
+)
+
+// webhookPayload is the payload of the OpsGenie webhook integration.
+// Reference: https://support.atlassian.com/opsgenie/docs/opsgenie-edge-connector-alert-action-data/
+type webhookPayload struct {
+	Action string `json:"action"`
+	Alert  struct {
+		AlertID  string `json:"alertId"`
+		Message  string `json:"message"`
+		Priority string `json:"priority"`
+		Source   string `json:"source"`
+		Username string `json:"username"`
+	} `json:"alert"`
+}
+
+// WebhookServer receives the alerts posted by the OpsGenie webhook
+// integration, as an alternative to polling OpsGenie with Service.
+type WebhookServer struct {
+	actionSource string
+	actionUser   string
+	address      string
+	alertClient  *AlertClient
+	minPriority  string
+	secret       string
+	state        *stateStore
+	teams        []string
+}
+
+// NewWebhookServer creates a new OpsGenie webhook server. The shared secret of
+// the webhook requests is read from the configured environment variable.
+func NewWebhookServer(conf *config.Config) (*WebhookServer, error) {
+	alertClient, err := NewAlertClient(conf.OpsGenie.APIUrl, conf.OpsGenie.EnvVar, conf.OpsGenie.MaxRetries, conf.OpsGenie.RetryBackoff)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := os.Getenv(conf.OpsGenie.WebhookSecretEnvVar)
+	if secret == "" {
+		return nil, fmt.Errorf("webhook secret is not set in environment variable %s", conf.OpsGenie.WebhookSecretEnvVar)
+	}
+
+	// The alerts received are recorded in memory if no state file is
+	// configured.
+	state := newStateStore("")
+	if conf.OpsGenie.StateFile != "" {
+		state, err = loadState(conf.OpsGenie.StateFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Alerts are only investigated if one of the configured teams responds
+	// to them, as in poll mode where the team queries fetch them.
+	var teams []string
+	for _, team := range conf.OpsGenie.GetTeams() {
+		if team.Name != "" {
+			teams = append(teams, team.Name)
+		}
+	}
+
+	s := &WebhookServer{
+		actionSource: conf.OpsGenie.ActionSource,
+		actionUser:   conf.OpsGenie.ActionUser,
+		address:      conf.OpsGenie.WebhookAddress,
+		alertClient:  alertClient,
+		minPriority:  conf.OpsGenie.MinPriority,
+		secret:       secret,
+		state:        state,
+		teams:        teams,
+	}
+
+	return s, nil
+}
+
+// AlertClient returns the OpsGenie alert client used by the webhook server.
+func (s *WebhookServer) AlertClient() *AlertClient {
+	return s.alertClient
+}
+
+// Start starts the webhook server, which sends the created alerts to the
+// provided channel, until the context is canceled. It returns an error if the
+// server fails, e.g. to listen on its address.
+func (s *WebhookServer) Start(ctx context.Context, queryChan chan<- any) error {
+	slog.Info("OpsGenie webhook server started", "address", s.address)
+	defer slog.Info("OpsGenie webhook server stopped")
+
+	// Alerts are dispatched in the background, once the request is answered.
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	server := &http.Server{
+		Addr:              s.address,
+		Handler:           s.handler(ctx, queryChan, &wg),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		<-ctx.Done()
+		err := server.Shutdown(context.Background())
+		if err != nil {
+			slog.Warn("Failed to shut down OpsGenie webhook server", "error", err)
+		}
+	}()
+
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return fmt.Errorf("failed to listen for OpsGenie webhook: %w", err)
+	}
+	// OpsGenie cannot be probed in webhook mode, the server is ready once
+	// it can receive the alerts.
+	health.SetReady(health.CheckOpsGenie, true)
+
+	err = server.Serve(listener)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve OpsGenie webhook: %w", err)
+	}
+
+	return nil
+}
+
+// handler returns the handler of the webhook requests, dispatching the alerts
+// in the background tracked by the wait group.
+func (s *WebhookServer) handler(ctx context.Context, queryChan chan<- any, wg *sync.WaitGroup) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /webhook", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := s.handleWebhook(w, r)
+		if !ok {
+			return
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.dispatchAlert(ctx, id, queryChan)
+		}()
+	})
+
+	return mux
+}
+
+// handleWebhook validates and parses a webhook request. It returns the ID of
+// the alert to investigate, if any.
+func (s *WebhookServer) handleWebhook(w http.ResponseWriter, r *http.Request) (string, bool) {
+	secret := r.Header.Get(webhookSecretHeader)
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(s.secret)) != 1 {
+		http.Error(w, "invalid secret", http.StatusUnauthorized)
+		return "", false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookPayloadSize))
+	if err != nil {
+		http.Error(w, "failed to read payload", http.StatusBadRequest)
+		return "", false
+	}
+
+	var payload webhookPayload
+	err = json.Unmarshal(body, &payload)
+	if err != nil || payload.Alert.AlertID == "" {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return "", false
+	}
+
+	// Only created alerts are investigated, other actions are acknowledged
+	// but ignored.
+	w.WriteHeader(http.StatusAccepted)
+
+	if payload.Action != webhookCreateAction {
+		slog.Debug("Ignoring OpsGenie webhook action", "action", payload.Action, "alert.id", payload.Alert.AlertID)
+		return "", false
+	}
+
+	if isOwn(payload.Alert.Source, payload.Alert.Username, s.actionSource, s.actionUser) {
+		slog.Warn("Skipping alert created by OKA", "alert.id", payload.Alert.AlertID, "source", payload.Alert.Source, "owner", payload.Alert.Username)
+		return "", false
+	}
+
+	if !meetsMinPriority(alert.Priority(payload.Alert.Priority), s.minPriority) {
+		slog.Debug("Skipping alert below the minimum priority", "alert.id", payload.Alert.AlertID, "priority", payload.Alert.Priority, "min_priority", s.minPriority)
+		return "", false
+	}
+
+	// OpsGenie may post an alert again, e.g. when retrying a request.
+	s.state.evictBefore(time.Now().Add(-webhookDedupeWindow))
+	if !s.state.claim(payload.Alert.AlertID) {
+		slog.Debug("Skipping alert already received", "alert.id", payload.Alert.AlertID)
+		return "", false
+	}
+
+	slog.Info("Received alert from OpsGenie webhook", "alert.id", payload.Alert.AlertID, "message", payload.Alert.Message)
+
+	return payload.Alert.AlertID, true
+}
+
+// dispatchAlert fetches the details of the alert and sends it to the provided
+// channel, tagged with the team responding to it. Acknowledged alerts and
+// alerts none of the configured teams respond to are skipped. The alert can be
+// received again if it cannot be fetched or sent.
+func (s *WebhookServer) dispatchAlert(ctx context.Context, id string, queryChan chan<- any) {
+	handled := false
+	defer func() {
+		if !handled {
+			s.state.remove(id)
+		}
+		s.saveState()
+	}()
+
+	a, err := s.alertClient.GetAlert(ctx, id)
+	if err != nil {
+		slog.Warn("Failed to get alert from OpsGenie", "alert.id", id, "error", err)
+		return
+	}
+
+	if a.Acknowledged {
+		slog.Debug("Skipping acknowledged alert", "alert.id", id)
+		handled = true
+		return
+	}
+
+	if len(s.teams) > 0 {
+		team, ok := s.alertTeam(a)
+		if !ok {
+			slog.Debug("Skipping alert of other teams", "alert.id", id, "teams", s.teams)
+			handled = true
+			return
+		}
+		tagSourceTeam(a, team)
+	}
+
+	select {
+	case <-ctx.Done():
+	case queryChan <- a:
+		handled = true
+	}
+}
+
+// alertTeam returns the first configured team responding to the alert.
+func (s *WebhookServer) alertTeam(a *alert.GetAlertResult) (string, bool) {
+	for _, team := range s.teams {
+		if AlertMatchesTeam(a, team) {
+			return team, true
+		}
+	}
+
+	return "", false
+}
+
+// saveState saves the state of the received alerts, logging failures.
+func (s *WebhookServer) saveState() {
+	err := s.state.save()
+	if err != nil {
+		slog.Warn("Failed to save the state of received alerts", "error", err)
+	}
+}
@@ -0,0 +1,200 @@
+package opsgenie
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+)
+
+// sampleWebhookPayload is a sample payload of the OpsGenie webhook
+// integration.
+const sampleWebhookPayload = `{
+	"action": "Create",
+	"alert": {
+		"alertId": "alert-1",
+		"message": "KubeAPIDown",
+		"priority": "P1",
+		"source": "prometheus",
+		"username": "System"
+	}
+}`
+
+// newTestWebhookServer returns a webhook server fetching the alerts from a
+// fake OpsGenie API, along with the URL of its webhook endpoint and the
+// channel the alerts are sent to.
+func newTestWebhookServer(t *testing.T, a alert.GetAlertResult, teams []string) (string, chan any) {
+	t.Helper()
+
+	fake := newFakeOpsGenie(t)
+	fake.handle("GET /v2/alerts/{id}", func(r *http.Request) any {
+		return a
+	})
+
+	alertClient, err := NewAlertClient(fake.apiURL(), fakeAPIKeyEnvVar, 0, time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to create alert client: %v", err)
+	}
+
+	s := &WebhookServer{
+		alertClient: alertClient,
+		secret:      "secret",
+		state:       newStateStore(""),
+		teams:       teams,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	queryChan := make(chan any, 1)
+	var wg sync.WaitGroup
+
+	server := httptest.NewServer(s.handler(ctx, queryChan, &wg))
+	t.Cleanup(func() {
+		server.Close()
+		cancel()
+		wg.Wait()
+	})
+
+	return server.URL + "/webhook", queryChan
+}
+
+// postWebhook posts the payload with the secret to the webhook endpoint.
+func postWebhook(t *testing.T, url, secret, payload string) int {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(payload))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set(webhookSecretHeader, secret)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to post webhook: %v", err)
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode
+}
+
+// receiveAlert returns the alert sent to the channel, or nil if none is sent
+// in time.
+func receiveAlert(t *testing.T, queryChan <-chan any) *alert.GetAlertResult {
+	t.Helper()
+
+	select {
+	case payload := <-queryChan:
+		a, ok := payload.(*alert.GetAlertResult)
+		if !ok {
+			t.Fatalf("expected an alert, got %T", payload)
+		}
+		return a
+	case <-time.After(500 * time.Millisecond):
+		return nil
+	}
+}
+
+func TestWebhookDispatchesAlert(t *testing.T) {
+	a := alert.GetAlertResult{
+		Id:      "alert-1",
+		Message: "KubeAPIDown",
+		Responders: []alert.Responder{
+			{Type: alert.TeamResponder, Name: "Team-A"},
+		},
+	}
+	url, queryChan := newTestWebhookServer(t, a, []string{"team-a"})
+
+	status := postWebhook(t, url, "secret", sampleWebhookPayload)
+	if status != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d", http.StatusAccepted, status)
+	}
+
+	received := receiveAlert(t, queryChan)
+	if received == nil {
+		t.Fatal("expected the alert to be sent to the channel")
+	}
+	if received.Id != "alert-1" {
+		t.Errorf("expected alert alert-1, got %s", received.Id)
+	}
+	if team := SourceTeam(received); team != "team-a" {
+		t.Errorf("expected the alert to be tagged with team-a, got %q", team)
+	}
+
+	// The alert is only investigated once if posted again.
+	postWebhook(t, url, "secret", sampleWebhookPayload)
+	if received := receiveAlert(t, queryChan); received != nil {
+		t.Errorf("expected the alert posted again to be skipped, got %s", received.Id)
+	}
+}
+
+func TestWebhookSkipsAlert(t *testing.T) {
+	testCases := []struct {
+		name    string
+		alert   alert.GetAlertResult
+		teams   []string
+		secret  string
+		payload string
+		status  int
+	}{
+		{
+			name:    "invalid secret",
+			alert:   alert.GetAlertResult{Id: "alert-1"},
+			secret:  "invalid",
+			payload: sampleWebhookPayload,
+			status:  http.StatusUnauthorized,
+		},
+		{
+			name:    "invalid payload",
+			alert:   alert.GetAlertResult{Id: "alert-1"},
+			secret:  "secret",
+			payload: `{"action": "Create"}`,
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "other action",
+			alert:   alert.GetAlertResult{Id: "alert-1"},
+			secret:  "secret",
+			payload: strings.Replace(sampleWebhookPayload, `"Create"`, `"Close"`, 1),
+			status:  http.StatusAccepted,
+		},
+		{
+			name:    "acknowledged alert",
+			alert:   alert.GetAlertResult{Id: "alert-1", Acknowledged: true},
+			secret:  "secret",
+			payload: sampleWebhookPayload,
+			status:  http.StatusAccepted,
+		},
+		{
+			name: "alert of another team",
+			alert: alert.GetAlertResult{
+				Id: "alert-1",
+				Responders: []alert.Responder{
+					{Type: alert.TeamResponder, Name: "team-b"},
+				},
+			},
+			teams:   []string{"team-a"},
+			secret:  "secret",
+			payload: sampleWebhookPayload,
+			status:  http.StatusAccepted,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			url, queryChan := newTestWebhookServer(t, tc.alert, tc.teams)
+
+			status := postWebhook(t, url, tc.secret, tc.payload)
+			if status != tc.status {
+				t.Errorf("expected status %d, got %d", tc.status, status)
+			}
+
+			if received := receiveAlert(t, queryChan); received != nil {
+				t.Errorf("expected the alert to be skipped, got %s", received.Id)
+			}
+		})
+	}
+}
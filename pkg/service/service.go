@@ -1,22 +1,92 @@
 // Package service provides a simple way to manage and wait for goroutines.
 package service
 
-import "sync"
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
 
-// wg is a WaitGroup used to wait for all running services to complete.
-var wg sync.WaitGroup
+// ErrTimeout is returned by WaitTimeout when the services did not complete in
+// time.
+var ErrTimeout = errors.New("services did not complete in time")
+
+var (
+	// wg is a WaitGroup used to wait for all running services to complete.
+	wg sync.WaitGroup
+
+	// errs are the errors returned by the services started with RunE.
+	errs   []error
+	errsMu sync.Mutex
+)
 
 // Run starts a new goroutine that executes the given function.
 // It uses a WaitGroup to track the number of running services.
 func Run(f func()) {
+	RunE(func() error {
+		f()
+		return nil
+	})
+}
+
+// RunE starts a new goroutine that executes the given function, like Run. The
+// error returned by the function is reported by Wait.
+func RunE(f func() error) {
 	wg.Add(1)
-	go func(s func()) {
+	go func(s func() error) {
 		defer wg.Done()
-		s()
+		err := s()
+		if err != nil {
+			errsMu.Lock()
+			errs = append(errs, err)
+			errsMu.Unlock()
+		}
 	}(f)
 }
 
-// Wait blocks until all running services have completed.
-func Wait() {
+// Wait blocks until all running services have completed, and returns the
+// errors of the services joined.
+func Wait() error {
 	wg.Wait()
+
+	return collectErrors()
+}
+
+// WaitTimeout blocks until all running services have completed, or until the
+// timeout elapses once the context is done, and returns the errors of the
+// services joined. ErrTimeout is returned if the services did not complete in
+// time. A zero timeout waits indefinitely, like Wait.
+func WaitTimeout(ctx context.Context, timeout time.Duration) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return collectErrors()
+	case <-ctx.Done():
+	}
+
+	if timeout <= 0 {
+		<-done
+		return collectErrors()
+	}
+
+	select {
+	case <-done:
+		return collectErrors()
+	case <-time.After(timeout):
+		return errors.Join(ErrTimeout, collectErrors())
+	}
+}
+
+// collectErrors returns the errors of the services joined.
+func collectErrors() error {
+	errsMu.Lock()
+	defer errsMu.Unlock()
+
+	return errors.Join(errs...)
 }
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// drain waits for the services started by the test and forgets their errors
+// once it completes, the services being global.
+func drain(t *testing.T) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		wg.Wait()
+
+		errsMu.Lock()
+		errs = nil
+		errsMu.Unlock()
+	})
+}
+
+func TestWaitTimeout(t *testing.T) {
+	testCases := []struct {
+		name     string
+		duration time.Duration
+		timeout  time.Duration
+	}{
+		{
+			name:     "services drained in time",
+			duration: 100 * time.Millisecond,
+			timeout:  5 * time.Second,
+		},
+		{
+			name:     "no timeout",
+			duration: 100 * time.Millisecond,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			drain(t)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			Run(func() {
+				<-ctx.Done()
+				time.Sleep(tc.duration)
+			})
+
+			cancel()
+			start := time.Now()
+			err := WaitTimeout(ctx, tc.timeout)
+			if err != nil {
+				t.Errorf("expected the services to complete in time, got %v", err)
+			}
+			if elapsed := time.Since(start); elapsed < tc.duration {
+				t.Errorf("expected to wait for the service to complete, took %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestWaitTimeoutBeforeCancel(t *testing.T) {
+	drain(t)
+
+	// The timeout only starts once the context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	Run(func() {
+		time.Sleep(100 * time.Millisecond)
+	})
+
+	err := WaitTimeout(ctx, 10*time.Millisecond)
+	if err != nil {
+		t.Errorf("expected the services to be waited for until the context is done, got %v", err)
+	}
+}
+
+func TestWaitErrors(t *testing.T) {
+	drain(t)
+
+	errA := errors.New("service a failed")
+	errB := errors.New("service b failed")
+	RunE(func() error { return errA })
+	RunE(func() error { return nil })
+	RunE(func() error { return errB })
+	Run(func() {})
+
+	err := Wait()
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("expected the errors of both failing services, got %v", err)
+	}
+}
+
+func TestWaitNoErrors(t *testing.T) {
+	drain(t)
+
+	RunE(func() error { return nil })
+	Run(func() {})
+
+	err := Wait()
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestWaitTimeoutErrors(t *testing.T) {
+	drain(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	serviceErr := errors.New("service failed")
+	RunE(func() error {
+		<-ctx.Done()
+		return serviceErr
+	})
+
+	cancel()
+	err := WaitTimeout(ctx, 5*time.Second)
+	if !errors.Is(err, serviceErr) {
+		t.Errorf("expected error %v, got %v", serviceErr, err)
+	}
+}
+
+// TestWaitTimeoutSlowService must run last, the WaitGroup of the abandoned
+// service cannot be reused safely.
+func TestWaitTimeoutSlowService(t *testing.T) {
+	drain(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	Run(func() {
+		<-release
+	})
+	t.Cleanup(func() { close(release) })
+
+	cancel()
+	start := time.Now()
+	err := WaitTimeout(ctx, 50*time.Millisecond)
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected error %v, got %v", ErrTimeout, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected to return once the timeout elapsed, took %s", elapsed)
+	}
+}
@@ -0,0 +1,46 @@
+package session
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/opsgenie"
+)
+
+// acknowledge acknowledges the alert investigated by the session in OpsGenie,
+// adding the configured acknowledgement note. Sessions whose payload is not an
+// alert, e.g. an incident, have nothing to acknowledge.
+func acknowledge(ctx context.Context, alertClient *opsgenie.AlertClient, s *Session, conf *config.Config) error {
+	a, ok := opsgenieAlert(s.alert)
+	if !ok {
+		return nil
+	}
+
+	noteData := opsgenie.AckNoteData{
+		Alert:       a,
+		SessionID:   s.ID,
+		SlackHandle: conf.SlackHandle,
+	}
+	note, err := opsgenie.TemplateAckNote(conf.OpsGenie.AckNoteTemplate, noteData)
+	if err != nil {
+		return err
+	}
+
+	_, err = alertClient.AcknowledgeAlert(ctx, a.Id, conf.OpsGenie.ActionUser, note, conf.OpsGenie.ActionSource)
+	return err
+}
+
+// unacknowledge unacknowledges the alert investigated by the session in
+// OpsGenie, so that humans know the investigation failed. Sessions whose
+// payload is not an alert have nothing to unacknowledge.
+func unacknowledge(ctx context.Context, alertClient *opsgenie.AlertClient, s *Session, sessionErr error, conf *config.Config) error {
+	a, ok := opsgenieAlert(s.alert)
+	if !ok {
+		return nil
+	}
+
+	note := fmt.Sprintf("OKA investigation failed (session %s): %s", s.ID, sessionErr)
+	_, err := alertClient.UnacknowledgeAlert(ctx, a.Id, conf.OpsGenie.ActionUser, note, conf.OpsGenie.ActionSource)
+	return err
+}
@@ -0,0 +1,50 @@
+package session
+
+import (
+	"context"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+	"github.com/prometheus/common/version"
+
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/mcp/alerts"
+	"github.com/giantswarm/oka/pkg/mcp/client"
+	"github.com/giantswarm/oka/pkg/opsgenie"
+)
+
+// opsgenieAlert returns the OpsGenie alert carried by a session payload, if
+// the payload is one, including when it is investigated with its incident.
+func opsgenieAlert(payload any) (*alert.GetAlertResult, bool) {
+	switch p := payload.(type) {
+	case *alert.GetAlertResult:
+		return p, p != nil
+	case *opsgenie.IncidentAlert:
+		return p.GetAlertResult, p != nil && p.GetAlertResult != nil
+	}
+
+	return nil, false
+}
+
+// alertURL returns the link to the alert carried by a session payload in the
+// OpsGenie web UI, or an empty string if the payload is not an alert or the
+// link cannot be built.
+func alertURL(payload any, conf *config.Config) string {
+	a, ok := opsgenieAlert(payload)
+	if !ok {
+		return ""
+	}
+
+	return opsgenie.AlertURL(conf.OpsGenie.APIUrl, conf.OpsGenie.Domain, a.Id)
+}
+
+// registerAlertsServer registers the in-process MCP server acting on the alert
+// carried by the session payload, if the payload is one.
+func registerAlertsServer(ctx context.Context, mcpClients *client.Clients, alertClient *opsgenie.AlertClient, payload any, conf *config.Config) error {
+	a, ok := opsgenieAlert(payload)
+	if !ok {
+		return nil
+	}
+
+	alertsServer := alerts.NewServer("oka", version.Version, alertClient, a.Id, conf)
+	return mcpClients.RegisterServer(ctx, alertsServer.MCPServer, "alerts")
+}
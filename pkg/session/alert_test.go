@@ -0,0 +1,133 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+	"github.com/opsgenie/opsgenie-go-sdk-v2/client"
+
+	"github.com/giantswarm/oka/pkg/opsgenie"
+)
+
+func TestAlertURL(t *testing.T) {
+	testCases := []struct {
+		name     string
+		payload  any
+		apiURL   string
+		slackURL string
+		expected string
+	}{
+		{
+			name:     "us region",
+			payload:  &alert.GetAlertResult{Id: "alert-1"},
+			apiURL:   string(client.API_URL),
+			expected: "https://example.app.opsgenie.com/alert/detail/alert-1/details",
+		},
+		{
+			name:     "eu region",
+			payload:  &alert.GetAlertResult{Id: "alert-1"},
+			apiURL:   string(client.API_URL_EU),
+			expected: "https://example.app.eu.opsgenie.com/alert/detail/alert-1/details",
+		},
+		{
+			name:     "incident alert",
+			payload:  &opsgenie.IncidentAlert{GetAlertResult: &alert.GetAlertResult{Id: "alert-1"}, IncidentID: "incident-1"},
+			apiURL:   string(client.API_URL_EU),
+			expected: "https://example.app.eu.opsgenie.com/alert/detail/alert-1/details",
+		},
+		{
+			name:     "slack alert url",
+			payload:  &alert.GetAlertResult{Id: "alert-1"},
+			apiURL:   string(client.API_URL_EU),
+			slackURL: "https://alerts.example.com/",
+			expected: "https://example.app.eu.opsgenie.com/alert/detail/alert-1/details",
+		},
+		{
+			name:    "not an alert",
+			payload: map[string]any{"message": "test"},
+			apiURL:  string(client.API_URL),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := testConfig(t)
+			conf.OpsGenie.APIUrl = tc.apiURL
+			conf.OpsGenie.Domain = "example"
+			conf.Slack.AlertURL = tc.slackURL
+
+			if url := alertURL(tc.payload, conf); url != tc.expected {
+				t.Errorf("expected URL %q, got %q", tc.expected, url)
+			}
+		})
+	}
+}
+
+func TestSessionAlertURL(t *testing.T) {
+	const (
+		expectedURL      = "https://example.app.eu.opsgenie.com/alert/detail/alert-1/details"
+		expectedSlackURL = "https://alerts.example.com/alert-1"
+	)
+
+	// The slack.alert_url prefix only links the alert in the Slack summary.
+	conf := testConfig(t)
+	conf.OpsGenie.APIUrl = string(client.API_URL_EU)
+	conf.OpsGenie.Domain = "example"
+	conf.SessionsJSON = true
+	conf.Slack.AlertURL = "https://alerts.example.com/"
+
+	model := &fakeModel{}
+	s := newTestSession(t, &alert.GetAlertResult{Id: "alert-1"}, model, newTestClients(t, &echoServer{}), conf)
+
+	err := s.Run(context.Background())
+	if err != nil {
+		t.Fatalf("failed to run session: %v", err)
+	}
+
+	if !containsText(model.calls[0], "Link to the alert in OpsGenie: "+expectedURL) {
+		t.Error("expected the link to the alert to be sent to the LLM")
+	}
+
+	log, err := os.ReadFile(s.logFile.Name())
+	if err != nil {
+		t.Fatalf("failed to read session log: %v", err)
+	}
+	if !strings.Contains(string(log), "## Alert link\n"+expectedURL) {
+		t.Errorf("expected the link to the alert in the session log, got %q", log)
+	}
+
+	data, err := os.ReadFile(transcriptPath(s.logFile.Name()))
+	if err != nil {
+		t.Fatalf("failed to read session transcript: %v", err)
+	}
+	var transcript Transcript
+	err = json.Unmarshal(data, &transcript)
+	if err != nil {
+		t.Fatalf("failed to parse session transcript: %v", err)
+	}
+	if transcript.AlertURL != expectedURL {
+		t.Errorf("expected the transcript to link the alert %q, got %q", expectedURL, transcript.AlertURL)
+	}
+
+	var summary []byte
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		summary, _ = io.ReadAll(r.Body)
+	}))
+	t.Cleanup(webhook.Close)
+	conf.Slack.WebhookURL = webhook.URL
+
+	err = notifySlack(context.Background(), s, conf)
+	if err != nil {
+		t.Fatalf("failed to notify Slack: %v", err)
+	}
+	if !strings.Contains(string(summary), expectedSlackURL) {
+		t.Errorf("expected the Slack summary to link the alert with the Slack alert URL, got %s", summary)
+	}
+}
@@ -0,0 +1,46 @@
+package session
+
+import (
+	"regexp"
+	"strings"
+)
+
+// finalAnswerHeadingRegexp matches the heading the LLM is asked to give its
+// final report under, or the "Final Summary" heading used before.
+var finalAnswerHeadingRegexp = regexp.MustCompile(`(?im)^#{1,6}[ \t]*\**final[ \t]+(?:answer|summary)[ \t]*:?\**[ \t]*:?[ \t]*$`)
+
+// finalAnswer extracts the final answer from the final response of the LLM,
+// which may hold its reasoning before: the content under the last "Final
+// Answer" heading, or the whole response if there is no such heading. The
+// end phrase closing the response, if any, is removed.
+func finalAnswer(response, endPhrase string) string {
+	answer := response
+	headings := finalAnswerHeadingRegexp.FindAllStringIndex(response, -1)
+	if len(headings) > 0 {
+		answer = response[headings[len(headings)-1][1]:]
+	}
+
+	// The end phrase may be quoted or emphasized.
+	const decoration = " \t\n\"'`*_.!"
+	answer = strings.TrimSpace(answer)
+	trimmed := strings.TrimRight(answer, decoration)
+	if n := len(trimmed) - len(endPhrase); endPhrase != "" && n >= 0 && strings.EqualFold(trimmed[n:], endPhrase) {
+		answer = trimmed[:n]
+
+		// Remove the line of the end phrase if it only holds the phrase,
+		// or its opening quote otherwise.
+		lineStart := strings.LastIndex(answer, "\n") + 1
+		if strings.Trim(answer[lineStart:], decoration) == "" {
+			answer = answer[:lineStart]
+		} else {
+			answer = strings.TrimRight(strings.TrimRight(answer, " \t"), "\"'`")
+		}
+		answer = strings.TrimSpace(answer)
+	}
+
+	if answer == "" {
+		return strings.TrimSpace(response)
+	}
+
+	return answer
+}
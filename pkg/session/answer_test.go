@@ -0,0 +1,91 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestFinalAnswer(t *testing.T) {
+	testCases := []struct {
+		name     string
+		response string
+		expected string
+	}{
+		{
+			name:     "final answer heading",
+			response: "I checked the pods, they restarted.\n\n## Final Answer\nThe pods were OOM killed.\nStatus: RESOLVED\n\ninvestigation complete",
+			expected: "The pods were OOM killed.\nStatus: RESOLVED",
+		},
+		{
+			name:     "last of several headings",
+			response: "## Final Answer\nDraft.\n\nActually, one more check.\n\n### **Final Answer:**\nThe disk is full.\ninvestigation complete",
+			expected: "The disk is full.",
+		},
+		{
+			name:     "final summary heading",
+			response: "Reasoning.\n# final summary\nThe node is down.",
+			expected: "The node is down.",
+		},
+		{
+			name:     "no heading",
+			response: "The alert is resolved.\ninvestigation complete",
+			expected: "The alert is resolved.",
+		},
+		{
+			name:     "emphasized end phrase",
+			response: "The alert is resolved.\n\n**Investigation Complete.**",
+			expected: "The alert is resolved.",
+		},
+		{
+			name:     "end phrase in the last line",
+			response: "The alert is resolved, \"investigation complete\"",
+			expected: "The alert is resolved,",
+		},
+		{
+			name:     "heading not on its own line",
+			response: "See the ## Final Answer below.\nThe alert is resolved.",
+			expected: "See the ## Final Answer below.\nThe alert is resolved.",
+		},
+		{
+			name:     "only the end phrase",
+			response: "investigation complete",
+			expected: "investigation complete",
+		},
+		{
+			name:     "empty final answer section",
+			response: "The alert is resolved.\n## Final Answer\ninvestigation complete",
+			expected: "The alert is resolved.\n## Final Answer\ninvestigation complete",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			answer := finalAnswer(tc.response, testEndPhrase)
+			if answer != tc.expected {
+				t.Errorf("expected final answer %q, got %q", tc.expected, answer)
+			}
+		})
+	}
+}
+
+func TestSessionFinalAnswer(t *testing.T) {
+	model := &fakeModel{
+		responses: []*llms.ContentChoice{
+			toolCallChoice("call-1", echoTool, `{"text": "a"}`),
+			{Content: "The echo tool answered.\n\n## Final Answer\nThe alert is a test.\n\n" + testEndPhrase},
+		},
+	}
+	s := newTestSession(t, map[string]any{"message": "test"}, model, newTestClients(t, &echoServer{}), testConfig(t))
+
+	err := s.Run(context.Background())
+	if err != nil {
+		t.Fatalf("failed to run session: %v", err)
+	}
+
+	// The intermediate responses and reasoning are not part of the answer.
+	if s.finalResponse != "The alert is a test." {
+		t.Errorf("expected the final answer only, got %q", s.finalResponse)
+	}
+}
@@ -0,0 +1,76 @@
+package session
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// budget limits the number of sessions started within a rolling time window.
+// It is safe for concurrent use.
+type budget struct {
+	limit  int
+	window time.Duration
+
+	mu     sync.Mutex
+	starts []time.Time
+}
+
+// newBudget creates a new budget allowing limit sessions per window. A limit
+// lower than 1 disables the budget.
+func newBudget(limit int, window time.Duration) *budget {
+	return &budget{
+		limit:  limit,
+		window: window,
+	}
+}
+
+// wait blocks until a session can be started within the budget and records
+// its start. It returns false if the context is canceled while waiting.
+func (b *budget) wait(ctx context.Context) bool {
+	if b.limit < 1 {
+		return true
+	}
+
+	logged := false
+	for {
+		delay := b.reserve(time.Now())
+		if delay == 0 {
+			return true
+		}
+
+		if !logged {
+			slog.Warn("Session budget exhausted, deferring alert", "limit", b.limit, "window", b.window, "delay", delay)
+			logged = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(delay):
+		}
+	}
+}
+
+// reserve records a session start at the given time if the budget allows it
+// and returns 0. Otherwise it returns the delay until the budget frees up.
+func (b *budget) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Forget the sessions started outside of the window.
+	i := 0
+	for i < len(b.starts) && now.Sub(b.starts[i]) >= b.window {
+		i++
+	}
+	b.starts = b.starts[i:]
+
+	if len(b.starts) >= b.limit {
+		return b.starts[0].Add(b.window).Sub(now)
+	}
+
+	b.starts = append(b.starts, now)
+
+	return 0
+}
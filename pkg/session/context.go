@@ -0,0 +1,90 @@
+package session
+
+import (
+	"github.com/tmc/langchaingo/llms"
+)
+
+const (
+	// charsPerToken is the approximate number of characters per token, used
+	// to estimate the size of the session context.
+	charsPerToken = 4
+
+	// imageTokens is the approximate number of tokens of an image.
+	imageTokens = 1000
+
+	// trimmedToolResponse replaces the content of the tool responses trimmed
+	// from the session context.
+	trimmedToolResponse = "[Tool response removed to fit the context window]"
+)
+
+// trimFunc trims the messages of a session so that their estimated size fits
+// in maxTokens. It must preserve the alert and the system prompt.
+type trimFunc func(messages []llms.MessageContent, maxTokens int) []llms.MessageContent
+
+// estimateTokens estimates the number of tokens of the messages.
+func estimateTokens(messages []llms.MessageContent) int {
+	chars := 0
+	images := 0
+	for _, message := range messages {
+		for _, part := range message.Parts {
+			switch p := part.(type) {
+			case llms.TextContent:
+				chars += len(p.Text)
+			case llms.ToolCall:
+				if p.FunctionCall != nil {
+					chars += len(p.FunctionCall.Name) + len(p.FunctionCall.Arguments)
+				}
+			case llms.ToolCallResponse:
+				chars += len(p.Name) + len(p.Content)
+			case llms.BinaryContent, llms.ImageURLContent:
+				images++
+			}
+		}
+	}
+
+	return chars/charsPerToken + images*imageTokens
+}
+
+// dropOldToolResponses trims the messages by replacing the content of the
+// oldest tool responses until the messages fit in maxTokens. Tool responses
+// are replaced rather than removed as providers expect a response for every
+// tool call. The alert and the system prompt are never trimmed.
+func dropOldToolResponses(messages []llms.MessageContent, maxTokens int) []llms.MessageContent {
+	for i := range messages {
+		if estimateTokens(messages) <= maxTokens {
+			break
+		}
+
+		if messages[i].Role != llms.ChatMessageTypeTool {
+			continue
+		}
+
+		for j, part := range messages[i].Parts {
+			response, ok := part.(llms.ToolCallResponse)
+			if !ok || response.Content == trimmedToolResponse {
+				continue
+			}
+
+			response.Content = trimmedToolResponse
+			messages[i].Parts[j] = response
+		}
+	}
+
+	return messages
+}
+
+// trimContext trims the session context if its estimated size exceeds the
+// context window.
+func (s *Session) trimContext() {
+	if s.contextWindowTokens <= 0 {
+		return
+	}
+
+	tokens := estimateTokens(s.messages)
+	if tokens <= s.contextWindowTokens {
+		return
+	}
+
+	s.messages = s.trim(s.messages, s.contextWindowTokens)
+	s.logger.Info("Trimmed session context", "tokens", tokens, "trimmed_tokens", estimateTokens(s.messages), "context_window_tokens", s.contextWindowTokens)
+}
@@ -0,0 +1,132 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// toolResponseMessage returns a tool response message with the content.
+func toolResponseMessage(id, content string) llms.MessageContent {
+	return llms.MessageContent{
+		Role:  llms.ChatMessageTypeTool,
+		Parts: []llms.ContentPart{llms.ToolCallResponse{ToolCallID: id, Name: echoTool, Content: content}},
+	}
+}
+
+func TestDropOldToolResponses(t *testing.T) {
+	large := strings.Repeat("x", 4000)
+	systemPrompt := strings.Repeat("s", 400)
+	alertMessage := strings.Repeat("a", 400)
+
+	testCases := []struct {
+		name            string
+		maxTokens       int
+		expectedTrimmed []bool
+	}{
+		{
+			name:            "fits",
+			maxTokens:       10000,
+			expectedTrimmed: []bool{false, false, false},
+		},
+		{
+			name:            "oldest trimmed",
+			maxTokens:       2500,
+			expectedTrimmed: []bool{true, false, false},
+		},
+		{
+			name:            "all trimmed",
+			maxTokens:       10,
+			expectedTrimmed: []bool{true, true, true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			messages := []llms.MessageContent{
+				llms.TextParts(llms.ChatMessageTypeSystem, systemPrompt),
+				llms.TextParts(llms.ChatMessageTypeHuman, alertMessage),
+				toolResponseMessage("call-1", large),
+				toolResponseMessage("call-2", large),
+				toolResponseMessage("call-3", large),
+			}
+
+			messages = dropOldToolResponses(messages, tc.maxTokens)
+			if len(messages) != 5 {
+				t.Fatalf("expected the messages to be kept, got %d messages", len(messages))
+			}
+
+			// The system prompt and the alert are never trimmed.
+			if text := messages[0].Parts[0].(llms.TextContent).Text; text != systemPrompt {
+				t.Errorf("expected the system prompt to be preserved, got %q", text)
+			}
+			if text := messages[1].Parts[0].(llms.TextContent).Text; text != alertMessage {
+				t.Errorf("expected the alert to be preserved, got %q", text)
+			}
+
+			for i, expected := range tc.expectedTrimmed {
+				response := messages[i+2].Parts[0].(llms.ToolCallResponse)
+				if trimmed := response.Content == trimmedToolResponse; trimmed != expected {
+					t.Errorf("expected tool response %d trimmed: %t, got %t", i+1, expected, trimmed)
+				}
+				if id := fmt.Sprintf("call-%d", i+1); response.ToolCallID != id {
+					t.Errorf("expected the tool call ID of response %d to be preserved", i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestSessionTrimsContext(t *testing.T) {
+	conf := testConfig(t)
+	conf.Session.ContextWindowTokens = 1500
+
+	large := strings.Repeat("x", 4000)
+	model := &fakeModel{
+		responses: []*llms.ContentChoice{
+			toolCallChoice("call-1", echoTool, `{"text": "`+large+`"}`),
+			toolCallChoice("call-2", echoTool, `{"text": "`+large+`"}`),
+		},
+	}
+	s := newTestSession(t, map[string]any{"message": "test"}, model, newTestClients(t, &echoServer{}), conf)
+
+	var trimmed int
+	trim := s.trim
+	s.trim = func(messages []llms.MessageContent, maxTokens int) []llms.MessageContent {
+		trimmed++
+		return trim(messages, maxTokens)
+	}
+
+	err := s.Run(context.Background())
+	if err != nil {
+		t.Fatalf("failed to run session: %v", err)
+	}
+
+	if trimmed == 0 {
+		t.Fatal("expected the trimming function to be called")
+	}
+
+	// The last LLM call is prompted with the first tool response trimmed.
+	last := model.calls[len(model.calls)-1]
+	if systemMessage(last) == "" {
+		t.Error("expected the system prompt to be preserved")
+	}
+
+	var contents []string
+	for _, message := range last {
+		for _, part := range message.Parts {
+			if response, ok := part.(llms.ToolCallResponse); ok {
+				contents = append(contents, response.Content)
+			}
+		}
+	}
+	if len(contents) != 2 {
+		t.Fatalf("expected 2 tool responses, got %d", len(contents))
+	}
+	if contents[0] != trimmedToolResponse {
+		t.Errorf("expected the oldest tool response to be trimmed, got %d characters", len(contents[0]))
+	}
+}
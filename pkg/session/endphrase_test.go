@@ -0,0 +1,49 @@
+package session
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestCustomEndPhrase(t *testing.T) {
+	const endPhrase = "Fin de l'enquête"
+
+	conf := testConfig(t)
+	conf.Session.EndPhrase = endPhrase
+
+	// The LLM keeps calling tools along with its answers, the default phrase
+	// does not end the investigation while the custom one does.
+	defaultChoice := toolCallChoice("call-1", echoTool, `{"text": "a"}`)
+	defaultChoice.Content = "Still investigating. investigation complete"
+	customChoice := toolCallChoice("call-2", echoTool, `{"text": "b"}`)
+	customChoice.Content = "The alert is resolved. FIN DE L'ENQUÊTE"
+
+	echo := &echoServer{}
+	model := &fakeModel{
+		responses: []*llms.ContentChoice{defaultChoice, customChoice},
+	}
+	s := newTestSession(t, map[string]any{"message": "test"}, model, newTestClients(t, echo), conf)
+
+	err := s.Run(context.Background())
+	if err != nil {
+		t.Fatalf("failed to run session: %v", err)
+	}
+
+	if !slices.Equal(echo.calls, []string{"a"}) {
+		t.Errorf("expected only the tool call along the default phrase to run, got %q", echo.calls)
+	}
+	if len(model.calls) != 2 {
+		t.Errorf("expected the custom phrase to end the session after 2 LLM calls, got %d", len(model.calls))
+	}
+	if s.finalResponse != "The alert is resolved." {
+		t.Errorf("expected the final response without the end phrase, got %q", s.finalResponse)
+	}
+
+	if prompt := systemMessage(model.calls[0]); !strings.Contains(prompt, endPhrase) {
+		t.Errorf("expected the system prompt to ask for the custom phrase, got %q", prompt)
+	}
+}
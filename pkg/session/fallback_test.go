@@ -0,0 +1,104 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/llm"
+)
+
+func TestLLMFallback(t *testing.T) {
+	testCases := []struct {
+		name             string
+		err              error
+		expectedAttempts int
+		expectedFallback bool
+	}{
+		{
+			name:             "primary unavailable",
+			err:              errors.New("503 Service Unavailable"),
+			expectedAttempts: 2,
+			expectedFallback: true,
+		},
+		{
+			name:             "permanent failure",
+			err:              errors.New("400 invalid request"),
+			expectedAttempts: 1,
+			expectedFallback: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := testConfig(t)
+			conf.LLM.MaxRetries = 1
+			conf.LLM.RetryBackoff = time.Millisecond
+
+			primary := &flakyModel{fakeModel: &fakeModel{}, failures: 100, err: tc.err}
+			fallback := &fakeModel{
+				responses: []*llms.ContentChoice{toolCallChoice("call-1", echoTool, `{"text": "a"}`)},
+			}
+			models := []llm.Model{
+				{Model: primary, Config: config.LLM{Model: "primary"}},
+				{Model: fallback, Config: config.LLM{Model: "fallback"}},
+			}
+
+			s, err := New(map[string]any{"message": "test"}, models, newTestClients(t, &echoServer{}), nil, conf)
+			if err != nil {
+				t.Fatalf("failed to create session: %v", err)
+			}
+
+			err = s.Run(context.Background())
+			if tc.expectedFallback != (err == nil) {
+				t.Fatalf("expected the session to succeed: %t, got %v", tc.expectedFallback, err)
+			}
+
+			// The primary model is only given up once its retries are
+			// exhausted.
+			if primary.attempts != tc.expectedAttempts {
+				t.Errorf("expected %d attempts of the primary model, got %d", tc.expectedAttempts, primary.attempts)
+			}
+
+			data, err := os.ReadFile(s.logFile.Name())
+			if err != nil {
+				t.Fatalf("failed to read session log: %v", err)
+			}
+			if strings.Contains(string(data), "## LLM fallback\nmodel: fallback") != tc.expectedFallback {
+				t.Errorf("expected the fallback in the session log: %t", tc.expectedFallback)
+			}
+
+			if !tc.expectedFallback {
+				if len(fallback.calls) != 0 {
+					t.Errorf("expected the fallback model not to be called, got %d calls", len(fallback.calls))
+				}
+				return
+			}
+
+			// The fallback model carries on with the session history, and
+			// keeps being used for the following calls.
+			if len(fallback.calls) != 2 {
+				t.Fatalf("expected the fallback model to be called twice, got %d calls", len(fallback.calls))
+			}
+			if systemMessage(fallback.calls[0]) == "" || !reflect.DeepEqual(fallback.calls[0], s.messages[:len(fallback.calls[0])]) {
+				t.Errorf("expected the fallback model to be given the session history, got %v", fallback.calls[0])
+			}
+			if len(fallback.calls[1]) <= len(fallback.calls[0]) {
+				t.Errorf("expected the history to grow with the tool call, got %d messages", len(fallback.calls[1]))
+			}
+			if responses := toolResponses(s); len(responses) != 1 || responses[0] != "echo: a" {
+				t.Errorf("expected the tool response of the fallback call, got %q", responses)
+			}
+			if s.modelIndex != 1 {
+				t.Errorf("expected the fallback model to be the current one, got model %d", s.modelIndex)
+			}
+		})
+	}
+}
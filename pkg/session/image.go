@@ -0,0 +1,126 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+const (
+	// maxImageSize is the maximum size in bytes of an image attached to the
+	// session context.
+	maxImageSize = 5 << 20
+
+	// imageFetchTimeout is the timeout for downloading a single image.
+	imageFetchTimeout = 30 * time.Second
+)
+
+// alertImageURLs returns the image URLs held by the given alert detail field.
+// The field may hold several URLs separated by commas or whitespace.
+func alertImageURLs(payload any, field string) []string {
+	a, ok := opsgenieAlert(payload)
+	if !ok || field == "" {
+		return nil
+	}
+
+	value := a.Details[field]
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\t'
+	})
+}
+
+// addImages downloads the images referenced by the alert and adds them to the
+// session context, so that multimodal models can look at them. Images which
+// cannot be downloaded are skipped.
+func (s *Session) addImages(ctx context.Context) {
+	urls := alertImageURLs(s.alert, s.imageField)
+	if len(urls) == 0 {
+		return
+	}
+
+	parts := []llms.ContentPart{
+		llms.TextPart("Images referenced by the alert:"),
+	}
+
+	s.log("\n## Images\n")
+	for _, url := range urls {
+		mimeType, data, err := fetchImage(ctx, url)
+		if err != nil {
+			s.logger.Warn("Failed to fetch alert image", "error", err, "url", url)
+			s.log("- %s (skipped: %s)\n", url, err)
+			continue
+		}
+
+		parts = append(parts, llms.BinaryPart(mimeType, data))
+		s.log("- %s\n", url)
+	}
+
+	if len(parts) > 1 {
+		s.addToContext(llms.ChatMessageTypeHuman, parts...)
+	}
+}
+
+// addToolImages adds the images returned by a tool to the session context if
+// the session is multimodal, tool responses only hold text. Otherwise the
+// images are only described in the tool response.
+func (s *Session) addToolImages(tool string, images []llms.BinaryContent) {
+	if !s.multimodal || len(images) == 0 {
+		return
+	}
+
+	parts := []llms.ContentPart{
+		llms.TextPart(fmt.Sprintf("Images returned by the %s tool:", tool)),
+	}
+	for _, image := range images {
+		parts = append(parts, image)
+	}
+
+	s.log("\n## Tool images\ntool: %s\n%d images added to the context\n", tool, len(images))
+	s.addToContext(llms.ChatMessageTypeHuman, parts...)
+}
+
+// fetchImage downloads the image at the given URL and returns its MIME type
+// and content.
+func fetchImage(ctx context.Context, url string) (string, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, imageFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	if len(data) > maxImageSize {
+		return "", nil, fmt.Errorf("image exceeds %d bytes", maxImageSize)
+	}
+
+	mimeType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(mimeType, "image/") {
+		mimeType = http.DetectContentType(data)
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		return "", nil, fmt.Errorf("unsupported content type: %s", mimeType)
+	}
+
+	return mimeType, data, nil
+}
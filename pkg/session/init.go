@@ -0,0 +1,181 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig"
+
+	"github.com/giantswarm/oka/pkg/command"
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/logger"
+)
+
+// initCommandData holds the data available to session init command templates.
+type initCommandData struct {
+	Alert any // Alert investigated by the session
+}
+
+// initCommandsCache keeps track of the init and session init commands which
+// ran successfully, so that they are not run again for every alert until their
+// TTL expires.
+type initCommandsCache struct {
+	timeout time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*initCommandEntry
+}
+
+// initCommandEntry is the cache entry of a single rendered init command. Its
+// mutex serializes concurrent runs of the same command.
+type initCommandEntry struct {
+	mu      sync.Mutex
+	lastRun time.Time
+}
+
+// newInitCommandsCache creates a new init commands cache running the commands
+// within the configured timeout. The init commands are recorded as run now, as
+// they run at startup.
+func newInitCommandsCache(conf *config.Config) *initCommandsCache {
+	c := &initCommandsCache{
+		timeout: conf.InitCommandTimeout,
+		entries: make(map[string]*initCommandEntry),
+	}
+
+	now := time.Now()
+	for _, cmd := range conf.InitCommands {
+		c.entry(commandKey(cmd)).lastRun = now
+	}
+
+	return c
+}
+
+// commandKey returns the cache key of a command.
+func commandKey(cmd config.Command) string {
+	return strings.Join(append([]string{cmd.Command}, cmd.Args...), " ")
+}
+
+// entry returns the cache entry for the given command key.
+func (c *initCommandsCache) entry(key string) *initCommandEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		e = &initCommandEntry{}
+		c.entries[key] = e
+	}
+
+	return e
+}
+
+// runInitCommands runs the init commands again if they did not run
+// successfully within init_commands_ttl, when set, e.g. to refresh expired
+// credentials. It then templates the session init commands with the alert
+// data and runs the ones which did not run successfully within
+// session_init_commands_ttl.
+func runInitCommands(ctx context.Context, conf *config.Config, alert any, cache *initCommandsCache) error {
+	if conf.InitCommandsTTL > 0 {
+		for _, command := range conf.InitCommands {
+			err := cache.run(ctx, command, conf.InitCommandsTTL)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	data := initCommandData{
+		Alert: alert,
+	}
+
+	for _, command := range conf.SessionInitCommands {
+		rendered, err := templateCommand(command, data)
+		if err != nil {
+			return err
+		}
+
+		err = cache.run(ctx, rendered, conf.SessionInitCommandsTTL)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// run runs the command unless it already ran successfully within the TTL.
+// Concurrent runs of the same command are serialized, so that the sessions
+// waiting for it do not run it again.
+func (c *initCommandsCache) run(ctx context.Context, cmd config.Command, ttl time.Duration) error {
+	key := commandKey(cmd)
+	e := c.entry(key)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.lastRun.IsZero() && time.Since(e.lastRun) < ttl {
+		logger.FromContext(ctx).Debug("Skipping cached init command", "command", key)
+		return nil
+	}
+
+	logger.FromContext(ctx).Info("Running init command before session", "command", key)
+	err := command.Run(ctx, cmd, c.timeout)
+	if err != nil {
+		return fmt.Errorf("failed to run init command: %w", err)
+	}
+
+	e.lastRun = time.Now()
+
+	return nil
+}
+
+// templateCommand templates the command, its arguments and environment
+// variables with the provided data.
+func templateCommand(command config.Command, data initCommandData) (config.Command, error) {
+	var err error
+	rendered := config.Command{
+		Args: make([]string, len(command.Args)),
+		Env:  make([]string, len(command.Env)),
+	}
+
+	rendered.Command, err = templateString(command.Command, data)
+	if err != nil {
+		return config.Command{}, err
+	}
+
+	for i, arg := range command.Args {
+		rendered.Args[i], err = templateString(arg, data)
+		if err != nil {
+			return config.Command{}, err
+		}
+	}
+
+	for i, env := range command.Env {
+		rendered.Env[i], err = templateString(env, data)
+		if err != nil {
+			return config.Command{}, err
+		}
+	}
+
+	return rendered, nil
+}
+
+// templateString renders a single init command template string.
+func templateString(text string, data initCommandData) (string, error) {
+	tmpl, err := template.New("init-command").Funcs(sprig.FuncMap()).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse session init command template %q: %w", text, err)
+	}
+
+	var b strings.Builder
+	err = tmpl.Execute(&b, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute session init command template %q: %w", text, err)
+	}
+
+	return b.String(), nil
+}
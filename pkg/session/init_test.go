@@ -0,0 +1,194 @@
+package session
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+// countingCommand returns a command appending the first argument to a file,
+// along with a function returning the arguments of its runs.
+func countingCommand(t *testing.T) (config.Command, func() []string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "runs")
+	command := config.Command{
+		Command: "sh",
+		Args:    []string{"-c", `echo "$1" >> "$0"`, path},
+	}
+
+	runs := func() []string {
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			t.Fatalf("failed to read command runs: %v", err)
+		}
+		return strings.Fields(string(content))
+	}
+
+	return command, runs
+}
+
+// withArg returns the command with the argument appended.
+func withArg(command config.Command, arg string) config.Command {
+	command.Args = append(append([]string{}, command.Args...), arg)
+	return command
+}
+
+func TestInitCommandsTTL(t *testing.T) {
+	testCases := []struct {
+		name         string
+		ttl          time.Duration
+		wait         time.Duration
+		expectedRuns int
+	}{
+		{
+			name:         "no TTL",
+			ttl:          0,
+			wait:         100 * time.Millisecond,
+			expectedRuns: 0,
+		},
+		{
+			name:         "within TTL",
+			ttl:          time.Hour,
+			expectedRuns: 0,
+		},
+		{
+			name:         "expired TTL",
+			ttl:          50 * time.Millisecond,
+			wait:         100 * time.Millisecond,
+			expectedRuns: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			command, runs := countingCommand(t)
+
+			conf := testConfig(t)
+			conf.InitCommands = []config.Command{withArg(command, "init")}
+			conf.InitCommandsTTL = tc.ttl
+
+			// The init commands are recorded as run at startup.
+			cache := newInitCommandsCache(conf)
+			time.Sleep(tc.wait)
+
+			for range 2 {
+				err := runInitCommands(context.Background(), conf, nil, cache)
+				if err != nil {
+					t.Fatalf("failed to run init commands: %v", err)
+				}
+			}
+
+			if got := len(runs()); got != tc.expectedRuns {
+				t.Errorf("expected the init command to run %d times, got %d", tc.expectedRuns, got)
+			}
+		})
+	}
+}
+
+func TestSessionInitCommandsTTL(t *testing.T) {
+	testCases := []struct {
+		name         string
+		ttl          time.Duration
+		expectedRuns []string
+	}{
+		{
+			name:         "no TTL",
+			ttl:          0,
+			expectedRuns: []string{"alert-1", "alert-1", "alert-2"},
+		},
+		{
+			name:         "within TTL",
+			ttl:          time.Hour,
+			expectedRuns: []string{"alert-1", "alert-2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			command, runs := countingCommand(t)
+
+			conf := testConfig(t)
+			conf.SessionInitCommands = []config.Command{withArg(command, "{{ .Alert.Id }}")}
+			conf.SessionInitCommandsTTL = tc.ttl
+			cache := newInitCommandsCache(conf)
+
+			// Session init commands are cached once rendered, so that they run
+			// again for another alert.
+			for _, id := range []string{"alert-1", "alert-1", "alert-2"} {
+				err := runInitCommands(context.Background(), conf, &alert.GetAlertResult{Id: id}, cache)
+				if err != nil {
+					t.Fatalf("failed to run init commands: %v", err)
+				}
+			}
+
+			if got := runs(); strings.Join(got, ",") != strings.Join(tc.expectedRuns, ",") {
+				t.Errorf("expected the session init command to run for %q, got %q", tc.expectedRuns, got)
+			}
+		})
+	}
+}
+
+func TestInitCommandsTTLConcurrentSessions(t *testing.T) {
+	command, runs := countingCommand(t)
+
+	conf := testConfig(t)
+	conf.InitCommands = []config.Command{withArg(command, "init")}
+	conf.InitCommandsTTL = 50 * time.Millisecond
+	cache := newInitCommandsCache(conf)
+	time.Sleep(100 * time.Millisecond)
+
+	// Sessions starting together once the TTL expired run the init command
+	// once, the others wait for it.
+	const sessions = 8
+	var wg sync.WaitGroup
+	for i := range sessions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := runInitCommands(context.Background(), conf, nil, cache)
+			if err != nil {
+				t.Errorf("failed to run init commands of session %d: %v", i, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := len(runs()); got != 1 {
+		t.Errorf("expected the init command to run once, got %d", got)
+	}
+}
+
+func TestInitCommandsTTLFailure(t *testing.T) {
+	command, runs := countingCommand(t)
+	failing := withArg(command, "failed")
+	failing.Args[1] += "; exit 1"
+
+	conf := testConfig(t)
+	conf.SessionInitCommands = []config.Command{failing}
+	conf.SessionInitCommandsTTL = time.Hour
+	cache := newInitCommandsCache(conf)
+
+	// A failing command is not cached, it runs again for the next session.
+	for range 2 {
+		err := runInitCommands(context.Background(), conf, nil, cache)
+		if err == nil || !strings.Contains(err.Error(), "failed to run init command") {
+			t.Errorf("expected the init command to fail, got %v", err)
+		}
+	}
+
+	if got := len(runs()); got != 2 {
+		t.Errorf("expected the failing init command to run twice, got %d", got)
+	}
+}
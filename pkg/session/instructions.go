@@ -0,0 +1,44 @@
+package session
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+// alertInstructions returns the configured instructions matching the alert
+// carried by the session payload, in order. An instruction matches the alerts
+// having any of its tags or whose message contains its message.
+func alertInstructions(instructions []config.AlertInstruction, payload any) []string {
+	a, ok := opsgenieAlert(payload)
+	if !ok {
+		return nil
+	}
+
+	var matched []string
+	for _, instruction := range instructions {
+		matchesTags := slices.ContainsFunc(instruction.Tags, func(tag string) bool {
+			return slices.Contains(a.Tags, tag)
+		})
+		matchesMessage := instruction.Message != "" && strings.Contains(strings.ToLower(a.Message), strings.ToLower(instruction.Message))
+
+		if matchesTags || matchesMessage {
+			matched = append(matched, instruction.Instruction)
+		}
+	}
+
+	return matched
+}
+
+// formatAlertInstructions formats the instructions matching the alert for the
+// session context.
+func formatAlertInstructions(instructions []string) string {
+	var b strings.Builder
+	b.WriteString("Additional instructions for investigating this alert:\n")
+	for _, instruction := range instructions {
+		b.WriteString("- " + instruction + "\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,89 @@
+package session
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+func TestAlertInstructions(t *testing.T) {
+	instructions := []config.AlertInstruction{
+		{Instruction: "Check the PVC usage first", Tags: []string{"disk-pressure", "storage"}},
+		{Instruction: "Check the certificates", Message: "Certificate Expiring"},
+		{Instruction: "Check the node conditions", Tags: []string{"node"}, Message: "NotReady"},
+	}
+
+	testCases := []struct {
+		name     string
+		payload  any
+		expected []string
+	}{
+		{
+			name:     "matching tag",
+			payload:  &alert.GetAlertResult{Message: "Volume almost full", Tags: []string{"team:phoenix", "storage"}},
+			expected: []string{"Check the PVC usage first"},
+		},
+		{
+			name:     "matching message, case-insensitive",
+			payload:  &alert.GetAlertResult{Message: "certificate expiring in 3 days"},
+			expected: []string{"Check the certificates"},
+		},
+		{
+			name:     "matching tag or message",
+			payload:  &alert.GetAlertResult{Message: "Node worker-1 NotReady", Tags: []string{"disk-pressure"}},
+			expected: []string{"Check the PVC usage first", "Check the node conditions"},
+		},
+		{
+			name:     "tag prefix does not match",
+			payload:  &alert.GetAlertResult{Message: "Volume almost full", Tags: []string{"storage-class"}},
+			expected: nil,
+		},
+		{
+			name:     "no match",
+			payload:  &alert.GetAlertResult{Message: "High latency", Tags: []string{"api"}},
+			expected: nil,
+		},
+		{
+			name:     "not an alert",
+			payload:  map[string]any{"message": "Certificate expiring", "tags": []string{"storage"}},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			matched := alertInstructions(instructions, tc.payload)
+			if !slices.Equal(matched, tc.expected) {
+				t.Errorf("expected instructions %q, got %q", tc.expected, matched)
+			}
+		})
+	}
+}
+
+func TestSessionAlertInstructions(t *testing.T) {
+	conf := testConfig(t)
+	conf.Session.AlertInstructions = []config.AlertInstruction{
+		{Instruction: "Check the PVC usage first", Tags: []string{"disk-pressure"}},
+		{Instruction: "Check the certificates", Message: "certificate"},
+	}
+
+	model := &fakeModel{}
+	a := &alert.GetAlertResult{Id: "alert-1", Message: "Disk pressure on node", Tags: []string{"disk-pressure"}}
+	s := newTestSession(t, a, model, newTestClients(t, &echoServer{}), conf)
+
+	err := s.Run(context.Background())
+	if err != nil {
+		t.Fatalf("failed to run session: %v", err)
+	}
+
+	if !containsText(model.calls[0], "Additional instructions for investigating this alert:\n- Check the PVC usage first\n") {
+		t.Error("expected the matching instruction to be sent to the LLM")
+	}
+	if containsText(model.calls[0], "Check the certificates") {
+		t.Error("expected the instruction which does not match not to be sent to the LLM")
+	}
+}
@@ -0,0 +1,82 @@
+package session
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// ToolCall is a tool call proposed by the LLM, submitted for approval before
+// being executed.
+type ToolCall struct {
+	SessionID string
+	Tool      string
+	Arguments string
+}
+
+// Approver decides whether the tool calls proposed by the LLM are executed.
+type Approver interface {
+	// Approve returns whether the tool call is executed. An error ends the
+	// session.
+	Approve(ctx context.Context, call ToolCall) (bool, error)
+}
+
+// Interaction lets an operator step through a session instead of leaving the
+// LLM investigate autonomously: the operator sees the LLM responses, approves
+// each tool call and can add instructions before each LLM call.
+type Interaction interface {
+	Approver
+
+	// Instructions returns the instructions added to the context before the
+	// next LLM call, none if empty. An error ends the session.
+	Instructions(ctx context.Context) (string, error)
+	// Response shows a response of the LLM.
+	Response(content string)
+	// ToolResponse shows the response of a tool call.
+	ToolResponse(tool, content string)
+}
+
+// deniedToolResponse is the response given to the LLM for the tool calls
+// which were not approved.
+const deniedToolResponse = "The tool call was denied by the operator, do not retry it. Continue the investigation with other tools or conclude it."
+
+// approve returns whether the tool call is executed, asking the session
+// approver if any.
+func (s Session) approve(ctx context.Context, tool, arguments string) (bool, error) {
+	if s.approver == nil {
+		return true, nil
+	}
+
+	approved, err := s.approver.Approve(ctx, ToolCall{SessionID: s.ID, Tool: tool, Arguments: arguments})
+	if err != nil {
+		return false, fmt.Errorf("failed to approve tool call: %w", err)
+	}
+	if !approved {
+		s.logger.Info("Tool call denied", "tool", tool)
+		s.log("\n## Tool call denied\ntool: %s\n", tool)
+	}
+
+	return approved, nil
+}
+
+// addInstructions adds the instructions of the session interaction, if any,
+// to the context before the next LLM call.
+func (s *Session) addInstructions(ctx context.Context) error {
+	if s.interaction == nil {
+		return nil
+	}
+
+	instructions, err := s.interaction.Instructions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read instructions: %w", err)
+	}
+	if instructions == "" {
+		return nil
+	}
+
+	s.addToContext(llms.ChatMessageTypeHuman, llms.TextPart(instructions))
+	s.log("\n## Operator instructions\n%s\n", instructions)
+
+	return nil
+}
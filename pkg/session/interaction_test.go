@@ -0,0 +1,101 @@
+package session
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// scriptedInteraction is a session interaction answering with scripted
+// approvals and instructions, in order.
+type scriptedInteraction struct {
+	approvals    []bool
+	instructions []string
+	calls        []ToolCall
+}
+
+// Approve returns the next scripted approval.
+func (i *scriptedInteraction) Approve(ctx context.Context, call ToolCall) (bool, error) {
+	i.calls = append(i.calls, call)
+
+	approved := i.approvals[0]
+	i.approvals = i.approvals[1:]
+
+	return approved, nil
+}
+
+// Instructions returns the next scripted instructions, none once they are
+// exhausted.
+func (i *scriptedInteraction) Instructions(ctx context.Context) (string, error) {
+	if len(i.instructions) == 0 {
+		return "", nil
+	}
+
+	instructions := i.instructions[0]
+	i.instructions = i.instructions[1:]
+
+	return instructions, nil
+}
+
+// Response ignores the LLM response.
+func (i *scriptedInteraction) Response(content string) {}
+
+// ToolResponse ignores the tool response.
+func (i *scriptedInteraction) ToolResponse(tool, content string) {}
+
+func TestInteractiveSession(t *testing.T) {
+	echo := &echoServer{}
+	model := &fakeModel{
+		responses: []*llms.ContentChoice{
+			toolCallChoice("call-1", echoTool, `{"text": "denied"}`),
+			toolCallChoice("call-2", echoTool, `{"text": "approved"}`),
+		},
+	}
+	s := newTestSession(t, map[string]any{"message": "test"}, model, newTestClients(t, echo), testConfig(t))
+
+	interaction := &scriptedInteraction{
+		approvals:    []bool{false, true},
+		instructions: []string{"Check the pods first"},
+	}
+	s.approver = interaction
+	s.interaction = interaction
+
+	err := s.Run(context.Background())
+	if err != nil {
+		t.Fatalf("failed to run session: %v", err)
+	}
+
+	// Only the approved tool call is executed.
+	if !slices.Equal(echo.calls, []string{"approved"}) {
+		t.Errorf("expected only the approved call to be executed, got %q", echo.calls)
+	}
+	if len(interaction.calls) != 2 || interaction.calls[0].SessionID != s.ID || interaction.calls[0].Tool != echoTool {
+		t.Errorf("expected both tool calls of the session to be submitted, got %+v", interaction.calls)
+	}
+
+	responses := toolResponses(s)
+	if len(responses) != 2 || responses[0] != deniedToolResponse || !strings.Contains(responses[1], "approved") {
+		t.Errorf("expected the denied response then the tool result, got %q", responses)
+	}
+
+	// The instructions are sent with the first LLM call.
+	if !containsText(model.calls[0], "Check the pods first") {
+		t.Error("expected the operator instructions to be sent to the LLM")
+	}
+}
+
+// containsText returns whether a message part contains the text.
+func containsText(messages []llms.MessageContent, text string) bool {
+	for _, message := range messages {
+		for _, part := range message.Parts {
+			if p, ok := part.(llms.TextContent); ok && strings.Contains(p.Text, text) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
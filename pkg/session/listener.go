@@ -2,86 +2,202 @@ package session
 
 import (
 	"context"
-	_ "embed"
-	"fmt"
 	"log/slog"
-	"strings"
 	"sync"
-	"text/template"
-
-	"github.com/Masterminds/sprig"
-	"github.com/tmc/langchaingo/llms"
+	"time"
 
 	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/events"
+	"github.com/giantswarm/oka/pkg/llm"
+	"github.com/giantswarm/oka/pkg/logger"
 	"github.com/giantswarm/oka/pkg/mcp/client"
+	"github.com/giantswarm/oka/pkg/opsgenie"
 )
 
-//go:embed system-prompt.tmpl
-var systemPromptTmpl string
-var systemPromptTemplate *template.Template
-var systemPrompt string
+// Listen listens for incoming alerts and starts a new session for each one.
+// The alert client is used to act on the investigated alerts in OpsGenie and
+// the sessions' events are published to the broker, which may be nil. The
+// tool calls of the sessions are executed once approved by the approver, all
+// of them if nil. The reloadable settings of the configurations received on
+// the reloads channel apply to the sessions started afterwards.
+func Listen(ctx context.Context, c <-chan any, reloads <-chan *config.Config, llmModels []llm.Model, mcpClients *client.Clients, alertClient *opsgenie.AlertClient, broker *events.Broker, approver Approver, conf *config.Config) error {
+	slog.Info("Session service started")
 
-func init() {
-	systemPromptTemplate = template.Must(template.New("system-prompt").Funcs(sprig.FuncMap()).Parse(systemPromptTmpl))
-}
+	// Sessions are given their own copy of the configuration so that reloads
+	// do not affect the running ones.
+	listenConf := *conf
 
-// Listen listens for incoming alerts and starts a new session for each one.
-func Listen(ctx context.Context, c <-chan any, llmModel llms.Model, mcpClients *client.Clients, conf *config.Config) error {
-	// Add system prompt instructions
-	systemPromptData := struct {
-		SlackHandle string
-	}{
-		SlackHandle: conf.SlackHandle,
-	}
+	initCache := newInitCommandsCache(conf)
+	sessionBudget := newBudget(conf.Session.HourlyBudget, time.Hour)
 
-	var systemPromptBuilder strings.Builder
-	err := systemPromptTemplate.Execute(&systemPromptBuilder, systemPromptData)
-	if err != nil {
-		return fmt.Errorf("failed to execute system prompt template: %w", err)
+	// Concurrent sessions are unlimited if no limit is configured.
+	var slots chan struct{}
+	if conf.MaxConcurrentSessions > 0 {
+		slots = make(chan struct{}, conf.MaxConcurrentSessions)
 	}
-	systemPrompt = systemPromptBuilder.String()
-
-	slog.Info("Session service started")
 
+	// Sessions are started from this goroutine so that every wg.Add happens
+	// before wg.Wait.
 	var wg sync.WaitGroup
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case alert := <-c:
-				wg.Add(1)
-				go func(alert any, llmModel llms.Model, mcpClients *client.Clients, conf *config.Config) {
-					defer wg.Done()
-					run(ctx, alert, llmModel, mcpClients, conf)
-				}(alert, llmModel, mcpClients, conf)
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Waiting for sessions to complete")
+			wg.Wait()
+			slog.Info("Session service stopped")
+
+			return nil
+		case reloaded := <-reloads:
+			listenConf.MaxCalls = reloaded.MaxCalls
+			slog.Info("Reloaded session service", "max_calls", listenConf.MaxCalls)
+		case alert := <-c:
+			if !sessionBudget.wait(ctx) {
+				continue
 			}
+
+			// Wait for a free session slot, alerts queue up meanwhile.
+			if !acquire(ctx, slots) {
+				continue
+			}
+
+			sessionConf := listenConf
+			wg.Add(1)
+			go func(alert any) {
+				defer wg.Done()
+				defer release(slots)
+				// Failures are logged by run.
+				_, _ = run(ctx, alert, llmModels, mcpClients, alertClient, broker, initCache, approver, &sessionConf)
+			}(alert)
 		}
-	}()
+	}
+}
+
+// acquire acquires a session slot, waiting for one to be free. It returns
+// false if the context is canceled while waiting. Slots are unlimited if the
+// slots channel is nil.
+func acquire(ctx context.Context, slots chan struct{}) bool {
+	if slots == nil {
+		return true
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case slots <- struct{}{}:
+		return true
+	}
+}
+
+// release releases a session slot acquired with acquire.
+func release(slots chan struct{}) {
+	if slots != nil {
+		<-slots
+	}
+}
+
+// ProcessSingleAlert starts a session for the given alert and returns its
+// result once the session is over.
+func ProcessSingleAlert(ctx context.Context, alert any, llmModels []llm.Model, mcpClients *client.Clients, alertClient *opsgenie.AlertClient, conf *config.Config) (Result, error) {
+	initCache := newInitCommandsCache(conf)
 
-	<-ctx.Done()
-	slog.Info("Waiting for sessions to complete")
-	wg.Wait()
-	slog.Info("Session service stopped")
+	return run(ctx, alert, llmModels, mcpClients, alertClient, nil, initCache, nil, conf)
+}
+
+// ProcessInteractive starts a session for the given alert driven by the
+// interaction, which approves the tool calls and adds instructions between the
+// LLM calls, and returns its result once the session is over.
+func ProcessInteractive(ctx context.Context, alert any, llmModels []llm.Model, mcpClients *client.Clients, alertClient *opsgenie.AlertClient, interaction Interaction, conf *config.Config) (Result, error) {
+	initCache := newInitCommandsCache(conf)
 
-	return nil
+	return run(ctx, alert, llmModels, mcpClients, alertClient, nil, initCache, interaction, conf)
 }
 
-// run starts a new session for the given alert.
-func run(ctx context.Context, alert any, llmModel llms.Model, mcpClients *client.Clients, conf *config.Config) {
+// run starts a new session for the given alert, whose tool calls are executed
+// once approved by the approver if not nil. The session is driven by the
+// approver if it is an Interaction. Failures are logged and returned along
+// with the errored result.
+func run(ctx context.Context, alert any, llmModels []llm.Model, mcpClients *client.Clients, alertClient *opsgenie.AlertClient, broker *events.Broker, initCache *initCommandsCache, approver Approver, conf *config.Config) (Result, error) {
+	// The records logged before the session starts carry the attributes of
+	// its alert.
+	ctx = logger.NewContext(ctx, slog.Default().With(alertLogAttrs(alert, conf)...))
+	log := logger.FromContext(ctx)
+
+	err := runInitCommands(ctx, conf, alert, initCache)
+	if err != nil {
+		log.Error("Failed to run session init commands", "error", err)
+		return errorResult(err), err
+	}
+
+	// Non-shared servers are registered on a clone of the shared clients, so
+	// that only they are closed when the session ends.
 	sessionClients := mcpClients.Clone()
-	// TODO: close non-shared clients
-	err := sessionClients.RegisterServersConfig(ctx, conf.GetMCPServers(false))
+	if a, ok := opsgenieAlert(alert); ok {
+		sessionClients.SetInstallation(opsgenie.Installation(a))
+	}
+	defer func() {
+		err := sessionClients.Close()
+		if err != nil {
+			log.Warn("Failed to close session MCP clients", "error", err)
+		}
+	}()
+	err = sessionClients.RegisterServersConfig(ctx, conf.GetMCPServers(false), conf.MCP.FailFast)
 	if err != nil {
-		slog.Error("Failed to register MCP servers", "error", err)
-		return
+		log.Error("Failed to register MCP servers", "error", err)
+		return errorResult(err), err
+	}
+
+	if conf.OpsGenie.AlertTools && alertClient != nil {
+		err = registerAlertsServer(ctx, sessionClients, alertClient, alert, conf)
+		if err != nil {
+			log.Error("Failed to register alerts MCP server", "error", err)
+			return errorResult(err), err
+		}
 	}
 
-	s, err := New(alert, llmModel, sessionClients, conf.MaxCalls, conf.SessionsLogDir)
+	s, err := New(alert, llmModels, sessionClients, broker, conf)
 	if err != nil {
-		slog.Error("Failed to create new session", "error", err)
-		return
+		log.Error("Failed to create new session", "error", err)
+		return errorResult(err), err
+	}
+	s.approver = approver
+	s.interaction, _ = approver.(Interaction)
+
+	if conf.OpsGenie.IncludeNotes && alertClient != nil {
+		s.notes, err = alertNotes(ctx, alertClient, alert)
+		if err != nil {
+			s.logger.Warn("Failed to fetch alert notes", "error", err)
+		}
+	}
+
+	acknowledged := false
+	if conf.OpsGenie.AckOnStart && alertClient != nil {
+		err = acknowledge(ctx, alertClient, s, conf)
+		if err != nil {
+			s.logger.Warn("Failed to acknowledge alert", "error", err)
+		} else {
+			acknowledged = true
+		}
+	}
+
+	sessionErr := s.Run(ctx)
+	if sessionErr == nil && conf.OpsGenie.PostNotes && alertClient != nil && s.finalResponse != "" {
+		err = postSummary(ctx, alertClient, s, conf)
+		if err != nil {
+			s.logger.Warn("Failed to post investigation summary to alert", "error", err)
+		}
+	}
+	if sessionErr == nil && conf.Slack.PostSummaries && s.finalResponse != "" {
+		err = notifySlack(ctx, s, conf)
+		if err != nil {
+			s.logger.Warn("Failed to post investigation summary to Slack", "error", err)
+		}
+	}
+	if sessionErr != nil && acknowledged && conf.OpsGenie.UnackOnFailure {
+		err = unacknowledge(ctx, alertClient, s, sessionErr, conf)
+		if err != nil {
+			s.logger.Warn("Failed to unacknowledge alert", "error", err)
+		}
 	}
 
-	s.Run(ctx)
+	return s.Result(), sessionErr
 }
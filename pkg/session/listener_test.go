@@ -0,0 +1,292 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+	"github.com/opsgenie/opsgenie-go-sdk-v2/incident"
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/llm"
+)
+
+func TestListenConcurrentSessions(t *testing.T) {
+	conf := testConfig(t)
+	conf.Session.SystemPromptFile = filepath.Join(t.TempDir(), "system-prompt.tmpl")
+	err := os.WriteFile(conf.Session.SystemPromptFile, []byte("Investigate {{ .AlertID }}"), 0600)
+	if err != nil {
+		t.Fatalf("failed to write system prompt template: %v", err)
+	}
+
+	model := &fakeModel{}
+	models := []llm.Model{{Model: model, Config: config.LLM{Model: "fake"}}}
+	clients := newTestClients(t, &echoServer{})
+
+	const sessions = 8
+	alerts := make(chan any, sessions)
+	for i := range sessions {
+		alerts <- &alert.GetAlertResult{Id: fmt.Sprintf("alert-%d", i)}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- Listen(ctx, alerts, nil, models, clients, nil, nil, nil, conf)
+	}()
+
+	// Wait for every session to call the LLM.
+	deadline := time.After(10 * time.Second)
+	for {
+		model.mu.Lock()
+		calls := len(model.calls)
+		model.mu.Unlock()
+		if calls == sessions {
+			break
+		}
+
+		select {
+		case <-deadline:
+			t.Fatalf("expected %d sessions to call the LLM, got %d", sessions, calls)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	cancel()
+	err = <-done
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	// Every session is prompted with its own alert.
+	var prompts []string
+	for _, messages := range model.calls {
+		prompts = append(prompts, systemMessage(messages))
+	}
+	slices.Sort(prompts)
+
+	var expected []string
+	for i := range sessions {
+		expected = append(expected, fmt.Sprintf("Investigate alert-%d", i))
+	}
+	if !slices.Equal(prompts, expected) {
+		t.Errorf("expected prompts %q, got %q", expected, prompts)
+	}
+}
+
+// blockingModel is an LLM whose calls block until released, recording the
+// number of concurrent calls.
+type blockingModel struct {
+	release chan struct{}
+
+	mu          sync.Mutex
+	calls       int
+	inFlight    int
+	maxInFlight int
+}
+
+// GenerateContent returns a final answer once the calls are released.
+func (m *blockingModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	m.mu.Lock()
+	m.calls++
+	m.inFlight++
+	m.maxInFlight = max(m.maxInFlight, m.inFlight)
+	m.mu.Unlock()
+
+	defer func() {
+		m.mu.Lock()
+		m.inFlight--
+		m.mu.Unlock()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-m.release:
+	}
+
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: "The alert is resolved. " + testEndPhrase}}}, nil
+}
+
+// Call generates a completion of the prompt.
+func (m *blockingModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+// waitFor waits for the condition on the model to hold.
+func (m *blockingModel) waitFor(t *testing.T, condition func(m *blockingModel) bool) {
+	t.Helper()
+
+	deadline := time.After(10 * time.Second)
+	for {
+		m.mu.Lock()
+		ok := condition(m)
+		m.mu.Unlock()
+		if ok {
+			return
+		}
+
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for the sessions")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestListenMaxConcurrentSessions(t *testing.T) {
+	const (
+		maxConcurrentSessions = 2
+		sessions              = 6
+	)
+
+	conf := testConfig(t)
+	conf.MaxConcurrentSessions = maxConcurrentSessions
+
+	model := &blockingModel{release: make(chan struct{})}
+	models := []llm.Model{{Model: model, Config: config.LLM{Model: "fake"}}}
+	clients := newTestClients(t, &echoServer{})
+
+	alerts := make(chan any, sessions)
+	for i := range sessions {
+		alerts <- &alert.GetAlertResult{Id: fmt.Sprintf("alert-%d", i)}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- Listen(ctx, alerts, nil, models, clients, nil, nil, nil, conf)
+	}()
+
+	// Sessions beyond the limit wait for a slot rather than start.
+	model.waitFor(t, func(m *blockingModel) bool { return m.inFlight == maxConcurrentSessions })
+	time.Sleep(50 * time.Millisecond)
+
+	model.mu.Lock()
+	calls := model.calls
+	model.mu.Unlock()
+	if calls != maxConcurrentSessions {
+		t.Errorf("expected %d sessions to start, got %d", maxConcurrentSessions, calls)
+	}
+
+	// Every queued session runs once the slots are freed.
+	close(model.release)
+	model.waitFor(t, func(m *blockingModel) bool { return m.calls == sessions && m.inFlight == 0 })
+
+	cancel()
+	err := <-done
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	if model.maxInFlight > maxConcurrentSessions {
+		t.Errorf("expected at most %d concurrent sessions, got %d", maxConcurrentSessions, model.maxInFlight)
+	}
+}
+
+func TestListenCancelQueuedSessions(t *testing.T) {
+	conf := testConfig(t)
+	conf.MaxConcurrentSessions = 1
+
+	model := &blockingModel{release: make(chan struct{})}
+	models := []llm.Model{{Model: model, Config: config.LLM{Model: "fake"}}}
+
+	alerts := make(chan any, 3)
+	for i := range 3 {
+		alerts <- &alert.GetAlertResult{Id: fmt.Sprintf("alert-%d", i)}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- Listen(ctx, alerts, nil, models, newTestClients(t, &echoServer{}), nil, nil, nil, conf)
+	}()
+
+	// Canceling while sessions wait for a slot stops the running session and
+	// drops the queued ones.
+	model.waitFor(t, func(m *blockingModel) bool { return m.inFlight == 1 })
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("failed to listen: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected listening to stop once canceled")
+	}
+
+	if model.calls != 1 {
+		t.Errorf("expected only the running session to call the LLM, got %d calls", model.calls)
+	}
+}
+
+func TestProcessIncident(t *testing.T) {
+	alertClient, fake := newTestAlertClient(t)
+	clients := newTestClients(t, &echoServer{})
+
+	var summary []byte
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		summary, _ = io.ReadAll(r.Body)
+	}))
+	t.Cleanup(webhook.Close)
+
+	conf := testConfig(t)
+	conf.OpsGenie.AckOnStart = true
+	conf.OpsGenie.PostNotes = true
+	conf.OpsGenie.UnackOnFailure = true
+	conf.Slack.PostSummaries = true
+	conf.Slack.WebhookURL = webhook.URL
+
+	// The session logger derives from the default logger once the session is
+	// created.
+	previous := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	var out bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	models := []llm.Model{{Model: &fakeModel{}, Config: config.LLM{Model: "fake"}}}
+	payload := &incident.Incident{Id: "incident-1", Message: "Cluster is down"}
+	_, err := ProcessSingleAlert(context.Background(), payload, models, clients, alertClient, conf)
+	if err != nil {
+		t.Fatalf("failed to process incident: %v", err)
+	}
+
+	// The OpsGenie alert actions are skipped without a warning.
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var record map[string]any
+		err = decoder.Decode(&record)
+		if err != nil {
+			t.Fatalf("failed to decode log record: %v", err)
+		}
+		if record["level"] == slog.LevelWarn.String() || record["level"] == slog.LevelError.String() {
+			t.Errorf("expected no warning or error, got %v", record)
+		}
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.notes) != 0 {
+		t.Errorf("expected no note to be added, got %v", fake.notes)
+	}
+
+	if !strings.Contains(string(summary), "Cluster is down") {
+		t.Errorf("expected the Slack summary of the incident, got %s", summary)
+	}
+}
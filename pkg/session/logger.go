@@ -0,0 +1,36 @@
+package session
+
+import (
+	"log/slog"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+// alertLogAttrs returns the log attributes correlating the records with the
+// alert carried by a session payload and its team, none if the payload is not
+// an alert.
+func alertLogAttrs(payload any, conf *config.Config) []any {
+	a, ok := opsgenieAlert(payload)
+	if !ok {
+		return nil
+	}
+
+	attrs := []any{"alert.id", a.Id}
+
+	team := conf.OpsGenie.Team
+	if alertTeam := alertTeam(a); alertTeam != "" {
+		team = alertTeam
+	}
+	if team != "" {
+		attrs = append(attrs, "team", team)
+	}
+
+	return attrs
+}
+
+// sessionLogger returns the logger of a session, a child of the default
+// logger whose records carry the session ID along with the attributes of its
+// alert, so that the logs of a session can be correlated end to end.
+func sessionLogger(id string, payload any, conf *config.Config) *slog.Logger {
+	return slog.Default().With(append([]any{"session.id", id}, alertLogAttrs(payload, conf)...)...)
+}
@@ -0,0 +1,110 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestAlertLogAttrs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		payload  any
+		expected []any
+	}{
+		{
+			name:     "alert of the configured team",
+			payload:  &alert.GetAlertResult{Id: "alert-1"},
+			expected: []any{"alert.id", "alert-1", "team", "configured"},
+		},
+		{
+			name: "alert of a responder team",
+			payload: &alert.GetAlertResult{Id: "alert-1", Responders: []alert.Responder{
+				{Type: alert.UserResponder, Name: "user"},
+				{Type: alert.TeamResponder, Name: "phoenix"},
+			}},
+			expected: []any{"alert.id", "alert-1", "team", "phoenix"},
+		},
+		{
+			name: "alert fetched for a team",
+			payload: &alert.GetAlertResult{Id: "alert-1", Tags: []string{"oka-team:atlas"}, Responders: []alert.Responder{
+				{Type: alert.TeamResponder, Name: "phoenix"},
+			}},
+			expected: []any{"alert.id", "alert-1", "team", "atlas"},
+		},
+		{
+			name:     "not an alert",
+			payload:  map[string]any{"message": "test"},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := testConfig(t)
+			conf.OpsGenie.Team = "configured"
+
+			if attrs := alertLogAttrs(tc.payload, conf); !reflect.DeepEqual(attrs, tc.expected) {
+				t.Errorf("expected attributes %v, got %v", tc.expected, attrs)
+			}
+		})
+	}
+}
+
+func TestSessionLogAttributes(t *testing.T) {
+	clients := newTestClients(t, &echoServer{})
+
+	// The session logger derives from the default logger once the session is
+	// created.
+	previous := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	var out bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	conf := testConfig(t)
+	conf.OpsGenie.Team = "phoenix"
+
+	model := &fakeModel{
+		responses: []*llms.ContentChoice{
+			toolCallChoice("call-1", echoTool, `{"text": "a"}`),
+		},
+	}
+	s := newTestSession(t, &alert.GetAlertResult{Id: "alert-1"}, model, clients, conf)
+
+	err := s.Run(context.Background())
+	if err != nil {
+		t.Fatalf("failed to run session: %v", err)
+	}
+
+	expected := map[string]string{"session.id": s.ID, "alert.id": "alert-1", "team": "phoenix"}
+	logged := make(map[string]bool)
+	for line := range bytes.Lines(out.Bytes()) {
+		var record map[string]any
+		err := json.Unmarshal(line, &record)
+		if err != nil {
+			t.Fatalf("failed to parse log record %q: %v", line, err)
+		}
+
+		msg, _ := record["msg"].(string)
+		logged[msg] = true
+		for key, value := range expected {
+			if record[key] != value {
+				t.Errorf("expected record %q to carry %s=%s, got %v", msg, key, value, record[key])
+			}
+		}
+	}
+
+	// The records of the whole session, tool calls included, are correlated.
+	for _, msg := range []string{"Starting session", "Calling LLM", "Tool call", "Tool response", "Stopping session"} {
+		if !logged[msg] {
+			t.Errorf("expected record %q to be logged", msg)
+		}
+	}
+}
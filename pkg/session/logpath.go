@@ -0,0 +1,62 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig"
+)
+
+// logPathData holds the data available to the session log path template.
+type logPathData struct {
+	AlertID   string
+	Date      string
+	SessionID string
+	Team      string
+}
+
+// sessionLogPath renders the path of a session log file within the sessions
+// log directory, creating the nested directories if needed. The rendered path
+// must stay within the sessions log directory.
+func sessionLogPath(logDir string, pathTemplate string, sessionID string, payload any, team string) (string, error) {
+	tmpl, err := template.New("path").Funcs(sprig.FuncMap()).Option("missingkey=error").Parse(pathTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse session log path template: %w", err)
+	}
+
+	data := logPathData{
+		Date:      time.Now().Format(time.DateOnly),
+		SessionID: sessionID,
+		Team:      team,
+	}
+
+	if a, ok := opsgenieAlert(payload); ok {
+		data.AlertID = a.Id
+		if t := alertTeam(a); t != "" {
+			data.Team = t
+		}
+	}
+
+	var pathBuilder strings.Builder
+	err = tmpl.Execute(&pathBuilder, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute session log path template: %w", err)
+	}
+
+	path := filepath.Clean(pathBuilder.String())
+	if !filepath.IsLocal(path) {
+		return "", fmt.Errorf("session log path %q is not within the sessions log directory", path)
+	}
+
+	path = filepath.Join(logDir, path)
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return "", fmt.Errorf("failed to create session log directory: %w", err)
+	}
+
+	return path, nil
+}
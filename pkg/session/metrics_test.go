@@ -0,0 +1,85 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/giantswarm/oka/pkg/metrics"
+)
+
+func TestSessionMetrics(t *testing.T) {
+	toolCall := toolCallChoice("call-1", echoTool, `{"text": "a"}`)
+	toolCall.GenerationInfo = map[string]any{"PromptTokens": 100, "CompletionTokens": 20}
+	model := &fakeModel{
+		responses: []*llms.ContentChoice{
+			toolCall,
+			toolCallChoice("call-2", echoTool, `{"text": "a"}`),
+		},
+	}
+	s := newTestSession(t, map[string]any{"message": "test"}, model, newTestClients(t, &echoServer{}), testConfig(t))
+
+	// The metrics are global, the activity of the session is measured by the
+	// difference of their values.
+	values := func() []float64 {
+		return []float64{
+			testutil.ToFloat64(metrics.SessionsStarted),
+			testutil.ToFloat64(metrics.SessionsFailed),
+			testutil.ToFloat64(metrics.ToolCalls.WithLabelValues(echoTool, "success")),
+			testutil.ToFloat64(metrics.ToolCalls.WithLabelValues(echoTool, "cached")),
+			testutil.ToFloat64(metrics.LLMTokens.WithLabelValues("fake", "prompt")),
+			testutil.ToFloat64(metrics.LLMTokens.WithLabelValues("fake", "completion")),
+		}
+	}
+	names := []string{"sessions started", "sessions failed", "successful tool calls", "cached tool calls", "prompt tokens", "completion tokens"}
+	before := values()
+	callsBefore := llmCallCount(t, "fake")
+
+	err := s.Run(context.Background())
+	if err != nil {
+		t.Fatalf("failed to run session: %v", err)
+	}
+
+	expected := []float64{1, 0, 1, 1, 100, 20}
+	for i, after := range values() {
+		if delta := after - before[i]; delta != expected[i] {
+			t.Errorf("expected %g %s, got %g", expected[i], names[i], delta)
+		}
+	}
+
+	completed := testutil.ToFloat64(metrics.SessionsCompleted.WithLabelValues(string(s.result.Outcome)))
+	if completed < 1 {
+		t.Errorf("expected the session to be counted as completed with outcome %s, got %g", s.result.Outcome, completed)
+	}
+	if calls := llmCallCount(t, "fake") - callsBefore; calls != uint64(len(model.calls)) {
+		t.Errorf("expected the duration of %d LLM calls to be observed, got %d", len(model.calls), calls)
+	}
+}
+
+// llmCallCount returns the number of LLM calls of the model whose duration
+// was observed.
+func llmCallCount(t *testing.T, model string) uint64 {
+	t.Helper()
+
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != "oka_llm_call_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "model" && label.GetValue() == model {
+					return metric.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,56 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/opsgenie"
+)
+
+// alertNotes fetches the notes left on the alert carried by the session
+// payload, if the payload is one.
+func alertNotes(ctx context.Context, alertClient *opsgenie.AlertClient, payload any) ([]alert.AlertNote, error) {
+	a, ok := opsgenieAlert(payload)
+	if !ok {
+		return nil, nil
+	}
+
+	return alertClient.ListAlertNotes(ctx, a.Id)
+}
+
+// maxNoteLength is the maximum length of an alert note accepted by OpsGenie.
+const maxNoteLength = 25000
+
+// postSummary adds the final response of the session to the investigated
+// alert as a note, truncated to the maximum note length. Sessions whose payload
+// is not an alert, e.g. an incident, have no alert to add the note to.
+func postSummary(ctx context.Context, alertClient *opsgenie.AlertClient, s *Session, conf *config.Config) error {
+	a, ok := opsgenieAlert(s.alert)
+	if !ok {
+		return nil
+	}
+
+	note := fmt.Sprintf("OKA investigation summary (session %s):\n\n%s", s.ID, s.finalResponse)
+	if len(note) > maxNoteLength {
+		note = strings.ToValidUTF8(note[:maxNoteLength], "")
+	}
+
+	_, err := alertClient.AddNote(ctx, a.Id, conf.OpsGenie.ActionUser, note, conf.OpsGenie.ActionSource)
+	return err
+}
+
+// formatNotes formats the alert notes for the session context, one note per
+// line with its author and creation time.
+func formatNotes(notes []alert.AlertNote) string {
+	var b strings.Builder
+	for _, note := range notes {
+		fmt.Fprintf(&b, "- [%s] %s: %s\n", note.CreatedAt.Format(time.RFC3339), note.Owner, note.Note)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/llm"
+)
+
+func TestPostNotes(t *testing.T) {
+	testCases := []struct {
+		name      string
+		postNotes bool
+	}{
+		{
+			name:      "enabled",
+			postNotes: true,
+		},
+		{
+			name:      "disabled",
+			postNotes: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := testConfig(t)
+			conf.OpsGenie.PostNotes = tc.postNotes
+			alertClient, fake := newTestAlertClient(t)
+
+			models := []llm.Model{{Model: &fakeModel{}, Config: config.LLM{Model: "fake"}}}
+			a := &alert.GetAlertResult{Id: "alert-1", Message: "test"}
+			_, err := ProcessSingleAlert(context.Background(), a, models, newTestClients(t, &echoServer{}), alertClient, conf)
+			if err != nil {
+				t.Fatalf("failed to process alert: %v", err)
+			}
+
+			notes := fake.notes["alert-1"]
+			if !tc.postNotes {
+				if len(notes) != 0 {
+					t.Errorf("expected no note to be posted, got %q", notes)
+				}
+				return
+			}
+
+			if len(notes) != 1 {
+				t.Fatalf("expected a note to be posted, got %q", notes)
+			}
+			if !strings.HasPrefix(notes[0], "OKA investigation summary") || !strings.Contains(notes[0], "The alert is resolved.") {
+				t.Errorf("expected the note to hold the investigation summary, got %q", notes[0])
+			}
+		})
+	}
+}
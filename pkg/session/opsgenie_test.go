@@ -0,0 +1,79 @@
+package session
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/oka/pkg/opsgenie"
+)
+
+// fakeAPIKeyEnvVar is the environment variable holding the API key of the
+// clients of the fake OpsGenie API.
+const fakeAPIKeyEnvVar = "OKA_TEST_OPSGENIE_API_KEY"
+
+// fakeOpsGenie is a fake OpsGenie API recording the notes added to the alerts.
+type fakeOpsGenie struct {
+	mu    sync.Mutex
+	notes map[string][]string
+}
+
+// newTestAlertClient returns an alert client of a fake OpsGenie API, stopped
+// at the end of the test.
+func newTestAlertClient(t *testing.T) (*opsgenie.AlertClient, *fakeOpsGenie) {
+	t.Helper()
+	t.Setenv(fakeAPIKeyEnvVar, "test")
+
+	f := &fakeOpsGenie{
+		notes: make(map[string][]string),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v2/alerts/{id}/notes", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Note string `json:"note"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		f.mu.Lock()
+		f.notes[r.PathValue("id")] = append(f.notes[r.PathValue("id")], body.Note)
+		f.mu.Unlock()
+
+		writeFakeResponse(w, http.StatusAccepted, nil)
+	})
+	mux.HandleFunc("GET /v2/alerts/requests/{id}", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeResponse(w, http.StatusOK, map[string]any{"isSuccess": true, "status": "processed"})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	// The OpsGenie clients use plain HTTP for hosts which are not OpsGenie
+	// API hosts.
+	alertClient, err := opsgenie.NewAlertClient(strings.TrimPrefix(server.URL, "http://"), fakeAPIKeyEnvVar, 0, time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to create alert client: %v", err)
+	}
+
+	return alertClient, f
+}
+
+// writeFakeResponse writes an OpsGenie API response with the data.
+func writeFakeResponse(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Request-Id", "test")
+	w.Header().Set("X-Response-Time", "0.01")
+	w.Header().Set("X-RateLimit-State", "OK")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"data":      data,
+		"result":    "Request will be processed",
+		"took":      0.01,
+		"requestId": "test",
+	})
+}
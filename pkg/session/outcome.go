@@ -0,0 +1,66 @@
+package session
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Outcome is the machine-readable outcome of a session.
+type Outcome string
+
+// Session outcomes, recorded at the end of the session.
+const (
+	// OutcomeCompleted is the outcome of the sessions whose investigation
+	// completed, resolving the alert or not.
+	OutcomeCompleted Outcome = "completed"
+	// OutcomeCallLimit is the outcome of the sessions which hit the limit of
+	// LLM calls, their investigation may be incomplete.
+	OutcomeCallLimit Outcome = "hit-call-limit"
+	// OutcomeTimeout is the outcome of the sessions which exhausted their
+	// time budget, their investigation may be incomplete.
+	OutcomeTimeout Outcome = "timed-out"
+	// OutcomeError is the outcome of the sessions which failed or were
+	// canceled.
+	OutcomeError Outcome = "errored"
+	// OutcomeEscalated is the outcome of the sessions whose investigation
+	// completed with the LLM requesting an escalation to a human.
+	OutcomeEscalated Outcome = "escalated"
+)
+
+// Result is the outcome of a session along with a short reason.
+type Result struct {
+	Outcome Outcome
+	Reason  string
+}
+
+// Result returns the result of the session, set once it has run.
+func (s *Session) Result() Result {
+	return s.result
+}
+
+// statusRegexp matches the status the LLM concludes its final summary with,
+// as requested by the system prompt.
+var statusRegexp = regexp.MustCompile("(?i)status:?\\**:?\\s*`?(RESOLVED|INVESTIGATED|ESCALATE)")
+
+// completionResult returns the result of a session completed with the given
+// final response, escalated if the LLM concluded with the ESCALATE status.
+func completionResult(response string) Result {
+	matches := statusRegexp.FindAllStringSubmatch(response, -1)
+	if len(matches) == 0 {
+		return Result{Outcome: OutcomeCompleted, Reason: "the investigation completed without status"}
+	}
+
+	status := strings.ToUpper(matches[len(matches)-1][1])
+	if status == "ESCALATE" {
+		return Result{Outcome: OutcomeEscalated, Reason: "the LLM requested an escalation"}
+	}
+
+	return Result{Outcome: OutcomeCompleted, Reason: fmt.Sprintf("the investigation completed with status %s", status)}
+}
+
+// errorResult returns the result of a session which failed with the given
+// error.
+func errorResult(err error) Result {
+	return Result{Outcome: OutcomeError, Reason: err.Error()}
+}
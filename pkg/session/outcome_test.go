@@ -0,0 +1,141 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/llm"
+	"github.com/giantswarm/oka/pkg/mcp/client"
+)
+
+func TestSessionOutcome(t *testing.T) {
+	testCases := []struct {
+		name            string
+		responses       []*llms.ContentChoice
+		maxCalls        int
+		timeout         time.Duration
+		slowTool        bool
+		expectedOutcome Outcome
+		expectedReason  string
+		expectedErr     bool
+	}{
+		{
+			name:            "completed",
+			responses:       []*llms.ContentChoice{{Content: "Disk freed.\n**Status:** RESOLVED\n" + testEndPhrase}},
+			expectedOutcome: OutcomeCompleted,
+			expectedReason:  "the investigation completed with status RESOLVED",
+		},
+		{
+			name:            "completed without status",
+			expectedOutcome: OutcomeCompleted,
+			expectedReason:  "the investigation completed without status",
+		},
+		{
+			name:            "escalated",
+			responses:       []*llms.ContentChoice{{Content: "Unknown cause.\nStatus: `ESCALATE`\n" + testEndPhrase}},
+			expectedOutcome: OutcomeEscalated,
+			expectedReason:  "the LLM requested an escalation",
+		},
+		{
+			name: "hit call limit",
+			responses: []*llms.ContentChoice{
+				toolCallChoice("call-1", echoTool, `{"text": "a"}`),
+				toolCallChoice("call-2", echoTool, `{"text": "b"}`),
+			},
+			maxCalls:        2,
+			expectedOutcome: OutcomeCallLimit,
+			expectedReason:  "the session hit the limit of 2 LLM calls",
+		},
+		{
+			name: "timed out",
+			responses: []*llms.ContentChoice{
+				toolCallChoice("call-1", slowTool, `{}`),
+			},
+			timeout:         50 * time.Millisecond,
+			slowTool:        true,
+			expectedOutcome: OutcomeTimeout,
+			expectedReason:  "the session exhausted its time budget of 50ms",
+		},
+		{
+			name: "errored",
+			responses: []*llms.ContentChoice{
+				toolCallChoice("call-1", echoTool, `{"text": `),
+			},
+			expectedOutcome: OutcomeError,
+			expectedReason:  "failed to unmarshal tool call arguments",
+			expectedErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := testConfig(t)
+			conf.SessionsJSON = true
+			conf.Session.Timeout = tc.timeout
+			if tc.maxCalls > 0 {
+				conf.MaxCalls = tc.maxCalls
+			}
+
+			clients := newTestClients(t, &echoServer{})
+			if tc.slowTool {
+				clients = newSlowClients(t, time.Minute)
+			}
+			s := newTestSession(t, map[string]any{"message": "test"}, &fakeModel{responses: tc.responses}, clients, conf)
+
+			err := s.Run(context.Background())
+			if tc.expectedErr != (err != nil) {
+				t.Errorf("expected error to be %t, got %v", tc.expectedErr, err)
+			}
+
+			result := s.Result()
+			if result.Outcome != tc.expectedOutcome {
+				t.Errorf("expected outcome %s, got %s", tc.expectedOutcome, result.Outcome)
+			}
+			if !strings.Contains(result.Reason, tc.expectedReason) {
+				t.Errorf("expected reason containing %q, got %q", tc.expectedReason, result.Reason)
+			}
+
+			// The outcome is recorded at the end of the transcript.
+			data, err := os.ReadFile(transcriptPath(s.logFile.Name()))
+			if err != nil {
+				t.Fatalf("failed to read session transcript: %v", err)
+			}
+			var transcript Transcript
+			err = json.Unmarshal(data, &transcript)
+			if err != nil {
+				t.Fatalf("failed to parse session transcript: %v", err)
+			}
+			end := transcript.Events[len(transcript.Events)-1]
+			if end.Type != transcriptEnd || end.Outcome != string(result.Outcome) || end.Reason != result.Reason {
+				t.Errorf("expected the transcript to end with outcome %s, got %+v", result.Outcome, end)
+			}
+		})
+	}
+}
+
+func TestProcessSingleAlertResult(t *testing.T) {
+	conf := testConfig(t)
+	conf.MCPServers = config.MCPServers{}
+
+	model := &fakeModel{
+		responses: []*llms.ContentChoice{{Content: "Status: ESCALATE\n" + testEndPhrase}},
+	}
+	models := []llm.Model{{Model: model, Config: config.LLM{Model: "fake"}}}
+	clients := client.New()
+	t.Cleanup(func() { _ = clients.Close() })
+
+	result, err := ProcessSingleAlert(context.Background(), map[string]any{"message": "test"}, models, clients, nil, conf)
+	if err != nil {
+		t.Fatalf("failed to process alert: %v", err)
+	}
+	if result.Outcome != OutcomeEscalated {
+		t.Errorf("expected outcome %s, got %s", OutcomeEscalated, result.Outcome)
+	}
+}
@@ -0,0 +1,127 @@
+package session
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/opsgenie"
+	"github.com/giantswarm/oka/pkg/templates"
+)
+
+//go:embed system-prompt.tmpl
+var systemPromptTmpl string
+
+// systemPromptTemplate is the built-in system prompt template, parsed once and
+// only executed afterwards, which is safe for concurrent sessions. Each
+// session renders its own system prompt from its alert, see New.
+var systemPromptTemplate *template.Template
+
+func init() {
+	systemPromptTemplate = template.Must(templates.ParseSystemPrompt(systemPromptTmpl))
+}
+
+// systemPromptData holds the data available to the system prompt template.
+type systemPromptData struct {
+	Date             string // Current date, as YYYY-MM-DD
+	EndSessionPhrase string
+	Now              time.Time
+	SlackHandle      string
+
+	// Alert fields, empty if the session payload is not an OpsGenie alert.
+	Alert          any
+	AlertID        string
+	Details        map[string]string
+	IncidentAlerts int
+	IncidentID     string
+	Installation   string
+	Message        string
+	Priority       string
+	Source         string
+	Tags           []string
+	Team           string
+}
+
+// CheckSystemPrompt checks that the system prompt template, the configured
+// one or the built-in one, renders for an empty alert, so that invalid
+// templates are reported before starting sessions.
+func CheckSystemPrompt(conf *config.Config) error {
+	_, err := renderSystemPrompt(conf, &alert.GetAlertResult{})
+	return err
+}
+
+// renderSystemPrompt renders the system prompt template for a session
+// investigating the given alert. The configured template file, if any, is
+// read for every session so that it can be edited without restarting.
+func renderSystemPrompt(conf *config.Config, payload any) (string, error) {
+	tmpl := systemPromptTemplate
+	if conf.Session.SystemPromptFile != "" {
+		text, err := os.ReadFile(conf.Session.SystemPromptFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read system prompt template: %w", err)
+		}
+
+		tmpl, err = templates.ParseSystemPrompt(string(text))
+		if err != nil {
+			return "", fmt.Errorf("failed to parse system prompt template: %w", err)
+		}
+	}
+
+	now := time.Now()
+	data := systemPromptData{
+		Date:             now.Format(time.DateOnly),
+		EndSessionPhrase: conf.Session.EndPhrase,
+		Now:              now,
+		SlackHandle:      conf.SlackHandle,
+		Alert:            payload,
+		Team:             conf.OpsGenie.Team,
+	}
+
+	if a, ok := opsgenieAlert(payload); ok {
+		data.AlertID = a.Id
+		data.Details = a.Details
+		data.Installation = opsgenie.Installation(a)
+		data.Message = a.Message
+		data.Priority = string(a.Priority)
+		data.Source = a.Source
+		data.Tags = a.Tags
+		if team := alertTeam(a); team != "" {
+			data.Team = team
+		}
+	}
+
+	if incidentAlert, ok := payload.(*opsgenie.IncidentAlert); ok && incidentAlert != nil {
+		data.IncidentID = incidentAlert.IncidentID
+		data.IncidentAlerts = len(incidentAlert.IncidentAlerts)
+	}
+
+	var systemPromptBuilder strings.Builder
+	err := tmpl.Execute(&systemPromptBuilder, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute system prompt template: %w", err)
+	}
+
+	return systemPromptBuilder.String(), nil
+}
+
+// alertTeam returns the team whose query fetched the alert, or the name of
+// the first team responding to the alert.
+func alertTeam(a *alert.GetAlertResult) string {
+	if team := opsgenie.SourceTeam(a); team != "" {
+		return team
+	}
+
+	for _, responder := range a.Responders {
+		if responder.Type == alert.TeamResponder && responder.Name != "" {
+			return responder.Name
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,250 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestConcurrentSessionsSystemPrompt(t *testing.T) {
+	conf := testConfig(t)
+	conf.Session.SystemPromptFile = filepath.Join(t.TempDir(), "system-prompt.tmpl")
+	err := os.WriteFile(conf.Session.SystemPromptFile, []byte("Investigate {{ .AlertID }}: {{ .Message }}"), 0600)
+	if err != nil {
+		t.Fatalf("failed to write system prompt template: %v", err)
+	}
+
+	clients := newTestClients(t, &echoServer{})
+
+	const sessions = 8
+	models := make([]*fakeModel, sessions)
+	var wg sync.WaitGroup
+	for i := range sessions {
+		models[i] = &fakeModel{
+			responses: []*llms.ContentChoice{
+				toolCallChoice("call-1", echoTool, fmt.Sprintf(`{"text": "%d"}`, i)),
+			},
+		}
+		a := &alert.GetAlertResult{Id: fmt.Sprintf("alert-%d", i), Message: fmt.Sprintf("message %d", i)}
+		s := newTestSession(t, a, models[i], clients, conf)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.Run(context.Background())
+			if err != nil {
+				t.Errorf("failed to run session %d: %v", i, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Every session is prompted with its own alert.
+	for i, model := range models {
+		expected := fmt.Sprintf("Investigate alert-%d: message %d", i, i)
+		if prompt := systemMessage(model.calls[0]); prompt != expected {
+			t.Errorf("expected session %d to be prompted with %q, got %q", i, expected, prompt)
+		}
+	}
+}
+
+func TestRenderSystemPrompt(t *testing.T) {
+	const promptTemplate = `Alert {{ .AlertID }} ({{ .Priority }}) from {{ .Source }}: {{ .Message }}
+Team: {{ .Team }}
+Installation: {{ .Installation }}
+Tags: {{ join ", " .Tags }}
+Cluster: {{ index .Details "cluster" }}
+Incident: {{ .IncidentID }}
+Slack: {{ .SlackHandle }}
+Today: {{ .Date }}
+End with: {{ .EndSessionPhrase | upper }}`
+
+	testCases := []struct {
+		name     string
+		payload  any
+		expected string
+	}{
+		{
+			name: "alert",
+			payload: &alert.GetAlertResult{
+				Id:       "alert-1",
+				Priority: alert.P2,
+				Source:   "prometheus",
+				Message:  "Disk almost full",
+				Tags:     []string{"installation:gazelle", "severity:page"},
+				Details:  map[string]string{"cluster": "wc1"},
+				Responders: []alert.Responder{
+					{Type: alert.TeamResponder, Name: "phoenix"},
+				},
+			},
+			expected: `Alert alert-1 (P2) from prometheus: Disk almost full
+Team: phoenix
+Installation: gazelle
+Tags: installation:gazelle, severity:page
+Cluster: wc1
+Incident: 
+Slack: @oncall
+Today: %s
+End with: INVESTIGATION COMPLETE`,
+		},
+		{
+			name:    "other payload",
+			payload: map[string]any{"message": "test"},
+			expected: `Alert  () from : 
+Team: configured
+Installation: 
+Tags: 
+Cluster: 
+Incident: 
+Slack: @oncall
+Today: %s
+End with: INVESTIGATION COMPLETE`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := testConfig(t)
+			conf.SlackHandle = "@oncall"
+			conf.OpsGenie.Team = "configured"
+			conf.Session.SystemPromptFile = filepath.Join(t.TempDir(), "system-prompt.tmpl")
+			err := os.WriteFile(conf.Session.SystemPromptFile, []byte(promptTemplate), 0600)
+			if err != nil {
+				t.Fatalf("failed to write system prompt template: %v", err)
+			}
+
+			prompt, err := renderSystemPrompt(conf, tc.payload)
+			if err != nil {
+				t.Fatalf("failed to render system prompt: %v", err)
+			}
+
+			expected := fmt.Sprintf(tc.expected, time.Now().Format(time.DateOnly))
+			if prompt != expected {
+				t.Errorf("expected system prompt:\n%s\ngot:\n%s", expected, prompt)
+			}
+		})
+	}
+}
+
+func TestRenderBuiltInSystemPrompt(t *testing.T) {
+	conf := testConfig(t)
+	conf.SlackHandle = "@oncall"
+
+	prompt, err := renderSystemPrompt(conf, &alert.GetAlertResult{Id: "alert-1"})
+	if err != nil {
+		t.Fatalf("failed to render system prompt: %v", err)
+	}
+
+	for _, expected := range []string{"@oncall", testEndPhrase} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("expected the built-in system prompt to contain %q", expected)
+		}
+	}
+}
+
+func TestCheckSystemPrompt(t *testing.T) {
+	testCases := []struct {
+		name        string
+		builtIn     bool
+		template    *string
+		expectedErr string
+	}{
+		{
+			name:    "built-in template",
+			builtIn: true,
+		},
+		{
+			name:     "custom template",
+			template: ptr(`Investigate {{ .AlertID | default "the alert" }} for {{ env "USER" }}`),
+		},
+		{
+			name:        "missing file",
+			expectedErr: "failed to read system prompt template",
+		},
+		{
+			name:        "parse error",
+			template:    ptr("Investigate {{ .AlertID "),
+			expectedErr: "failed to parse system prompt template",
+		},
+		{
+			name:        "execution error",
+			template:    ptr("Investigate {{ .Unknown }}"),
+			expectedErr: "failed to execute system prompt template",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := testConfig(t)
+			if !tc.builtIn {
+				conf.Session.SystemPromptFile = filepath.Join(t.TempDir(), "system-prompt.tmpl")
+			}
+			if tc.template != nil {
+				err := os.WriteFile(conf.Session.SystemPromptFile, []byte(*tc.template), 0600)
+				if err != nil {
+					t.Fatalf("failed to write system prompt template: %v", err)
+				}
+			}
+
+			err := CheckSystemPrompt(conf)
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Errorf("expected the template to be valid, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("expected error containing %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestCustomSystemPromptFile(t *testing.T) {
+	conf := testConfig(t)
+	conf.Session.SystemPromptFile = filepath.Join(t.TempDir(), "system-prompt.tmpl")
+	err := os.WriteFile(conf.Session.SystemPromptFile, []byte("Custom prompt for {{ .AlertID | upper }}"), 0600)
+	if err != nil {
+		t.Fatalf("failed to write system prompt template: %v", err)
+	}
+
+	model := &fakeModel{}
+	s := newTestSession(t, &alert.GetAlertResult{Id: "alert-1"}, model, newTestClients(t, &echoServer{}), conf)
+	err = s.Run(context.Background())
+	if err != nil {
+		t.Fatalf("failed to run session: %v", err)
+	}
+
+	if prompt := systemMessage(model.calls[0]); prompt != "Custom prompt for ALERT-1" {
+		t.Errorf("expected the custom system prompt, got %q", prompt)
+	}
+}
+
+// ptr returns a pointer to the value.
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// systemMessage returns the text of the first system message.
+func systemMessage(messages []llms.MessageContent) string {
+	for _, message := range messages {
+		if message.Role != llms.ChatMessageTypeSystem {
+			continue
+		}
+		for _, part := range message.Parts {
+			if text, ok := part.(llms.TextContent); ok {
+				return text.Text
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,177 @@
+package session
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+// pruneInterval is the interval between two prunings of the session logs.
+const pruneInterval = time.Hour
+
+// running are the IDs of the running sessions keyed by the absolute path of
+// their log file, which is never pruned.
+var running = struct {
+	sync.Mutex
+	sessions map[string]string
+}{sessions: make(map[string]string)}
+
+// setRunning marks the session writing the log file as running or not.
+func setRunning(id, logFile string, isRunning bool) {
+	path, err := filepath.Abs(logFile)
+	if err != nil {
+		path = logFile
+	}
+
+	running.Lock()
+	defer running.Unlock()
+	if isRunning {
+		running.sessions[path] = id
+	} else {
+		delete(running.sessions, path)
+	}
+}
+
+// isLogActive returns whether the session log file is being written.
+func isLogActive(path string) bool {
+	running.Lock()
+	defer running.Unlock()
+
+	_, ok := running.sessions[path]
+	return ok
+}
+
+// Running returns the IDs of the running sessions.
+func Running() []string {
+	running.Lock()
+	defer running.Unlock()
+
+	ids := make([]string, 0, len(running.sessions))
+	for _, id := range running.sessions {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	return ids
+}
+
+// PruneLogs removes the session logs older than sessions_log_max_age and the
+// oldest ones beyond sessions_log_max_files, on start and then periodically
+// until the context is canceled. It returns immediately if no retention is
+// configured.
+func PruneLogs(ctx context.Context, conf *config.Config) {
+	if conf.SessionsLogMaxAge <= 0 && conf.SessionsLogMaxFiles <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		err := pruneLogs(conf.SessionsLogDir, conf.SessionsLogMaxFiles, conf.SessionsLogMaxAge, time.Now())
+		if err != nil {
+			slog.Error("Failed to prune session logs", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sessionLog is a session log file found in the sessions log directory.
+type sessionLog struct {
+	path    string
+	modTime time.Time
+}
+
+// pruneLogs removes the session logs, along with their transcript, older than
+// maxAge or beyond the newest maxFiles, a zero value disabling the limit. The
+// logs of the running sessions are kept, and the directories left empty are
+// removed.
+func pruneLogs(dir string, maxFiles int, maxAge time.Duration, now time.Time) error {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	var (
+		logs []sessionLog
+		dirs []string
+	)
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".log" || isLogActive(path) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		logs = append(logs, sessionLog{path: path, modTime: info.ModTime()})
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Newest first, so that the logs beyond maxFiles are the oldest ones.
+	slices.SortFunc(logs, func(a, b sessionLog) int {
+		return b.modTime.Compare(a.modTime)
+	})
+
+	removed := 0
+	for i, log := range logs {
+		expired := maxAge > 0 && now.Sub(log.modTime) > maxAge
+		if !expired && (maxFiles <= 0 || i < maxFiles) {
+			continue
+		}
+
+		err = os.Remove(log.path)
+		if err != nil {
+			slog.Warn("Failed to remove session log", "error", err, "file", log.path)
+			continue
+		}
+		removed++
+
+		err = os.Remove(transcriptPath(log.path))
+		if err != nil && !os.IsNotExist(err) {
+			slog.Warn("Failed to remove session transcript", "error", err, "file", transcriptPath(log.path))
+		}
+	}
+
+	// Remove the directories left empty, deepest first. Removing a non-empty
+	// directory fails and is ignored.
+	slices.SortFunc(dirs, func(a, b string) int {
+		return strings.Count(b, string(filepath.Separator)) - strings.Count(a, string(filepath.Separator))
+	})
+	for _, d := range dirs {
+		_ = os.Remove(d)
+	}
+
+	if removed > 0 {
+		slog.Info("Pruned session logs", "removed", removed, "dir", dir)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// writeSessionLog writes a session log file, along with its transcript,
+// modified the given duration before now.
+func writeSessionLog(t *testing.T, path string, age time.Duration, now time.Time) {
+	t.Helper()
+
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		t.Fatalf("failed to create log directory: %v", err)
+	}
+
+	for _, file := range []string{path, transcriptPath(path)} {
+		err = os.WriteFile(file, []byte("log"), 0600)
+		if err != nil {
+			t.Fatalf("failed to write %s: %v", file, err)
+		}
+		err = os.Chtimes(file, now.Add(-age), now.Add(-age))
+		if err != nil {
+			t.Fatalf("failed to set modification time of %s: %v", file, err)
+		}
+	}
+}
+
+// remainingLogs returns the session log files of the directory, relative to
+// it.
+func remainingLogs(t *testing.T, dir string) []string {
+	t.Helper()
+
+	var logs []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if filepath.Ext(path) == ".log" {
+			rel, _ := filepath.Rel(dir, path)
+			logs = append(logs, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to list session logs: %v", err)
+	}
+	slices.Sort(logs)
+
+	return logs
+}
+
+func TestPruneLogs(t *testing.T) {
+	testCases := []struct {
+		name         string
+		maxFiles     int
+		maxAge       time.Duration
+		expectedLogs []string
+	}{
+		{
+			name:         "max age",
+			maxAge:       36 * time.Hour,
+			expectedLogs: []string{"new.log", "running.log", "team/recent.log"},
+		},
+		{
+			name:         "max files",
+			maxFiles:     1,
+			expectedLogs: []string{"new.log", "running.log"},
+		},
+		{
+			name:         "max age and max files",
+			maxFiles:     3,
+			maxAge:       36 * time.Hour,
+			expectedLogs: []string{"new.log", "running.log", "team/recent.log"},
+		},
+		{
+			name:         "no limit",
+			expectedLogs: []string{"new.log", "old.log", "running.log", "team/old.log", "team/recent.log"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			now := time.Now()
+
+			writeSessionLog(t, filepath.Join(dir, "new.log"), time.Minute, now)
+			writeSessionLog(t, filepath.Join(dir, "team", "recent.log"), time.Hour, now)
+			writeSessionLog(t, filepath.Join(dir, "old.log"), 48*time.Hour, now)
+			writeSessionLog(t, filepath.Join(dir, "team", "old.log"), 72*time.Hour, now)
+
+			// The log of a running session is kept however old it is.
+			runningLog := filepath.Join(dir, "running.log")
+			writeSessionLog(t, runningLog, 96*time.Hour, now)
+			setRunning("running", runningLog, true)
+			t.Cleanup(func() { setRunning("running", runningLog, false) })
+
+			err := pruneLogs(dir, tc.maxFiles, tc.maxAge, now)
+			if err != nil {
+				t.Fatalf("failed to prune logs: %v", err)
+			}
+
+			logs := remainingLogs(t, dir)
+			if !slices.Equal(logs, tc.expectedLogs) {
+				t.Errorf("expected logs %q, got %q", tc.expectedLogs, logs)
+			}
+
+			// The transcripts are removed along with their log.
+			for _, removed := range []string{"old.log", "team/old.log"} {
+				if slices.Contains(logs, removed) {
+					continue
+				}
+				_, err := os.Stat(transcriptPath(filepath.Join(dir, removed)))
+				if !os.IsNotExist(err) {
+					t.Errorf("expected the transcript of %s to be removed, got %v", removed, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPruneLogsRemovesEmptyDirectories(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	writeSessionLog(t, filepath.Join(dir, "team", "old.log"), 48*time.Hour, now)
+
+	err := pruneLogs(dir, 0, time.Hour, now)
+	if err != nil {
+		t.Fatalf("failed to prune logs: %v", err)
+	}
+
+	_, err = os.Stat(filepath.Join(dir, "team"))
+	if !os.IsNotExist(err) {
+		t.Errorf("expected the empty directory to be removed, got %v", err)
+	}
+	_, err = os.Stat(dir)
+	if err != nil {
+		t.Errorf("expected the sessions log directory to be kept, got %v", err)
+	}
+}
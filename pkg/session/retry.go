@@ -0,0 +1,92 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// maxLLMRetryBackoff is the maximum delay between two attempts of an LLM
+// call.
+const maxLLMRetryBackoff = 2 * time.Minute
+
+// retryAfterError is implemented by the errors telling how long to wait before
+// retrying, e.g. from the Retry-After header of a rate limited response.
+type retryAfterError interface {
+	RetryAfter() time.Duration
+}
+
+// generateContent calls the given LLM model with its call options, retrying
+// transient failures up to the configured number of retries. Each attempt is given the LLM call timeout,
+// and waiting between attempts is interrupted if the context is canceled.
+func (s Session) generateContent(ctx context.Context, model sessionModel, options []llms.CallOption) (*llms.ContentResponse, error) {
+	options = append(slices.Clip(options), model.callOptions...)
+
+	for attempt := 0; ; attempt++ {
+		resp, err := s.generateContentOnce(ctx, model, options)
+		if err == nil || attempt >= s.llmMaxRetries || !isTransientLLMError(ctx, err) {
+			return resp, err
+		}
+
+		delay := llmRetryDelay(err, s.llmRetryBackoff, attempt)
+		s.logger.Warn("Retrying LLM call", "model", model.name, "attempt", attempt+1, "delay", delay, "error", err)
+		s.log("\n## LLM retry\nmodel: %s\nattempt: %d\ndelay: %s\nerror: %s\n", model.name, attempt+1, delay, err)
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// generateContentOnce calls the LLM model once, with the LLM call timeout.
+func (s Session) generateContentOnce(ctx context.Context, model sessionModel, options []llms.CallOption) (*llms.ContentResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.llmCallTimeout)
+	defer cancel()
+
+	return model.GenerateContent(ctx, s.messages, options...)
+}
+
+// isTransientLLMError returns true if the LLM call may succeed if retried,
+// i.e. it timed out, was rate limited or the provider was unavailable. Errors
+// are not transient once the session context is canceled.
+func isTransientLLMError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	// Providers do not classify their errors, they are mapped from their
+	// message (e.g. "429 Too Many Requests").
+	err = llms.NewErrorMapper("").Map(err)
+	return llms.IsRateLimitError(err) || llms.IsProviderUnavailableError(err)
+}
+
+// llmRetryDelay returns the delay before the next attempt. It is the delay
+// requested by the error if any, otherwise the backoff doubled on each attempt
+// with up to 50% of jitter.
+func llmRetryDelay(err error, backoff time.Duration, attempt int) time.Duration {
+	var retryAfterErr retryAfterError
+	if errors.As(err, &retryAfterErr) && retryAfterErr.RetryAfter() > 0 {
+		return min(retryAfterErr.RetryAfter(), maxLLMRetryBackoff)
+	}
+
+	if backoff <= 0 {
+		return 0
+	}
+
+	delay := backoff << attempt
+	if delay <= 0 || delay > maxLLMRetryBackoff {
+		delay = maxLLMRetryBackoff
+	}
+
+	return delay/2 + rand.N(delay/2+1)
+}
@@ -0,0 +1,174 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/llm"
+)
+
+// flakyModel is an LLM failing with err the given number of times before
+// answering like its fake model.
+type flakyModel struct {
+	*fakeModel
+
+	mu       sync.Mutex
+	failures int
+	err      error
+	attempts int
+}
+
+// GenerateContent fails until the failures are exhausted.
+func (m *flakyModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	m.mu.Lock()
+	m.attempts++
+	failing := m.attempts <= m.failures
+	m.mu.Unlock()
+
+	if failing {
+		return nil, m.err
+	}
+
+	return m.fakeModel.GenerateContent(ctx, messages, options...)
+}
+
+// Call generates a completion of the prompt.
+func (m *flakyModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+// retryAfter is an error requesting a delay before retrying.
+type retryAfter struct {
+	delay time.Duration
+}
+
+// Error implements the error interface.
+func (e retryAfter) Error() string {
+	return "429 Too Many Requests"
+}
+
+// RetryAfter returns the requested delay.
+func (e retryAfter) RetryAfter() time.Duration {
+	return e.delay
+}
+
+func TestLLMRetry(t *testing.T) {
+	testCases := []struct {
+		name             string
+		failures         int
+		err              error
+		expectedAttempts int
+		expectedErr      bool
+	}{
+		{
+			name:             "rate limited",
+			failures:         2,
+			err:              errors.New("Error 429: Too Many Requests"),
+			expectedAttempts: 3,
+		},
+		{
+			name:             "provider unavailable",
+			failures:         1,
+			err:              errors.New("503 Service Unavailable"),
+			expectedAttempts: 2,
+		},
+		{
+			name:             "retries exhausted",
+			failures:         5,
+			err:              errors.New("429 Too Many Requests"),
+			expectedAttempts: 4,
+			expectedErr:      true,
+		},
+		{
+			name:             "permanent failure",
+			failures:         1,
+			err:              errors.New("400 invalid request"),
+			expectedAttempts: 1,
+			expectedErr:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := testConfig(t)
+			conf.LLM.MaxRetries = 3
+			conf.LLM.RetryBackoff = time.Millisecond
+
+			model := &flakyModel{fakeModel: &fakeModel{}, failures: tc.failures, err: tc.err}
+			models := []llm.Model{{Model: model, Config: config.LLM{Model: "fake"}}}
+			s, err := New(map[string]any{"message": "test"}, models, newTestClients(t, &echoServer{}), nil, conf)
+			if err != nil {
+				t.Fatalf("failed to create session: %v", err)
+			}
+
+			err = s.Run(context.Background())
+			if tc.expectedErr != (err != nil) {
+				t.Errorf("expected error: %t, got %v", tc.expectedErr, err)
+			}
+			if model.attempts != tc.expectedAttempts {
+				t.Errorf("expected %d attempts, got %d", tc.expectedAttempts, model.attempts)
+			}
+
+			// Every retry is recorded in the session log.
+			data, err := os.ReadFile(s.logFile.Name())
+			if err != nil {
+				t.Fatalf("failed to read session log: %v", err)
+			}
+			retries := min(tc.failures, tc.expectedAttempts-1)
+			if n := strings.Count(string(data), "## LLM retry"); n != retries {
+				t.Errorf("expected %d retries in the session log, got %d", retries, n)
+			}
+		})
+	}
+}
+
+func TestLLMRetryCanceled(t *testing.T) {
+	conf := testConfig(t)
+	conf.LLM.MaxRetries = 3
+	conf.LLM.RetryBackoff = time.Hour
+
+	model := &flakyModel{fakeModel: &fakeModel{}, failures: 5, err: errors.New("429 Too Many Requests")}
+	models := []llm.Model{{Model: model, Config: config.LLM{Model: "fake"}}}
+	s, err := New(map[string]any{"message": "test"}, models, newTestClients(t, &echoServer{}), nil, conf)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_ = s.Run(ctx)
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected the cancelation to interrupt the backoff, waited %s", elapsed)
+	}
+	if model.attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", model.attempts)
+	}
+}
+
+func TestLLMRetryDelay(t *testing.T) {
+	// The delay requested by the error takes precedence over the backoff.
+	if delay := llmRetryDelay(retryAfter{delay: 3 * time.Second}, time.Hour, 0); delay != 3*time.Second {
+		t.Errorf("expected the requested delay, got %s", delay)
+	}
+	if delay := llmRetryDelay(retryAfter{delay: time.Hour}, time.Second, 0); delay != maxLLMRetryBackoff {
+		t.Errorf("expected the requested delay capped to %s, got %s", maxLLMRetryBackoff, delay)
+	}
+
+	for attempt := range 10 {
+		delay := llmRetryDelay(errors.New("429"), time.Second, attempt)
+		base := min(time.Second<<attempt, maxLLMRetryBackoff)
+		if delay < base/2 || delay > base {
+			t.Errorf("expected the delay of attempt %d within [%s, %s], got %s", attempt, base/2, base, delay)
+		}
+	}
+}
@@ -3,81 +3,261 @@ package session
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
 	"github.com/tmc/langchaingo/llms"
 
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/events"
+	"github.com/giantswarm/oka/pkg/llm"
+	"github.com/giantswarm/oka/pkg/logger"
 	"github.com/giantswarm/oka/pkg/mcp/client"
+	"github.com/giantswarm/oka/pkg/mcp/runbook"
+	"github.com/giantswarm/oka/pkg/metrics"
 )
 
 // Session represents an AI assistant session for processing a single alert.
 type Session struct {
 	ID string
 
-	alert      any
-	llm        llms.Model
-	logFile    *os.File
-	maxCalls   int
-	mcpClients *client.Clients
-	messages   []llms.MessageContent
+	alert               any
+	alertInstructions   []string
+	alertURL            string
+	approver            Approver
+	callLimitMessage    string
+	contextWindowTokens int
+	endPhrase           string
+	events              *events.Broker
+	finalResponse       string
+	imageField          string
+	interaction         Interaction
+	llmCallTimeout      time.Duration
+	llmMaxRetries       int
+	llmRetryBackoff     time.Duration
+	logFile             *os.File
+	logger              *slog.Logger
+	maxCalls            int
+	mcpClients          *client.Clients
+	messages            []llms.MessageContent
+	modelIndex          int
+	models              []sessionModel
+	multimodal          bool
+	notes               []alert.AlertNote
+	result              Result
+	prices              map[string]config.TokenPrice
+	runbooks            []string
+	seed                *int
+	systemPrompt        string
+	timeout             time.Duration
+	tokens              tokenTotals
+	toolCache           *toolCallCache
+	toolCallTimeout     time.Duration
+	transcript          *Transcript
+	trim                trimFunc
 }
 
-// New creates a new session for processing an alert.
-func New(alert any, llm llms.Model, mcpClients *client.Clients, maxToolCalls int, logDir string) (*Session, error) {
+// sessionModel is an LLM model used by a session, along with its call options.
+type sessionModel struct {
+	llms.Model
+
+	callOptions []llms.CallOption
+	name        string
+}
+
+// New creates a new session for processing an alert with the given LLM models,
+// the first one being used until it fails and the others being its fallbacks
+// in order. The session's events are published to the given broker, which may
+// be nil.
+func New(alert any, llmModels []llm.Model, mcpClients *client.Clients, broker *events.Broker, conf *config.Config) (*Session, error) {
 	id := uuid.New().String()
 
-	logFile := fmt.Sprintf("%s/session-%s.log", logDir, id)
+	systemPrompt, err := renderSystemPrompt(conf, alert)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]sessionModel, 0, len(llmModels))
+	for _, llmModel := range llmModels {
+		callOptions, err := llm.CallOptions(llmModel.Config)
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, sessionModel{
+			Model:       llmModel.Model,
+			callOptions: callOptions,
+			name:        llmModel.Config.Model,
+		})
+	}
+
+	logFile, err := sessionLogPath(conf.SessionsLogDir, conf.Session.PathTemplate, id, alert, conf.OpsGenie.Team)
+	if err != nil {
+		return nil, err
+	}
+
 	f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open session log file: %w", err)
 	}
+	// The log is kept from pruning until the session ends.
+	setRunning(id, logFile, true)
+
+	// The transcript is only recorded if enabled.
+	var transcript *Transcript
+	if conf.SessionsJSON {
+		transcript = &Transcript{SessionID: id, AlertURL: alertURL(alert, conf)}
+	}
 
 	s := &Session{
-		ID:         id,
-		alert:      alert,
-		llm:        llm,
-		logFile:    f,
-		maxCalls:   maxToolCalls,
-		mcpClients: mcpClients,
-		messages:   make([]llms.MessageContent, 0),
+		ID:                  id,
+		alert:               alert,
+		alertInstructions:   alertInstructions(conf.Session.AlertInstructions, alert),
+		alertURL:            alertURL(alert, conf),
+		callLimitMessage:    conf.Session.CallLimitMessage,
+		contextWindowTokens: conf.Session.ContextWindowTokens,
+		endPhrase:           conf.Session.EndPhrase,
+		events:              broker,
+		imageField:          conf.Session.ImageField,
+		llmCallTimeout:      conf.Session.LLMCallTimeout,
+		llmMaxRetries:       conf.LLM.MaxRetries,
+		llmRetryBackoff:     conf.LLM.RetryBackoff,
+		logFile:             f,
+		logger:              sessionLogger(id, alert, conf),
+		maxCalls:            conf.MaxCalls,
+		mcpClients:          mcpClients,
+		messages:            make([]llms.MessageContent, 0),
+		models:              models,
+		multimodal:          conf.Session.Multimodal,
+		prices:              conf.LLM.Prices,
+		seed:                conf.LLM.Seed,
+		systemPrompt:        systemPrompt,
+		timeout:             conf.Session.Timeout,
+		toolCache:           newToolCallCache(),
+		toolCallTimeout:     conf.Session.ToolCallTimeout,
+		transcript:          transcript,
+		trim:                dropOldToolResponses,
 	}
 
+	// The cost is only estimated if the price of the models is configured.
+	s.tokens.Priced = s.price() != nil
+
 	return s, nil
 }
 
-// Run starts the session and processes the alert.
-func (s *Session) Run(ctx context.Context) {
-	var finalErr error
+// Run starts the session and processes the alert. It returns an error if the
+// session failed before completing the investigation.
+func (s *Session) Run(ctx context.Context) (finalErr error) {
+	// The tool calls of the session log with the session's attributes.
+	ctx = logger.NewContext(ctx, s.logger)
 
-	slog.Info("Starting session", "session.id", s.ID, "logFile", s.logFile.Name())
-	defer slog.Info("Stopping session", "session.id", s.ID)
+	s.logger.Info("Starting session", "logFile", s.logFile.Name())
+	s.publish(events.TypeSessionStarted, nil)
+	metrics.SessionsStarted.Inc()
+	defer func() {
+		if finalErr != nil {
+			metrics.SessionsFailed.Inc()
+		} else {
+			metrics.SessionsCompleted.WithLabelValues(string(s.result.Outcome)).Inc()
+		}
+
+		s.logger.Info("Stopping session", "outcome", s.result.Outcome, "reason", s.result.Reason, "runbooks", s.runbooks, "tokens.prompt", s.tokens.Prompt, "tokens.completion", s.tokens.Completion)
+		data := map[string]any{"outcome": s.result.Outcome, "reason": s.result.Reason, "usage": s.usageData()}
+		if finalErr != nil {
+			data["error"] = finalErr.Error()
+		}
+		s.publish(events.TypeSessionCompleted, data)
+	}()
+	defer setRunning(s.ID, s.logFile.Name(), false)
 	defer s.logFile.Close()
 	defer func() {
 		if finalErr != nil {
 			s.log("\n## Error\n%s\n", finalErr.Error())
+			s.transcript.record(TranscriptEvent{Type: transcriptError, Error: finalErr.Error()})
+			s.result = errorResult(finalErr)
+		} else if s.result.Outcome == "" {
+			s.result = Result{Outcome: OutcomeError, Reason: "the session was canceled"}
 		}
+		s.logOutcome()
+		s.logRunbooks()
+		s.logUsage()
 		s.log("\n# Session end")
+
+		s.transcript.record(TranscriptEvent{Type: transcriptEnd, Outcome: string(s.result.Outcome), Reason: s.result.Reason, Usage: s.usageData()})
+		err := s.transcript.write(transcriptPath(s.logFile.Name()))
+		if err != nil {
+			s.logger.Warn("Failed to write session transcript", "error", err)
+		}
 	}()
 
+	// Bound the whole session, the LLM and tool calls are made within the
+	// session context. Exhausting the time budget ends the session without
+	// error, like hitting the call limit.
+	if s.timeout > 0 {
+		parentCtx := ctx
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+		defer func() {
+			if s.result.Outcome == "" && errors.Is(ctx.Err(), context.DeadlineExceeded) && parentCtx.Err() == nil {
+				s.logger.Warn("Session exhausted its time budget", "timeout", s.timeout)
+				s.result = Result{Outcome: OutcomeTimeout, Reason: fmt.Sprintf("the session exhausted its time budget of %s", s.timeout)}
+				finalErr = nil
+			}
+		}()
+	}
+
 	// Add the alert to the session context.
 	alertBytes, err := json.Marshal(s.alert)
 	if err != nil {
-		slog.Error("Failed to marshal alert to json", "error", err, "session.id", s.ID)
-		finalErr = fmt.Errorf("failed to marshal alert: %w", err)
-		return
+		s.logger.Error("Failed to marshal alert to json", "error", err)
+		return fmt.Errorf("failed to marshal alert: %w", err)
 	}
 	s.addToContext(llms.ChatMessageTypeGeneric, llms.TextPart(string(alertBytes)))
+	if s.alertURL != "" {
+		s.addToContext(llms.ChatMessageTypeGeneric, llms.TextPart("Link to the alert in OpsGenie: "+s.alertURL))
+	}
+
+	// Add the notes left by responders, so that their work is not repeated.
+	notes := formatNotes(s.notes)
+	if notes != "" {
+		s.addToContext(llms.ChatMessageTypeGeneric, llms.TextPart("Notes left on the alert before the investigation:\n"+notes))
+	}
 
 	// Add system prompt instructions.
-	s.addToContext(llms.ChatMessageTypeSystem, llms.TextPart(systemPrompt))
+	s.addToContext(llms.ChatMessageTypeSystem, llms.TextPart(s.systemPrompt))
+
+	// Add the instructions configured for the alert.
+	var instructions string
+	if len(s.alertInstructions) > 0 {
+		instructions = formatAlertInstructions(s.alertInstructions)
+		s.addToContext(llms.ChatMessageTypeSystem, llms.TextPart(instructions))
+	}
 
 	s.log("# Session initialized: %s\n", s.ID)
 	s.log("\n## Alert\n%s\n", string(alertBytes))
-	s.log("\n## Prompt\n%s\n", systemPrompt)
+	if s.alertURL != "" {
+		s.log("\n## Alert link\n%s\n", s.alertURL)
+	}
+	if notes != "" {
+		s.log("\n## Notes\n%s", notes)
+	}
+	s.log("\n## Prompt\n%s\n", s.systemPrompt)
+	if instructions != "" {
+		s.log("\n## Alert instructions\n%s", instructions)
+	}
+	if s.seed != nil {
+		s.log("\n## Seed\n%d\n", *s.seed)
+	}
+	if s.multimodal {
+		s.addImages(ctx)
+	}
 	s.log("\n## Tools\n")
 	for _, tool := range s.mcpClients.GetTools() {
 		s.log("- %s: %s\n", tool.Function.Name, tool.Function.Description)
@@ -87,57 +267,120 @@ func (s *Session) Run(ctx context.Context) {
 	for i := 0; i < s.maxCalls; i++ {
 		select {
 		case <-ctx.Done():
-			return
+			return nil
 		default:
 			// Continue if context is not done
 		}
 
 		lastCall := i == (s.maxCalls - 1)
 		if lastCall {
-			s.addToContext(llms.ChatMessageTypeSystem, llms.TextPart("You must now complete your investigation and provide a final response."))
+			s.addToContext(llms.ChatMessageTypeSystem, llms.TextPart(s.callLimitMessage))
 		}
 
-		slog.Info("Calling LLM", "session.id", s.ID)
+		err = s.addInstructions(ctx)
+		if err != nil {
+			return err
+		}
+
+		s.trimContext()
+
+		s.logger.Info("Calling LLM")
+		s.publish(events.TypeTurnStarted, map[string]any{"turn": i + 1})
+		s.transcript.record(TranscriptEvent{Type: transcriptLLMRequest})
+		llmStart := time.Now()
 		llmResponse, err := s.callLLM(ctx, lastCall)
 		if err != nil {
-			slog.Error("Failed to call LLM", "error", err, "session.id", s.ID)
-			finalErr = fmt.Errorf("failed to call LLM: %w", err)
-			return
+			s.logger.Error("Failed to call LLM", "error", err)
+			return fmt.Errorf("failed to call LLM: %w", err)
 		}
 		s.addToContext(llms.ChatMessageTypeAI, llms.TextPart(llmResponse.Content))
 		s.log("\n## LLM response\n%s\n", llmResponse.Content)
-
-		if len(llmResponse.ToolCalls) == 0 {
-			slog.Info("LLM did not suggest any tool calls", "session.id", s.ID)
-			return
+		s.publish(events.TypeLLMContent, map[string]any{"content": llmResponse.Content})
+		s.tokens.add(llmResponse.GenerationInfo, s.price())
+		s.transcript.record(TranscriptEvent{
+			Type:       transcriptLLMResponse,
+			Model:      s.models[s.modelIndex].name,
+			Content:    llmResponse.Content,
+			DurationMs: time.Since(llmStart).Milliseconds(),
+			Usage:      tokenUsage(llmResponse.GenerationInfo),
+		})
+		s.observeLLMCall(llmResponse.GenerationInfo, time.Since(llmStart))
+		if s.interaction != nil {
+			s.interaction.Response(llmResponse.Content)
 		}
 
-		// Create a context with timeout for tool processing.
-		toolCtx, cancel := context.WithTimeout(ctx, 3*time.Minute)
-		defer cancel()
+		if len(llmResponse.ToolCalls) == 0 || isInvestigationComplete(llmResponse.Content, s.endPhrase) {
+			s.logger.Info("LLM completed the investigation")
+			s.finalResponse = finalAnswer(llmResponse.Content, s.endPhrase)
+			s.log("\n## Final answer\n%s\n", s.finalResponse)
+			s.result = completionResult(llmResponse.Content)
+			return nil
+		}
 
 		for _, toolCall := range llmResponse.ToolCalls {
 			s.addToContext(llms.ChatMessageTypeAI, toolCall)
 
-			slog.Info("Tool call", "session.id", s.ID, "tool", toolCall.FunctionCall.Name)
+			s.logger.Info("Tool call", "tool", toolCall.FunctionCall.Name)
 			s.log("\n## Tool call\ntool: %s\nargs: %s\n", toolCall.FunctionCall.Name, toolCall.FunctionCall.Arguments)
+			s.publish(events.TypeToolCall, map[string]any{"tool": toolCall.FunctionCall.Name, "arguments": toolCall.FunctionCall.Arguments})
+			s.transcript.record(TranscriptEvent{Type: transcriptToolCall, Tool: toolCall.FunctionCall.Name, Arguments: toolCall.FunctionCall.Arguments})
 
 			args := make(map[string]interface{})
 			err = json.Unmarshal([]byte(toolCall.FunctionCall.Arguments), &args)
 			if err != nil {
-				slog.Error("Failed to unmarshal tool call arguments", "error", err, "session.id", s.ID, "arguments", toolCall.FunctionCall.Arguments)
-				finalErr = fmt.Errorf("failed to unmarshal tool call arguments: %w", err)
-				return
+				s.logger.Error("Failed to unmarshal tool call arguments", "error", err, "arguments", toolCall.FunctionCall.Arguments)
+				return fmt.Errorf("failed to unmarshal tool call arguments: %w", err)
 			}
 
-			toolResponse, err := s.mcpClients.CallTool(toolCtx, toolCall.FunctionCall.Name, args)
-			if err != nil {
-				slog.Error("Failed to process tool response", "error", err, "session.id", s.ID, "toolCall", toolCall.FunctionCall.Name)
-				toolResponse = fmt.Sprintf("Error: %s", err.Error())
+			s.recordRunbook(toolCall.FunctionCall.Name, args)
+
+			// Identical calls repeated in a row by the LLM are served from
+			// the cache, with a note so that it stops looping.
+			toolKey := toolCallKey(toolCall.FunctionCall.Name, args)
+			cachedResponse, cached := s.toolCache.get(toolKey)
+
+			approved := cached
+			if !cached {
+				s.toolCache.reset()
+				approved, err = s.approve(ctx, toolCall.FunctionCall.Name, toolCall.FunctionCall.Arguments)
+				if err != nil {
+					return err
+				}
 			}
 
-			slog.Info("Tool response", "session.id", s.ID, "tool", toolCall.FunctionCall.Name, "response", len(toolResponse))
+			toolStart := time.Now()
+			toolResponse, toolStatus := deniedToolResponse, "denied"
+			var toolImages []llms.BinaryContent
+			switch {
+			case cached:
+				s.logger.Info("Repeated tool call served from cache", "tool", toolCall.FunctionCall.Name)
+				toolResponse, toolStatus = repeatedToolCallNote+cachedResponse, "cached"
+			case approved:
+				var result client.ToolResult
+				result, err = s.callTool(ctx, toolCall.FunctionCall.Name, args)
+				toolResponse, toolImages, toolStatus = result.Text, result.Images, "success"
+				if err != nil {
+					s.logger.Error("Failed to process tool response", "error", err, "toolCall", toolCall.FunctionCall.Name)
+					toolResponse = fmt.Sprintf("Error: %s", err.Error())
+					toolStatus = "error"
+				} else {
+					s.toolCache.set(toolKey, toolResponse)
+				}
+			}
+			metrics.ToolCalls.WithLabelValues(toolCall.FunctionCall.Name, toolStatus).Inc()
+			if s.interaction != nil {
+				s.interaction.ToolResponse(toolCall.FunctionCall.Name, toolResponse)
+			}
+
+			s.logger.Info("Tool response", "tool", toolCall.FunctionCall.Name, "response", len(toolResponse))
 			s.log("\n## Tool response\ntool: %s\n%s\n", toolCall.FunctionCall.Name, toolResponse)
+			s.publish(events.TypeToolResult, map[string]any{"tool": toolCall.FunctionCall.Name, "result": toolResponse})
+			s.transcript.record(TranscriptEvent{
+				Type:       transcriptToolResponse,
+				Tool:       toolCall.FunctionCall.Name,
+				Content:    toolResponse,
+				DurationMs: time.Since(toolStart).Milliseconds(),
+			})
 
 			// Add history.
 			toolResponsePart := llms.ToolCallResponse{
@@ -146,8 +389,31 @@ func (s *Session) Run(ctx context.Context) {
 				Content:    toolResponse,
 			}
 			s.addToContext(llms.ChatMessageTypeTool, toolResponsePart)
+			s.addToolImages(toolCall.FunctionCall.Name, toolImages)
 		}
 	}
+
+	// The LLM still requested tool calls when the limit was reached, the
+	// investigation is likely incomplete.
+	s.logger.Warn("Session hit the call limit", "max_calls", s.maxCalls)
+	s.result = Result{Outcome: OutcomeCallLimit, Reason: fmt.Sprintf("the session hit the limit of %d LLM calls", s.maxCalls)}
+
+	return nil
+}
+
+// callTool calls a tool within the tool's timeout, falling back to the
+// session default if the tool has no specific timeout.
+func (s Session) callTool(ctx context.Context, name string, args map[string]any) (client.ToolResult, error) {
+	timeout := s.mcpClients.GetToolTimeout(name)
+	if timeout == 0 {
+		timeout = s.toolCallTimeout
+	}
+
+	// Create a context with timeout for tool processing.
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return s.mcpClients.CallTool(ctx, name, args)
 }
 
 // addToContext adds a message to the session's context.
@@ -159,12 +425,64 @@ func (s *Session) addToContext(role llms.ChatMessageType, parts ...llms.ContentP
 	s.messages = append(s.messages, message)
 }
 
-// callLLM generates a text completion using the specified provider from the registry.
-func (s Session) callLLM(ctx context.Context, lastCall bool) (*llms.ContentChoice, error) {
-	// Create a context with appropriate timeout.
-	ctx, cancel := context.WithTimeout(ctx, 3*time.Minute)
-	defer cancel()
+// recordRunbook records the runbook URL if the tool call retrieves a runbook.
+func (s *Session) recordRunbook(toolName string, args map[string]any) {
+	if s.mcpClients.GetOriginalToolName(toolName) != runbook.GetRunbookToolName {
+		return
+	}
+
+	url, ok := args["url"].(string)
+	if !ok || url == "" || slices.Contains(s.runbooks, url) {
+		return
+	}
 
+	s.runbooks = append(s.runbooks, url)
+}
+
+// isInvestigationComplete returns whether the LLM response contains the phrase
+// ending the investigation, matched case-insensitively. The LLM is asked to
+// end its final summary with it, but it may still suggest tool calls.
+func isInvestigationComplete(content, endPhrase string) bool {
+	if endPhrase == "" {
+		return false
+	}
+
+	return strings.Contains(strings.ToLower(content), strings.ToLower(endPhrase))
+}
+
+// logOutcome writes the outcome of the session to the log file.
+func (s Session) logOutcome() {
+	switch s.result.Outcome {
+	case OutcomeCompleted:
+		s.log("\n## Outcome\nThe investigation completed.\n")
+	case OutcomeEscalated:
+		s.log("\n## Outcome\nThe investigation completed, the LLM requested an escalation to a human.\n")
+	case OutcomeError:
+		s.log("\n## Outcome\nThe investigation failed: %s.\n", s.result.Reason)
+	case OutcomeCallLimit:
+		s.log("\n## Outcome\nThe investigation hit the limit of %d calls and was truncated, it may be incomplete.\n", s.maxCalls)
+	case OutcomeTimeout:
+		s.log("\n## Outcome\nThe investigation exhausted its time budget of %s and was stopped, it may be incomplete.\n", s.timeout)
+	}
+}
+
+// logRunbooks writes the runbooks used during the session to the log file.
+func (s Session) logRunbooks() {
+	s.log("\n## Runbooks\n")
+	if len(s.runbooks) == 0 {
+		s.log("No runbook used\n")
+		return
+	}
+
+	for _, url := range s.runbooks {
+		s.log("- %s\n", url)
+	}
+}
+
+// callLLM generates a text completion using the current LLM model. If the
+// model keeps failing on transient errors after its retries, the session falls
+// back to the next model, keeping its message history.
+func (s *Session) callLLM(ctx context.Context, lastCall bool) (*llms.ContentChoice, error) {
 	options := []llms.CallOption{
 		llms.WithTools(s.mcpClients.GetTools()),
 		// Limit generated responses to 1 to save tokens.
@@ -172,14 +490,45 @@ func (s Session) callLLM(ctx context.Context, lastCall bool) (*llms.ContentChoic
 		llms.WithCandidateCount(1),
 	}
 
-	resp, err := s.llm.GenerateContent(ctx, s.messages, options...)
+	resp, err := s.generateContent(ctx, s.models[s.modelIndex], options)
+	for err != nil && isTransientLLMError(ctx, err) && s.modelIndex < len(s.models)-1 {
+		s.modelIndex++
+		model := s.models[s.modelIndex]
+		s.logger.Warn("Falling back to the next LLM model", "model", model.name, "error", err)
+		s.log("\n## LLM fallback\nmodel: %s\nerror: %s\n", model.name, err)
+
+		resp, err = s.generateContent(ctx, model, options)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if resp == nil || len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("LLM %s returned no choice", s.models[s.modelIndex].name)
+	}
 
 	return resp.Choices[0], nil
 }
 
+// observeLLMCall records the duration and token usage of an LLM call of the
+// current model in the metrics.
+func (s Session) observeLLMCall(generationInfo map[string]any, duration time.Duration) {
+	model := s.models[s.modelIndex].name
+	prompt, completion := usageTokens(generationInfo)
+
+	metrics.LLMCallDuration.WithLabelValues(model).Observe(duration.Seconds())
+	metrics.LLMTokens.WithLabelValues(model, "prompt").Add(float64(prompt))
+	metrics.LLMTokens.WithLabelValues(model, "completion").Add(float64(completion))
+}
+
+// publish publishes a session event to the session's events broker.
+func (s Session) publish(eventType events.Type, data map[string]any) {
+	s.events.Publish(events.Event{
+		SessionID: s.ID,
+		Type:      eventType,
+		Data:      data,
+	})
+}
+
 // log writes a message to the session's log file.
 func (s Session) log(format string, args ...any) {
 	fmt.Fprintf(s.logFile, format, args...)
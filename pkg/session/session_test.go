@@ -0,0 +1,151 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/llm"
+	"github.com/giantswarm/oka/pkg/mcp/client"
+)
+
+// testEndPhrase is the phrase ending the investigations of the test sessions.
+const testEndPhrase = "investigation complete"
+
+// fakeModel is an LLM returning the scripted responses in order, then a final
+// answer, and recording the messages of every call.
+type fakeModel struct {
+	mu        sync.Mutex
+	responses []*llms.ContentChoice
+	calls     [][]llms.MessageContent
+}
+
+// GenerateContent returns the next scripted response.
+func (m *fakeModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, slices.Clone(messages))
+
+	choice := &llms.ContentChoice{Content: "The alert is resolved. " + testEndPhrase}
+	if len(m.responses) > 0 {
+		choice = m.responses[0]
+		m.responses = m.responses[1:]
+	}
+
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{choice}}, nil
+}
+
+// Call generates a completion of the prompt.
+func (m *fakeModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+// toolCallChoice returns an LLM response calling the tool with the arguments.
+func toolCallChoice(id, tool, arguments string) *llms.ContentChoice {
+	return &llms.ContentChoice{
+		ToolCalls: []llms.ToolCall{
+			{
+				ID:   id,
+				Type: "function",
+				FunctionCall: &llms.FunctionCall{
+					Name:      tool,
+					Arguments: arguments,
+				},
+			},
+		},
+	}
+}
+
+// testConfig returns the configuration of the test sessions, logging to a
+// temporary directory.
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	return &config.Config{
+		MaxCalls:       10,
+		SessionsLogDir: t.TempDir(),
+		OpsGenie:       &config.OpsGenie{},
+		Session: config.Session{
+			EndPhrase:       testEndPhrase,
+			LLMCallTimeout:  time.Minute,
+			PathTemplate:    "session-{{ .SessionID }}.log",
+			ToolCallTimeout: time.Minute,
+		},
+	}
+}
+
+// echoTool is the name of the echo tool of the test MCP server, as exposed to
+// the LLM.
+const echoTool = "mcp_test_echo"
+
+// echoServer is an in-process MCP server with an echo tool, counting its
+// calls.
+type echoServer struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+// newTestClients returns the MCP clients of an echo server.
+func newTestClients(t *testing.T, echo *echoServer) *client.Clients {
+	t.Helper()
+
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(true))
+	mcpServer.AddTool(
+		mcp.NewTool("echo", mcp.WithString("text", mcp.Required())),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			text := request.GetString("text", "")
+
+			echo.mu.Lock()
+			echo.calls = append(echo.calls, text)
+			echo.mu.Unlock()
+
+			return mcp.NewToolResultText(fmt.Sprintf("echo: %s", text)), nil
+		},
+	)
+
+	clients := client.New()
+	err := clients.RegisterServer(context.Background(), mcpServer, "test")
+	if err != nil {
+		t.Fatalf("failed to register test MCP server: %v", err)
+	}
+	t.Cleanup(func() { _ = clients.Close() })
+
+	return clients
+}
+
+// newTestSession creates a session investigating the alert with the model and
+// the MCP clients.
+func newTestSession(t *testing.T, alert any, model *fakeModel, clients *client.Clients, conf *config.Config) *Session {
+	t.Helper()
+
+	models := []llm.Model{{Model: model, Config: config.LLM{Model: "fake"}}}
+	s, err := New(alert, models, clients, nil, conf)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	return s
+}
+
+// toolResponses returns the tool responses of the session context, in order.
+func toolResponses(s *Session) []string {
+	var responses []string
+	for _, message := range s.messages {
+		for _, part := range message.Parts {
+			if response, ok := part.(llms.ToolCallResponse); ok {
+				responses = append(responses, response.Content)
+			}
+		}
+	}
+
+	return responses
+}
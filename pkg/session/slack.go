@@ -0,0 +1,37 @@
+package session
+
+import (
+	"context"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/incident"
+
+	"github.com/giantswarm/oka/pkg/config"
+	"github.com/giantswarm/oka/pkg/slack"
+)
+
+// notifySlack posts the final response of the session to Slack, along with
+// the title and link of the investigated alert. The slack.alert_url prefix
+// takes precedence over the link of the session. The summaries of incident
+// sessions are titled with the incident message and have no link. The
+// sessions of other payloads are not posted.
+func notifySlack(ctx context.Context, s *Session, conf *config.Config) error {
+	summary := slack.Summary{
+		Outcome:   string(s.result.Outcome),
+		Response:  s.finalResponse,
+		SessionID: s.ID,
+	}
+
+	if a, ok := opsgenieAlert(s.alert); ok {
+		summary.AlertTitle = a.Message
+		summary.AlertLink = s.alertURL
+		if conf.Slack.AlertURL != "" {
+			summary.AlertLink = conf.Slack.AlertURL + a.Id
+		}
+	} else if i, ok := s.alert.(*incident.Incident); ok && i != nil {
+		summary.AlertTitle = i.Message
+	} else {
+		return nil
+	}
+
+	return slack.NewNotifier(conf.Slack.WebhookURL).PostSummary(ctx, summary)
+}
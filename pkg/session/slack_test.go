@@ -0,0 +1,68 @@
+package session
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opsgenie/opsgenie-go-sdk-v2/alert"
+	"github.com/opsgenie/opsgenie-go-sdk-v2/incident"
+)
+
+func TestNotifySlack(t *testing.T) {
+	testCases := []struct {
+		name          string
+		payload       any
+		expectedTitle string
+		expectPosted  bool
+	}{
+		{
+			name:          "alert",
+			payload:       &alert.GetAlertResult{Id: "alert-1", Message: "Pod is crash looping"},
+			expectedTitle: "Pod is crash looping",
+			expectPosted:  true,
+		},
+		{
+			name:          "incident",
+			payload:       &incident.Incident{Id: "incident-1", Message: "Cluster is down"},
+			expectedTitle: "Cluster is down",
+			expectPosted:  true,
+		},
+		{
+			name:    "not an alert",
+			payload: map[string]any{"message": "test"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var posted []byte
+			webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				posted, _ = io.ReadAll(r.Body)
+			}))
+			t.Cleanup(webhook.Close)
+
+			conf := testConfig(t)
+			conf.Slack.WebhookURL = webhook.URL
+
+			s := &Session{ID: "session-1", alert: tc.payload, finalResponse: "Investigated"}
+			err := notifySlack(context.Background(), s, conf)
+			if err != nil {
+				t.Fatalf("failed to notify Slack: %v", err)
+			}
+
+			if !tc.expectPosted {
+				if posted != nil {
+					t.Errorf("expected no summary to be posted, got %s", posted)
+				}
+				return
+			}
+			if !strings.Contains(string(posted), tc.expectedTitle) {
+				t.Errorf("expected the summary to be titled %q, got %s", tc.expectedTitle, posted)
+			}
+		})
+	}
+}
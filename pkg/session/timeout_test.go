@@ -0,0 +1,128 @@
+package session
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/giantswarm/oka/pkg/mcp/client"
+)
+
+// slowTool is the name of the slow tool of the test MCP server, as exposed to
+// the LLM.
+const slowTool = "mcp_slow_wait"
+
+// newSlowClients returns the MCP clients of a server with a tool which takes
+// the given time to respond, unless its call is canceled.
+func newSlowClients(t *testing.T, delay time.Duration) *client.Clients {
+	t.Helper()
+
+	mcpServer := server.NewMCPServer("slow", "1.0.0", server.WithToolCapabilities(true))
+	mcpServer.AddTool(mcp.NewTool("wait"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	clients := client.New()
+	err := clients.RegisterServer(context.Background(), mcpServer, "slow")
+	if err != nil {
+		t.Fatalf("failed to register slow MCP server: %v", err)
+	}
+	t.Cleanup(func() { _ = clients.Close() })
+
+	return clients
+}
+
+func TestToolCallTimeout(t *testing.T) {
+	conf := testConfig(t)
+	conf.Session.ToolCallTimeout = 50 * time.Millisecond
+
+	model := &fakeModel{
+		responses: []*llms.ContentChoice{
+			toolCallChoice("call-1", slowTool, `{}`),
+		},
+	}
+	s := newTestSession(t, map[string]any{"message": "test"}, model, newSlowClients(t, time.Minute), conf)
+
+	start := time.Now()
+	err := s.Run(context.Background())
+	if err != nil {
+		t.Fatalf("failed to run session: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected the tool call to time out, the session took %s", elapsed)
+	}
+
+	responses := toolResponses(s)
+	if len(responses) != 1 {
+		t.Fatalf("expected 1 tool response, got %d", len(responses))
+	}
+	if !strings.HasPrefix(responses[0], "Error:") || !strings.Contains(responses[0], context.DeadlineExceeded.Error()) {
+		t.Errorf("expected a timeout error as tool response, got %q", responses[0])
+	}
+}
+
+func TestSessionTimeout(t *testing.T) {
+	conf := testConfig(t)
+	conf.Session.Timeout = 100 * time.Millisecond
+
+	model := &fakeModel{
+		responses: []*llms.ContentChoice{
+			toolCallChoice("call-1", slowTool, `{}`),
+		},
+	}
+	s := newTestSession(t, map[string]any{"message": "test"}, model, newSlowClients(t, time.Minute), conf)
+
+	start := time.Now()
+	err := s.Run(context.Background())
+	if err != nil {
+		t.Fatalf("expected the session to end without error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected the in-flight tool call to be canceled, the session took %s", elapsed)
+	}
+
+	if s.result.Outcome != OutcomeTimeout {
+		t.Errorf("expected outcome %s, got %s", OutcomeTimeout, s.result.Outcome)
+	}
+
+	log, err := os.ReadFile(s.logFile.Name())
+	if err != nil {
+		t.Fatalf("failed to read session log: %v", err)
+	}
+	if !strings.Contains(string(log), "exhausted its time budget of 100ms") {
+		t.Errorf("expected the session log to record the exhausted time budget, got %q", log)
+	}
+}
+
+func TestSessionTimeoutParentCanceled(t *testing.T) {
+	conf := testConfig(t)
+	conf.Session.Timeout = time.Minute
+
+	model := &fakeModel{
+		responses: []*llms.ContentChoice{
+			toolCallChoice("call-1", slowTool, `{}`),
+		},
+	}
+	s := newTestSession(t, map[string]any{"message": "test"}, model, newSlowClients(t, time.Minute), conf)
+
+	// Canceling the session is not reported as a timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_ = s.Run(ctx)
+
+	if s.result.Outcome == OutcomeTimeout {
+		t.Errorf("expected the canceled session not to time out, got %s", s.result.Outcome)
+	}
+}
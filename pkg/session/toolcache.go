@@ -0,0 +1,57 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// repeatedToolCallNote is prepended to the cached result of a repeated tool
+// call, so that the LLM stops looping over it.
+const repeatedToolCallNote = "Note: you just called this tool with the same arguments, this is the same result. Do not call it again with these arguments, use the result or try something else.\n\n"
+
+// toolCallCache caches the result of the last successful tool call of a
+// session, so that an identical call repeated right after by the LLM is not
+// executed again. Only consecutive calls are deduplicated, as the result of a
+// call may change once other tools, e.g. mutating ones, were called. Sessions
+// run a single goroutine, the cache is not safe for concurrent use.
+type toolCallCache struct {
+	key    string
+	result string
+}
+
+// newToolCallCache creates a new tool call cache.
+func newToolCallCache() *toolCallCache {
+	return &toolCallCache{}
+}
+
+// toolCallKey returns the cache key of a tool call. The arguments are
+// normalized by marshaling them, which sorts the object keys.
+func toolCallKey(tool string, args map[string]any) string {
+	normalized, err := json.Marshal(args)
+	if err != nil {
+		normalized = fmt.Appendf(nil, "%v", args)
+	}
+
+	return tool + "\x00" + string(normalized)
+}
+
+// get returns the cached result of a tool call, if it is the last call.
+func (c *toolCallCache) get(key string) (string, bool) {
+	if c.key != key {
+		return "", false
+	}
+
+	return c.result, true
+}
+
+// set caches the result of the last tool call.
+func (c *toolCallCache) set(key, result string) {
+	c.key = key
+	c.result = result
+}
+
+// reset forgets the last tool call, e.g. when it failed or was denied.
+func (c *toolCallCache) reset() {
+	c.key = ""
+	c.result = ""
+}
@@ -0,0 +1,74 @@
+package session
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestRepeatedToolCallServedFromCache(t *testing.T) {
+	echo := &echoServer{}
+	model := &fakeModel{
+		responses: []*llms.ContentChoice{
+			toolCallChoice("call-1", echoTool, `{"text": "a"}`),
+			toolCallChoice("call-2", echoTool, `{"text":"a"}`),
+			toolCallChoice("call-3", echoTool, `{"text": "b"}`),
+			toolCallChoice("call-4", echoTool, `{"text": "a"}`),
+		},
+	}
+	s := newTestSession(t, map[string]any{"message": "test"}, model, newTestClients(t, echo), testConfig(t))
+
+	err := s.Run(context.Background())
+	if err != nil {
+		t.Fatalf("failed to run session: %v", err)
+	}
+
+	// The second call repeats the first one, the fourth one does not repeat
+	// the call right before it.
+	if !slices.Equal(echo.calls, []string{"a", "b", "a"}) {
+		t.Errorf("expected the tool to be called with a, b and a, got %q", echo.calls)
+	}
+
+	responses := toolResponses(s)
+	if len(responses) != 4 {
+		t.Fatalf("expected 4 tool responses, got %d", len(responses))
+	}
+
+	if responses[1] != repeatedToolCallNote+responses[0] {
+		t.Errorf("expected the repeated call to return the cached result with a note, got %q", responses[1])
+	}
+
+	for _, i := range []int{0, 2, 3} {
+		if strings.HasPrefix(responses[i], repeatedToolCallNote) {
+			t.Errorf("expected tool response %d not to be served from the cache, got %q", i+1, responses[i])
+		}
+	}
+}
+
+func TestToolCallCache(t *testing.T) {
+	c := newToolCallCache()
+	keyA := toolCallKey("tool", map[string]any{"a": 1, "b": 2})
+	keyB := toolCallKey("tool", map[string]any{"a": 2})
+
+	if _, ok := c.get(keyA); ok {
+		t.Fatal("expected an empty cache")
+	}
+
+	c.set(keyA, "result")
+	if result, ok := c.get(toolCallKey("tool", map[string]any{"b": 2, "a": 1})); !ok || result != "result" {
+		t.Errorf("expected the call with reordered arguments to be cached, got %q, %t", result, ok)
+	}
+
+	c.set(keyB, "other")
+	if _, ok := c.get(keyA); ok {
+		t.Error("expected only the last call to be cached")
+	}
+
+	c.reset()
+	if _, ok := c.get(keyB); ok {
+		t.Error("expected no call cached after a reset")
+	}
+}
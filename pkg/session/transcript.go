@@ -0,0 +1,97 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Transcript event types.
+const (
+	transcriptLLMRequest   = "llm_request"
+	transcriptLLMResponse  = "llm_response"
+	transcriptToolCall     = "tool_call"
+	transcriptToolResponse = "tool_response"
+	transcriptError        = "error"
+	transcriptEnd          = "end"
+)
+
+// Transcript is the structured record of a session, written as JSON alongside
+// the session log for tooling analyzing the sessions.
+type Transcript struct {
+	SessionID string            `json:"session_id"`
+	AlertURL  string            `json:"alert_url,omitempty"`
+	Events    []TranscriptEvent `json:"events"`
+}
+
+// TranscriptEvent is an event of a session transcript.
+type TranscriptEvent struct {
+	Type       string         `json:"type"`
+	Time       time.Time      `json:"time"`
+	Model      string         `json:"model,omitempty"`
+	Content    string         `json:"content,omitempty"`
+	Tool       string         `json:"tool,omitempty"`
+	Arguments  string         `json:"arguments,omitempty"`
+	DurationMs int64          `json:"duration_ms,omitempty"`
+	Usage      map[string]any `json:"usage,omitempty"`
+	Outcome    string         `json:"outcome,omitempty"`
+	Reason     string         `json:"reason,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// record adds an event to the transcript. Recording to a nil transcript is a
+// no-op so that sessions do not need to check whether it is enabled.
+func (t *Transcript) record(e TranscriptEvent) {
+	if t == nil {
+		return
+	}
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	t.Events = append(t.Events, e)
+}
+
+// write writes the transcript as JSON to the given file.
+func (t *Transcript) write(path string) error {
+	if t == nil {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session transcript: %w", err)
+	}
+
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write session transcript: %w", err)
+	}
+
+	return nil
+}
+
+// tokenUsage extracts the token usage from the generation info of an LLM
+// response, whose keys depend on the provider (e.g. "PromptTokens" or
+// "InputTokens").
+func tokenUsage(generationInfo map[string]any) map[string]any {
+	usage := make(map[string]any)
+	for key, value := range generationInfo {
+		if strings.HasSuffix(key, "Tokens") {
+			usage[key] = value
+		}
+	}
+
+	if len(usage) == 0 {
+		return nil
+	}
+
+	return usage
+}
+
+// transcriptPath returns the path of the transcript of a session log file.
+func transcriptPath(logFile string) string {
+	return strings.TrimSuffix(logFile, ".log") + ".json"
+}
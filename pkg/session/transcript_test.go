@@ -0,0 +1,98 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestSessionTranscript(t *testing.T) {
+	conf := testConfig(t)
+	conf.SessionsJSON = true
+
+	model := &fakeModel{
+		responses: []*llms.ContentChoice{
+			toolCallChoice("call-1", echoTool, `{"text": "a"}`),
+		},
+	}
+	s := newTestSession(t, map[string]any{"message": "test"}, model, newTestClients(t, &echoServer{}), conf)
+
+	err := s.Run(context.Background())
+	if err != nil {
+		t.Fatalf("failed to run session: %v", err)
+	}
+
+	data, err := os.ReadFile(transcriptPath(s.logFile.Name()))
+	if err != nil {
+		t.Fatalf("failed to read session transcript: %v", err)
+	}
+
+	var transcript Transcript
+	err = json.Unmarshal(data, &transcript)
+	if err != nil {
+		t.Fatalf("failed to parse session transcript: %v", err)
+	}
+
+	if transcript.SessionID != s.ID {
+		t.Errorf("expected session ID %s, got %s", s.ID, transcript.SessionID)
+	}
+
+	var types []string
+	for _, event := range transcript.Events {
+		types = append(types, event.Type)
+		if event.Time.IsZero() {
+			t.Errorf("expected the %s event to have a time", event.Type)
+		}
+	}
+	expected := []string{
+		transcriptLLMRequest,
+		transcriptLLMResponse,
+		transcriptToolCall,
+		transcriptToolResponse,
+		transcriptLLMRequest,
+		transcriptLLMResponse,
+		transcriptEnd,
+	}
+	if !slices.Equal(types, expected) {
+		t.Fatalf("expected events %q, got %q", expected, types)
+	}
+
+	toolCall := transcript.Events[2]
+	if toolCall.Tool != echoTool || toolCall.Arguments != `{"text": "a"}` {
+		t.Errorf("expected the call of the echo tool, got %+v", toolCall)
+	}
+	if response := transcript.Events[3]; response.Content != "echo: a" {
+		t.Errorf("expected the echo tool response, got %q", response.Content)
+	}
+	if end := transcript.Events[6]; end.Outcome != string(s.Result().Outcome) {
+		t.Errorf("expected outcome %s, got %s", s.Result().Outcome, end.Outcome)
+	}
+}
+
+func TestSessionTranscriptDisabled(t *testing.T) {
+	s := newTestSession(t, map[string]any{"message": "test"}, &fakeModel{}, newTestClients(t, &echoServer{}), testConfig(t))
+
+	err := s.Run(context.Background())
+	if err != nil {
+		t.Fatalf("failed to run session: %v", err)
+	}
+
+	if _, err := os.Stat(transcriptPath(s.logFile.Name())); !os.IsNotExist(err) {
+		t.Errorf("expected no session transcript, got %v", err)
+	}
+}
+
+func TestTokenUsage(t *testing.T) {
+	usage := tokenUsage(map[string]any{"PromptTokens": 10, "CompletionTokens": 5, "StopReason": "end"})
+	if len(usage) != 2 || usage["PromptTokens"] != 10 || usage["CompletionTokens"] != 5 {
+		t.Errorf("expected the token counts, got %v", usage)
+	}
+
+	if usage := tokenUsage(map[string]any{"StopReason": "end"}); usage != nil {
+		t.Errorf("expected no usage, got %v", usage)
+	}
+}
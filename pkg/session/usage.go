@@ -0,0 +1,100 @@
+package session
+
+import (
+	"fmt"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+// tokenTotals holds the number of tokens used by the LLM calls of a session,
+// and their estimated cost.
+type tokenTotals struct {
+	Prompt     int
+	Completion int
+	Cost       float64
+	// Priced is false if the price of a model used by the session is unknown,
+	// the cost is then not estimated.
+	Priced bool
+}
+
+// add adds the token usage of an LLM response, found in its generation info
+// under provider specific keys, to the totals. Its cost is estimated with the
+// given price, which is nil if unknown.
+func (t *tokenTotals) add(generationInfo map[string]any, price *config.TokenPrice) {
+	prompt, completion := usageTokens(generationInfo)
+
+	t.Prompt += prompt
+	t.Completion += completion
+	if price == nil {
+		t.Priced = false
+		return
+	}
+	t.Cost += float64(prompt)/1000*price.Prompt + float64(completion)/1000*price.Completion
+}
+
+// usageTokens returns the number of prompt and completion tokens of an LLM
+// response, found in its generation info under provider specific keys.
+func usageTokens(generationInfo map[string]any) (prompt, completion int) {
+	prompt = firstInt(generationInfo, "PromptTokens", "InputTokens", "input_tokens")
+	completion = firstInt(generationInfo, "CompletionTokens", "OutputTokens", "output_tokens")
+
+	return prompt, completion
+}
+
+// firstInt returns the integer value of the first of the given keys present in
+// the map, or 0 if none is.
+func firstInt(m map[string]any, keys ...string) int {
+	for _, key := range keys {
+		switch v := m[key].(type) {
+		case int:
+			return v
+		case int32:
+			return int(v)
+		case int64:
+			return int(v)
+		case float64:
+			return int(v)
+		}
+	}
+
+	return 0
+}
+
+// price returns the price of the current LLM model, or nil if it is not
+// configured.
+func (s Session) price() *config.TokenPrice {
+	price, ok := s.prices[s.models[s.modelIndex].name]
+	if !ok {
+		return nil
+	}
+
+	return &price
+}
+
+// logUsage writes the token usage of the session, and its estimated cost if
+// the price of the model is configured, to the log file.
+func (s Session) logUsage() {
+	s.log("\n## Token usage\nprompt: %d\ncompletion: %d\n", s.tokens.Prompt, s.tokens.Completion)
+	if s.tokens.Priced {
+		s.log("estimated cost: %s\n", formatCost(s.tokens.Cost))
+	}
+}
+
+// usageData returns the token usage of the session, and its estimated cost if
+// known, as recorded in the transcript and events.
+func (s Session) usageData() map[string]any {
+	data := map[string]any{
+		"PromptTokens":     s.tokens.Prompt,
+		"CompletionTokens": s.tokens.Completion,
+	}
+	if s.tokens.Priced {
+		data["EstimatedCost"] = s.tokens.Cost
+	}
+
+	return data
+}
+
+// formatCost formats an estimated cost in the currency of the price table.
+func formatCost(cost float64) string {
+	return fmt.Sprintf("%.4f", cost)
+}
@@ -0,0 +1,101 @@
+package session
+
+import (
+	"context"
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/giantswarm/oka/pkg/config"
+)
+
+func TestSessionTokenUsage(t *testing.T) {
+	testCases := []struct {
+		name           string
+		prices         map[string]config.TokenPrice
+		expectedPriced bool
+	}{
+		{
+			name:           "priced model",
+			prices:         map[string]config.TokenPrice{"fake": {Prompt: 0.01, Completion: 0.03}},
+			expectedPriced: true,
+		},
+		{
+			name:           "unpriced model",
+			prices:         map[string]config.TokenPrice{"other": {Prompt: 0.01, Completion: 0.03}},
+			expectedPriced: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := testConfig(t)
+			conf.LLM.Prices = tc.prices
+
+			// Providers report the usage under different keys.
+			toolCall := toolCallChoice("call-1", echoTool, `{"text": "a"}`)
+			toolCall.GenerationInfo = map[string]any{"PromptTokens": 100, "CompletionTokens": 20}
+			answer := &llms.ContentChoice{
+				Content:        "The alert is resolved. " + testEndPhrase,
+				GenerationInfo: map[string]any{"InputTokens": int64(200), "OutputTokens": float64(30)},
+			}
+			model := &fakeModel{responses: []*llms.ContentChoice{toolCall, answer}}
+			s := newTestSession(t, map[string]any{"message": "test"}, model, newTestClients(t, &echoServer{}), conf)
+
+			err := s.Run(context.Background())
+			if err != nil {
+				t.Fatalf("failed to run session: %v", err)
+			}
+
+			if s.tokens.Prompt != 300 || s.tokens.Completion != 50 {
+				t.Errorf("expected 300 prompt and 50 completion tokens, got %d and %d", s.tokens.Prompt, s.tokens.Completion)
+			}
+			if s.tokens.Priced != tc.expectedPriced {
+				t.Errorf("expected priced: %t, got %t", tc.expectedPriced, s.tokens.Priced)
+			}
+
+			data, err := os.ReadFile(s.logFile.Name())
+			if err != nil {
+				t.Fatalf("failed to read session log: %v", err)
+			}
+			log := string(data)
+			if !strings.Contains(log, "## Token usage\nprompt: 300\ncompletion: 50\n") {
+				t.Errorf("expected the token usage in the session log, got %q", log)
+			}
+
+			usage := s.usageData()
+			_, hasCost := usage["EstimatedCost"]
+			if !tc.expectedPriced {
+				if hasCost || strings.Contains(log, "estimated cost") {
+					t.Errorf("expected no estimated cost, got %v", usage)
+				}
+				return
+			}
+
+			// 300 prompt tokens at 0.01 and 50 completion tokens at 0.03 per
+			// 1K tokens.
+			if math.Abs(s.tokens.Cost-0.0045) > 1e-9 {
+				t.Errorf("expected an estimated cost of 0.0045, got %v", s.tokens.Cost)
+			}
+			if !hasCost || !strings.Contains(log, "estimated cost: 0.0045") {
+				t.Errorf("expected the estimated cost to be reported, got %v", usage)
+			}
+		})
+	}
+}
+
+func TestTokenTotalsUnknownPrice(t *testing.T) {
+	totals := tokenTotals{Priced: true}
+	totals.add(map[string]any{"PromptTokens": 100}, &config.TokenPrice{Prompt: 1})
+	totals.add(map[string]any{"input_tokens": 100, "output_tokens": 10}, nil)
+
+	if totals.Prompt != 200 || totals.Completion != 10 {
+		t.Errorf("expected 200 prompt and 10 completion tokens, got %d and %d", totals.Prompt, totals.Completion)
+	}
+	if totals.Priced {
+		t.Error("expected the cost not to be estimated once a model is unpriced")
+	}
+}
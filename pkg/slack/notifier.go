@@ -0,0 +1,135 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// requestTimeout is the timeout of the requests to the Slack webhook.
+const requestTimeout = 10 * time.Second
+
+// maxTextLength is the maximum length of the text of a message, above which
+// Slack truncates it.
+const maxTextLength = 40000
+
+// Notifier posts messages to a Slack channel through an incoming webhook.
+type Notifier struct {
+	client     *http.Client
+	webhookURL string
+}
+
+// Summary is the summary of an investigation posted to Slack.
+type Summary struct {
+	AlertLink  string // Link to the alert, omitted if empty
+	AlertTitle string // Title of the investigated alert
+	Outcome    string // Outcome of the session, e.g. "completed" or "escalated", omitted if empty
+	Response   string // Final response of the LLM
+	SessionID  string // ID of the session investigating the alert
+}
+
+// ApprovalRequest is a request to approve a tool call posted to Slack.
+type ApprovalRequest struct {
+	Arguments string        // Arguments of the tool call
+	Link      string        // Link to the page approving or denying the tool call
+	SessionID string        // ID of the session calling the tool
+	Timeout   time.Duration // Duration after which the tool call is denied
+	Tool      string        // Name of the tool
+}
+
+// message is the payload of a Slack incoming webhook request.
+type message struct {
+	Text string `json:"text"`
+}
+
+// NewNotifier creates a new notifier posting to the given Slack incoming
+// webhook URL.
+func NewNotifier(webhookURL string) *Notifier {
+	return &Notifier{
+		client:     &http.Client{Timeout: requestTimeout},
+		webhookURL: webhookURL,
+	}
+}
+
+// PostSummary posts the summary of an investigation.
+func (n *Notifier) PostSummary(ctx context.Context, summary Summary) error {
+	return n.post(ctx, formatSummary(summary))
+}
+
+// PostApprovalRequest posts a request to approve a tool call.
+func (n *Notifier) PostApprovalRequest(ctx context.Context, request ApprovalRequest) error {
+	return n.post(ctx, formatApprovalRequest(request))
+}
+
+// post posts a message to the webhook.
+func (n *Notifier) post(ctx context.Context, msg message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post Slack message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to post Slack message: %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	return nil
+}
+
+// formatSummary formats the summary of an investigation as a Slack message,
+// with the alert title linking to the alert if a link is given.
+func formatSummary(summary Summary) message {
+	title := escape(summary.AlertTitle)
+	if summary.AlertLink != "" {
+		title = fmt.Sprintf("<%s|%s>", summary.AlertLink, title)
+	}
+
+	session := summary.SessionID
+	if summary.Outcome != "" {
+		session += ", " + summary.Outcome
+	}
+
+	text := fmt.Sprintf("*OKA investigation summary: %s*\n_Session %s_\n\n%s", title, session, escape(summary.Response))
+	if len(text) > maxTextLength {
+		text = strings.ToValidUTF8(text[:maxTextLength], "")
+	}
+
+	return message{Text: text}
+}
+
+// formatApprovalRequest formats a request to approve a tool call as a Slack
+// message.
+func formatApprovalRequest(request ApprovalRequest) message {
+	arguments := request.Arguments
+	if len(arguments) > maxTextLength/2 {
+		arguments = strings.ToValidUTF8(arguments[:maxTextLength/2], "") + "…"
+	}
+
+	text := fmt.Sprintf("*OKA requests approval to call %s*\n_Session %s_\n\n```%s```\n\n<%s|Approve or deny> within %s, the call is denied afterwards.",
+		escape(request.Tool), request.SessionID, escape(arguments), request.Link, request.Timeout)
+
+	return message{Text: text}
+}
+
+// escape escapes the characters having a special meaning in Slack messages.
+// Reference: https://api.slack.com/reference/surfaces/formatting#escaping
+func escape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
@@ -0,0 +1,113 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newStubWebhook starts a stub Slack webhook responding with the status, and
+// returns its URL along with the channel receiving the posted messages.
+func newStubWebhook(t *testing.T, status int) (string, <-chan message) {
+	t.Helper()
+
+	messages := make(chan message, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("expected a JSON POST request, got %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+
+		var msg message
+		err := json.NewDecoder(r.Body).Decode(&msg)
+		if err != nil {
+			t.Errorf("failed to decode Slack message: %v", err)
+		}
+		messages <- msg
+
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("invalid_payload"))
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL, messages
+}
+
+func TestPostSummary(t *testing.T) {
+	testCases := []struct {
+		name     string
+		summary  Summary
+		expected []string
+	}{
+		{
+			name: "with link",
+			summary: Summary{
+				AlertLink:  "https://example.app.opsgenie.com/alert/detail/alert-1/details",
+				AlertTitle: "Pods <crashing> & restarting",
+				Outcome:    "completed",
+				Response:   "The pods are OOM killed.",
+				SessionID:  "session-1",
+			},
+			expected: []string{
+				"*OKA investigation summary: <https://example.app.opsgenie.com/alert/detail/alert-1/details|Pods &lt;crashing&gt; &amp; restarting>*",
+				"_Session session-1, completed_",
+				"The pods are OOM killed.",
+			},
+		},
+		{
+			name: "without link",
+			summary: Summary{
+				AlertTitle: "Pods crashing",
+				Response:   "The pods are OOM killed.",
+				SessionID:  "session-1",
+			},
+			expected: []string{
+				"*OKA investigation summary: Pods crashing*",
+				"_Session session-1_",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			url, messages := newStubWebhook(t, http.StatusOK)
+
+			err := NewNotifier(url).PostSummary(context.Background(), tc.summary)
+			if err != nil {
+				t.Fatalf("failed to post summary: %v", err)
+			}
+
+			msg := <-messages
+			for _, expected := range tc.expected {
+				if !strings.Contains(msg.Text, expected) {
+					t.Errorf("expected the message to contain %q, got %q", expected, msg.Text)
+				}
+			}
+		})
+	}
+}
+
+func TestPostSummaryTruncated(t *testing.T) {
+	url, messages := newStubWebhook(t, http.StatusOK)
+
+	err := NewNotifier(url).PostSummary(context.Background(), Summary{Response: strings.Repeat("é", maxTextLength)})
+	if err != nil {
+		t.Fatalf("failed to post summary: %v", err)
+	}
+
+	msg := <-messages
+	if len(msg.Text) > maxTextLength {
+		t.Errorf("expected the message to be truncated to %d bytes, got %d", maxTextLength, len(msg.Text))
+	}
+}
+
+func TestPostSummaryError(t *testing.T) {
+	url, _ := newStubWebhook(t, http.StatusBadRequest)
+
+	err := NewNotifier(url).PostSummary(context.Background(), Summary{})
+	if err == nil || !strings.Contains(err.Error(), "invalid_payload") {
+		t.Errorf("expected an error with the response body, got %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+// Package templates parses the templates of the configuration, so that they
+// are parsed the same way when validating the configuration and when they are
+// rendered.
+package templates
+
+import (
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig"
+)
+
+// QueryTimeFormat is the format of the dates in the OpsGenie queries.
+const QueryTimeFormat = "02-01-2006T15:04:05"
+
+// ParseQuery parses an OpsGenie query string template, with the sprig
+// functions and opsgenieDate available. Missing keys are errors, so that a
+// query referencing an empty team is detected while rendering.
+func ParseQuery(text string) (*template.Template, error) {
+	funcs := sprig.TxtFuncMap()
+	funcs["opsgenieDate"] = opsgenieDate
+
+	return template.New("opsgenieQuery").Funcs(funcs).Option("missingkey=error").Parse(text)
+}
+
+// ParseSystemPrompt parses a system prompt template, with the sprig functions
+// available, e.g. env to read environment variables.
+func ParseSystemPrompt(text string) (*template.Template, error) {
+	return template.New("system-prompt").Funcs(sprig.FuncMap()).Parse(text)
+}
+
+// opsgenieDate formats a date in UTC in the format of the OpsGenie queries,
+// e.g. the result of the sprig now and dateModify functions.
+func opsgenieDate(t time.Time) string {
+	return t.UTC().Format(QueryTimeFormat)
+}